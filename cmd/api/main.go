@@ -5,15 +5,25 @@ import (
 	"net/http"
 	"os"
 	
+	"github.com/devinjacknz/devinsystem/internal/ai"
 	"github.com/devinjacknz/devinsystem/internal/api"
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/devinjacknz/devinsystem/internal/monitoring"
 	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/solana"
 	"github.com/devinjacknz/devinsystem/internal/trading"
 	"github.com/devinjacknz/devinsystem/internal/wallet"
+	"github.com/devinjacknz/devinsystem/pkg/utils"
 )
 
 func main() {
+	config, err := utils.LoadConfig("../../config.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Initialize wallet manager
-	walletManager, err := wallet.NewWalletManager()
+	walletManager, err := newWalletManager()
 	if err != nil {
 		log.Fatalf("Failed to initialize wallet manager: %v", err)
 	}
@@ -21,8 +31,24 @@ func main() {
 	// Initialize risk manager
 	riskManager := risk.NewManager()
 
-	// Initialize trading engine with dependencies
-	tradingEngine := trading.NewTradingEngine(riskManager, walletManager)
+	// Initialize trading engine with the same exchanges as the trader
+	pumpFun := exchange.NewPumpFun(config.SolanaRPCURL)
+	jupiter := exchange.NewJupiterDEX()
+	exchanges := []exchange.Exchange{
+		exchange.NewSolanaDEX(config.SolanaRPCURL),
+		pumpFun,
+		jupiter,
+	}
+	// Swaps are signed by wallet A; without it every order is rejected
+	if tradingWallet, err := walletManager.GetWallet(wallet.TradingWallet); err == nil {
+		submitter := exchange.NewSwapSubmitter(solana.NewClient(config.SolanaRPCURL), tradingWallet.Signer(), exchange.SubmitConfig{})
+		pumpFun.SetSubmitter(submitter)
+		jupiter.SetSubmitter(submitter)
+	} else {
+		log.Printf("No trading wallet in the keystore: orders will be rejected")
+	}
+	aiService := ai.NewService(config.OllamaURL, config.DeepSeekModel)
+	tradingEngine := trading.NewTradingEngine(riskManager, exchanges, aiService, monitoring.NewService())
 
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
 	if len(jwtSecret) == 0 {
@@ -38,3 +64,22 @@ func main() {
 	log.Printf("Starting server on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, server))
 }
+
+// newWalletManager keeps keys across restarts when KEYSTORE_PATH names a
+// keystore file. WALLET_MNEMONIC seeds the keystore on first start;
+// afterwards the seed is read from the keystore.
+func newWalletManager() (wallet.SweepManager, error) {
+	path := os.Getenv("KEYSTORE_PATH")
+	if path == "" {
+		return wallet.NewWalletManager()
+	}
+	keyStore, err := wallet.OpenHSMKeyStoreFromEnv(path, wallet.DefaultKDF)
+	if err != nil {
+		return nil, err
+	}
+	rpc := solana.NewClient(wallet.DefaultRPCURL)
+	if mnemonic := os.Getenv("WALLET_MNEMONIC"); mnemonic != "" {
+		return wallet.NewWalletManagerFromMnemonic(mnemonic, os.Getenv("WALLET_MNEMONIC_PASSPHRASE"), keyStore, rpc)
+	}
+	return wallet.NewWalletManagerWithKeyStore(keyStore, rpc)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/devinjacknz/devinsystem/internal/backtest"
+	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/trading"
+	"github.com/devinjacknz/devinsystem/pkg/utils"
+)
+
+func main() {
+	configPath := flag.String("config", "../../config.json", "path to config.json with strategies")
+	dataPath := flag.String("data", "", "recorded candles (.csv or .jsonl)")
+	capital := flag.Float64("capital", 10000, "initial capital in the quote asset")
+	feeBps := flag.Int("fee-bps", 25, "taker fee in basis points")
+	slippageBps := flag.Int("slippage-bps", 10, "slippage in basis points")
+	flag.Parse()
+
+	if *dataPath == "" {
+		log.Fatal("-data is required")
+	}
+
+	config, err := utils.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	candles, err := backtest.LoadFile(*dataPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Replays never call out to the AI service so results stay reproducible
+	bt, err := backtest.New(backtest.Config{
+		InitialCapital: *capital,
+		FeeBps:         *feeBps,
+		SlippageBps:    *slippageBps,
+	}, risk.NewManager())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, cfg := range config.Strategies {
+		if !cfg.Enabled {
+			continue
+		}
+		strategy, err := trading.NewStrategy(cfg.Type, cfg.Name, cfg.Amount, cfg.Params)
+		if err != nil {
+			log.Fatalf("Invalid strategy %s: %v", cfg.Name, err)
+		}
+		if err := bt.RegisterStrategy(strategy, cfg.Symbols...); err != nil {
+			log.Fatalf("Failed to register strategy %s: %v", cfg.Name, err)
+		}
+	}
+
+	report, err := bt.Run(candles)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
-	
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/devinjacknz/devinsystem/internal/ai"
 	"github.com/devinjacknz/devinsystem/internal/exchange"
 	"github.com/devinjacknz/devinsystem/internal/monitoring"
 	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/solana"
 	"github.com/devinjacknz/devinsystem/internal/trading"
+	"github.com/devinjacknz/devinsystem/internal/wallet"
 	"github.com/devinjacknz/devinsystem/pkg/utils"
 )
 
@@ -28,15 +36,139 @@ func main() {
 	
 	// Initialize exchanges
 	solanaDEX := exchange.NewSolanaDEX(config.SolanaRPCURL)
-	pumpFun := exchange.NewPumpFun(config.PumpFunURL)
+	pumpFun := exchange.NewPumpFun(config.SolanaRPCURL)
+	pumpFun.SetStreamConfig(exchange.StreamConfig{
+		OnError: func(err error) { monitor.LogError(err.Error()) },
+	})
+	jupiter := exchange.NewJupiterDEX()
 	
+	exchanges := []exchange.Exchange{solanaDEX, pumpFun, jupiter}
+
+	var wallets wallet.SweepManager
+	if path := os.Getenv("KEYSTORE_PATH"); path != "" {
+		if wallets, err = openWallets(path, config.SolanaRPCURL); err != nil {
+			log.Fatalf("Failed to open keystore: %v", err)
+		}
+	}
+
+	// In paper mode orders fill against live prices with virtual balances
+	if config.Environment == "paper" {
+		latency := time.Duration(config.Paper.LatencyMs) * time.Millisecond
+		jitter := time.Duration(config.Paper.JitterMs) * time.Millisecond
+		for i, ex := range exchanges {
+			exchanges[i] = exchange.NewPaperExchange(ex, exchange.PaperConfig{
+				Balances: config.Paper.Balances,
+				Fee:      exchange.BpsFee(config.Paper.FeeBps),
+				Slippage: exchange.BpsSlippage(config.Paper.SlippageBps),
+				Latency:  exchange.NewJitterLatency(latency, jitter, config.Paper.Seed+int64(i)),
+			})
+		}
+		log.Printf("Paper trading enabled with balances %v", config.Paper.Balances)
+	} else {
+		// Live swaps are signed by wallet A and sent to the configured node
+		if wallets == nil {
+			log.Fatal("KEYSTORE_PATH is required to trade outside paper mode")
+		}
+		submitter, err := newSubmitter(wallets, config.SolanaRPCURL)
+		if err != nil {
+			log.Fatalf("Failed to configure swap signing: %v", err)
+		}
+		pumpFun.SetSubmitter(submitter)
+		jupiter.SetSubmitter(submitter)
+		log.Printf("Live trading from wallet A (%s)", submitter.PublicKey())
+	}
+
 	// Initialize trading engine with both exchanges
 	engine := trading.NewTradingEngine(
 		riskMgr,
-		[]exchange.Exchange{solanaDEX, pumpFun},
+		exchanges,
 		aiService,
 		monitor,
 	)
 	
-	log.Fatal(engine.Start())
+	// Register configured strategies; disabled ones can be enabled at runtime
+	for _, cfg := range config.Strategies {
+		strategy, err := trading.NewStrategy(cfg.Type, cfg.Name, cfg.Amount, cfg.Params)
+		if err != nil {
+			log.Fatalf("Invalid strategy %s: %v", cfg.Name, err)
+		}
+		if err := engine.RegisterStrategy(strategy, cfg.Symbols...); err != nil {
+			log.Fatalf("Failed to register strategy %s: %v", cfg.Name, err)
+		}
+		if !cfg.Enabled {
+			if err := engine.DisableStrategy(strategy.Name(), ""); err != nil {
+				log.Fatalf("Failed to disable strategy %s: %v", cfg.Name, err)
+			}
+		}
+	}
+
+	// Stop cleanly on SIGINT/SIGTERM so no swap is interrupted mid-flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if config.Sweep.Enabled {
+		// Paper PnL is virtual, so it must never move real funds
+		if config.Environment == "paper" && !config.Sweep.DryRun {
+			log.Printf("Paper trading: profit sweeps forced to dry run")
+			config.Sweep.DryRun = true
+		}
+		if wallets == nil {
+			log.Fatal("KEYSTORE_PATH is required to sweep profit")
+		}
+		sweeper, err := newSweeper(wallets, config.Sweep, engine.Positions())
+		if err != nil {
+			log.Fatalf("Failed to configure profit sweeping: %v", err)
+		}
+		go sweeper.Run(ctx)
+		log.Printf("Sweeping %s profit from wallet A to B (dry run: %v)", config.Sweep.Mode, config.Sweep.DryRun)
+	}
+
+	if err := engine.Start(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// openWallets opens the wallets kept in the keystore file at path, the same
+// one the API server uses.
+func openWallets(path, rpcURL string) (wallet.SweepManager, error) {
+	keyStore, err := wallet.OpenHSMKeyStoreFromEnv(path, wallet.DefaultKDF)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.NewWalletManagerWithKeyStore(keyStore, solana.NewClient(rpcURL))
+}
+
+// newSubmitter signs swaps with the trading wallet's key.
+func newSubmitter(wallets wallet.SweepManager, rpcURL string) (*exchange.SwapSubmitter, error) {
+	tradingWallet, err := wallets.GetWallet(wallet.TradingWallet)
+	if err != nil {
+		return nil, fmt.Errorf("trading wallet: %w", err)
+	}
+	return exchange.NewSwapSubmitter(solana.NewClient(rpcURL), tradingWallet.Signer(), exchange.SubmitConfig{}), nil
+}
+
+// newSweeper moves profit from wallet A to B.
+func newSweeper(manager wallet.SweepManager, config utils.SweepConfig, pnl wallet.PnLSource) (*wallet.Sweeper, error) {
+	mint, err := solana.PublicKeyFromBase58(config.Mint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep mint: %w", err)
+	}
+
+	var audit wallet.SweepAuditor
+	if config.AuditLog != "" {
+		audit = wallet.NewFileSweepAudit(config.AuditLog)
+	}
+	return wallet.NewSweeper(manager, pnl, audit, wallet.SweepConfig{
+		Mode:      wallet.SweepMode(config.Mode),
+		Mint:      mint,
+		Quote:     config.Quote,
+		Threshold: config.Threshold,
+		Reserve:   config.Reserve,
+		Fraction:  config.Fraction,
+		MinAmount: config.MinAmount,
+		DailyCap:  config.DailyCap,
+		Interval:  time.Duration(config.IntervalSeconds) * time.Second,
+		DryRun:    config.DryRun,
+		OnError:   func(err error) { log.Printf("Profit sweep failed: %v", err) },
+	})
 }
@@ -0,0 +1,208 @@
+// Package backtest replays recorded market data through the same strategies
+// and risk checks the live engine uses, filling orders on a simulated
+// exchange. Runs are offline and deterministic: the same candles and
+// configuration always produce the same report.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/devinjacknz/devinsystem/internal/ai"
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/devinjacknz/devinsystem/internal/portfolio"
+	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/trading"
+)
+
+const (
+	defaultExchangeName = "Backtest"
+	defaultWallet       = "backtest"
+)
+
+var ErrNoData = errors.New("no market data to replay")
+
+type Config struct {
+	// InitialCapital is the quote-asset equity the run starts with.
+	InitialCapital float64
+	FeeBps         int
+	SlippageBps    int
+	// Exchange and Wallet name the simulated venue and account; they
+	// default to "Backtest" and "backtest".
+	Exchange string
+	Wallet   string
+	// AI, when set, supplies the analysis handed to strategies. It must be
+	// deterministic for the run to be; leave it nil to replay without one.
+	AI ai.Service
+}
+
+// Backtester runs one replay. Strategies and the risk manager keep state
+// between candles, so build a new Backtester for every run.
+type Backtester struct {
+	config     Config
+	strategies *trading.StrategySet
+	riskMgr    risk.Manager
+	exchange   *SimExchange
+	positions  *portfolio.Tracker
+	orderSeq   int
+}
+
+func New(config Config, riskMgr risk.Manager) (*Backtester, error) {
+	if config.InitialCapital <= 0 {
+		return nil, fmt.Errorf("invalid initial capital: %f", config.InitialCapital)
+	}
+	if riskMgr == nil {
+		return nil, errors.New("risk manager is required")
+	}
+	if config.Exchange == "" {
+		config.Exchange = defaultExchangeName
+	}
+	if config.Wallet == "" {
+		config.Wallet = defaultWallet
+	}
+
+	exposure, _ := riskMgr.(portfolio.ExposureUpdater)
+	return &Backtester{
+		config:     config,
+		strategies: trading.NewStrategySet(),
+		riskMgr:    riskMgr,
+		exchange:   NewSimExchange(config.Exchange, config.FeeBps, config.SlippageBps),
+		positions:  portfolio.NewTracker(exposure),
+	}, nil
+}
+
+// RegisterStrategy adds strategy for the given symbols, or for every symbol
+// when none are given.
+func (b *Backtester) RegisterStrategy(strategy trading.Strategy, symbols ...string) error {
+	return b.strategies.Register(strategy, symbols...)
+}
+
+// Run replays candles in time order. Every candle is offered to the active
+// strategies, their intents are validated by the risk manager and executed on
+// the simulated exchange, and equity is sampled once per timestamp.
+func (b *Backtester) Run(candles []Candle) (*Report, error) {
+	if len(candles) == 0 {
+		return nil, ErrNoData
+	}
+	candles = append([]Candle(nil), candles...)
+	sortCandles(candles)
+
+	report := &Report{
+		Start:          candles[0].Time,
+		End:            candles[len(candles)-1].Time,
+		InitialCapital: b.config.InitialCapital,
+	}
+
+	for i, candle := range candles {
+		b.exchange.Update(candle)
+		if err := b.positions.MarkAt(candle.Symbol, candle.Close, candle.Time); err != nil {
+			return nil, fmt.Errorf("failed to mark %s: %w", candle.Symbol, err)
+		}
+
+		input := trading.StrategyInput{
+			Exchange: b.config.Exchange,
+			Market: exchange.MarketData{
+				Symbol: candle.Symbol,
+				Price:  candle.Close,
+				Volume: candle.Volume,
+			},
+			Time: candle.Time,
+		}
+		if b.config.AI != nil {
+			analysis, err := b.config.AI.AnalyzeMarket(ai.MarketData{
+				Symbol: candle.Symbol,
+				Price:  candle.Close,
+				Volume: candle.Volume,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to analyze %s at %s: %w", candle.Symbol, candle.Time, err)
+			}
+			input.Analysis = analysis
+		}
+
+		for _, intent := range b.strategies.Evaluate(input) {
+			if err := b.execute(report, intent, candle); err != nil {
+				return nil, err
+			}
+		}
+
+		// Sample equity once every symbol has been replayed for this time
+		if i == len(candles)-1 || !candles[i+1].Time.Equal(candle.Time) {
+			report.addEquity(candle.Time, b.equity())
+		}
+	}
+
+	report.Positions = b.positions.Positions()
+	report.finish()
+	return report, nil
+}
+
+// execute routes one intent through risk validation and the simulated
+// exchange, recording the resulting trade. Rejections are counted rather
+// than treated as errors, as they are in the live engine.
+func (b *Backtester) execute(report *Report, intent trading.OrderIntent, candle Candle) error {
+	if err := b.riskMgr.ValidateOrder(risk.Order{
+		Symbol:    intent.Symbol,
+		Side:      intent.Side,
+		Amount:    intent.Amount,
+		Price:     intent.Price,
+		OrderType: intent.OrderType,
+	}); err != nil {
+		report.Rejected++
+		return nil
+	}
+
+	b.orderSeq++
+	execution, err := b.exchange.ExecuteOrder(exchange.Order{
+		ID:        fmt.Sprintf("bt-%d", b.orderSeq),
+		Symbol:    intent.Symbol,
+		Side:      intent.Side,
+		Amount:    intent.Amount,
+		Price:     intent.Price,
+		OrderType: intent.OrderType,
+	})
+	if err != nil {
+		report.Rejected++
+		return nil
+	}
+	if execution.FilledAmount <= 0 {
+		return nil
+	}
+
+	before, _ := b.positions.Position(b.config.Wallet, intent.Symbol)
+	after, err := b.positions.ApplyFill(portfolio.Fill{
+		Wallet:   b.config.Wallet,
+		Symbol:   intent.Symbol,
+		Exchange: b.config.Exchange,
+		Side:     intent.Side,
+		Amount:   execution.FilledAmount,
+		Price:    execution.AvgPrice,
+		Fee:      execution.Fee,
+		Time:     candle.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply fill for %s: %w", intent.Symbol, err)
+	}
+
+	report.Trades = append(report.Trades, Trade{
+		Time:     candle.Time,
+		Strategy: intent.Strategy,
+		Symbol:   intent.Symbol,
+		Side:     intent.Side,
+		Amount:   execution.FilledAmount,
+		Price:    execution.AvgPrice,
+		Fee:      execution.Fee,
+		PnL:      after.RealizedPnL - before.RealizedPnL,
+		Closing:  !before.IsFlat() && (before.Size > 0) != (intent.Side == "buy"),
+		Reason:   intent.Reason,
+	})
+	return nil
+}
+
+func (b *Backtester) equity() float64 {
+	equity := b.config.InitialCapital
+	for _, pos := range b.positions.Positions() {
+		equity += pos.PnL()
+	}
+	return equity
+}
@@ -0,0 +1,170 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/trading"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRiskManager struct {
+	mock.Mock
+}
+
+func (m *mockRiskManager) ValidateOrder(order risk.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+func (m *mockRiskManager) CheckExposure(symbol string) (float64, error) {
+	args := m.Called(symbol)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *mockRiskManager) UpdateStopLoss(symbol string, currentPrice float64) error {
+	args := m.Called(symbol, currentPrice)
+	return args.Error(0)
+}
+
+func loadCandles(t *testing.T) []Candle {
+	t.Helper()
+	candles, err := LoadFile("testdata/sol_usdc.csv")
+	assert.NoError(t, err)
+	return candles
+}
+
+func TestBacktester_Run(t *testing.T) {
+	bt, err := New(Config{InitialCapital: 10000}, risk.NewManager())
+	assert.NoError(t, err)
+	assert.NoError(t, bt.RegisterStrategy(trading.NewMomentumStrategy("", 2, 0.05, 1)))
+
+	report, err := bt.Run(loadCandles(t))
+	assert.NoError(t, err)
+
+	sides := make([]string, 0, len(report.Trades))
+	prices := make([]float64, 0, len(report.Trades))
+	for _, trade := range report.Trades {
+		sides = append(sides, trade.Side)
+		prices = append(prices, trade.Price)
+		assert.Equal(t, "momentum", trade.Strategy)
+	}
+	assert.Equal(t, []string{"buy", "sell", "buy"}, sides)
+	assert.Equal(t, []float64{106, 98, 107}, prices)
+	assert.False(t, report.Trades[0].Closing)
+	assert.True(t, report.Trades[1].Closing)
+	assert.Equal(t, -8.0, report.Trades[1].PnL)
+
+	assert.Len(t, report.EquityCurve, 12)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), report.Start)
+	assert.Equal(t, time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), report.End)
+	assert.Equal(t, 9994.0, report.FinalEquity)
+	assert.InDelta(t, -0.0006, report.TotalReturn, 1e-12)
+	// Peak 10006 on Jan 5, trough 9992 on Jan 7
+	assert.InDelta(t, 14.0/10006, report.MaxDrawdown, 1e-12)
+	assert.Equal(t, 0.0, report.WinRate)
+	assert.Less(t, report.Sharpe, 0.0)
+	assert.Zero(t, report.Rejected)
+
+	assert.Len(t, report.Positions, 1)
+	assert.Equal(t, 1.0, report.Positions[0].Size)
+	assert.Equal(t, 109.0, report.Positions[0].CurrentPrice)
+	assert.Equal(t, report.End, report.Positions[0].UpdatedAt)
+}
+
+func TestBacktester_Deterministic(t *testing.T) {
+	run := func() *Report {
+		bt, err := New(Config{InitialCapital: 1000, FeeBps: 25, SlippageBps: 10}, risk.NewManager())
+		assert.NoError(t, err)
+		assert.NoError(t, bt.RegisterStrategy(trading.NewMomentumStrategy("", 2, 0.05, 2)))
+		assert.NoError(t, bt.RegisterStrategy(trading.NewMeanReversionStrategy("", 4, 1.2, 1)))
+		report, err := bt.Run(loadCandles(t))
+		assert.NoError(t, err)
+		return report
+	}
+
+	first := run()
+	assert.NotEmpty(t, first.Trades)
+	assert.Equal(t, first, run())
+
+	for _, trade := range first.Trades {
+		assert.Greater(t, trade.Fee, 0.0)
+	}
+}
+
+func TestBacktester_RiskRejections(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.MatchedBy(func(o risk.Order) bool { return o.Side == "sell" })).Return(assert.AnError)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+
+	bt, err := New(Config{InitialCapital: 10000}, mockRisk)
+	assert.NoError(t, err)
+	assert.NoError(t, bt.RegisterStrategy(trading.NewMomentumStrategy("", 2, 0.05, 1)))
+
+	report, err := bt.Run(loadCandles(t))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Len(t, report.Trades, 2)
+	for _, trade := range report.Trades {
+		assert.Equal(t, "buy", trade.Side)
+	}
+	assert.Equal(t, 2.0, report.Positions[0].Size)
+}
+
+func TestBacktester_Errors(t *testing.T) {
+	_, err := New(Config{}, risk.NewManager())
+	assert.Error(t, err)
+	_, err = New(Config{InitialCapital: 100}, nil)
+	assert.Error(t, err)
+
+	bt, err := New(Config{InitialCapital: 100}, risk.NewManager())
+	assert.NoError(t, err)
+	_, err = bt.Run(nil)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestSimExchange_ExecuteOrder(t *testing.T) {
+	sim := NewSimExchange("Backtest", 100, 50)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := sim.ExecuteOrder(exchange.Order{Symbol: "SOL/USDC", Side: "buy", Amount: 1})
+	assert.ErrorIs(t, err, exchange.ErrMarketNotFound)
+
+	sim.Update(Candle{Time: at, Symbol: "SOL/USDC", Close: 100})
+	price, err := sim.GetMarketPrice("SOL/USDC")
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+
+	tests := []struct {
+		name       string
+		order      exchange.Order
+		wantFilled float64
+		wantPrice  float64
+	}{
+		{name: "market buy pays slippage", order: exchange.Order{Side: "buy", Amount: 2, OrderType: "market"}, wantFilled: 2, wantPrice: 100.5},
+		{name: "market sell gives slippage", order: exchange.Order{Side: "sell", Amount: 2, OrderType: "market"}, wantFilled: 2, wantPrice: 99.5},
+		{name: "limit buy above market fills", order: exchange.Order{Side: "buy", Amount: 1, Price: 101, OrderType: "limit"}, wantFilled: 1, wantPrice: 100.5},
+		{name: "limit buy below market rests", order: exchange.Order{Side: "buy", Amount: 1, Price: 100, OrderType: "limit"}},
+		{name: "limit sell above market rests", order: exchange.Order{Side: "sell", Amount: 1, Price: 100, OrderType: "limit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.order.ID = "o1"
+			tt.order.Symbol = "SOL/USDC"
+			report, err := sim.ExecuteOrder(tt.order)
+			assert.NoError(t, err)
+			assert.Equal(t, "o1", report.OrderID)
+			assert.Equal(t, at, report.Timestamp)
+			assert.Equal(t, tt.wantFilled, report.FilledAmount)
+			assert.InDelta(t, tt.wantPrice, report.AvgPrice, 1e-9)
+			assert.InDelta(t, tt.wantFilled*tt.wantPrice*0.01, report.Fee, 1e-9)
+		})
+	}
+
+	_, err = sim.ExecuteOrder(exchange.Order{Symbol: "SOL/USDC", Side: "hold", Amount: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,220 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Candle is one bar of recorded market data. Recordings of plain
+// exchange.MarketData ticks load as candles whose OHLC are all the tick
+// price.
+type Candle struct {
+	Time   time.Time `json:"timestamp"`
+	Symbol string    `json:"symbol"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+}
+
+// LoadFile reads candles from a .csv or .jsonl recording.
+func LoadFile(path string) ([]Candle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LoadCSV(f)
+	case ".jsonl", ".ndjson":
+		return LoadJSONL(f)
+	default:
+		return nil, fmt.Errorf("unsupported market data format: %s", path)
+	}
+}
+
+// LoadCSV reads candles from CSV with a header row. Columns are matched by
+// name: timestamp and symbol are required, along with close or price; open,
+// high, low and volume are optional.
+func LoadCSV(r io.Reader) ([]Candle, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["close"]; !ok {
+		if idx, ok := columns["price"]; ok {
+			columns["close"] = idx
+		}
+	}
+	for _, required := range []string{"timestamp", "symbol", "close"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv is missing column %q", required)
+		}
+	}
+
+	var candles []Candle
+	line := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+
+		ts, err := parseTime(field("timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		candle := Candle{Time: ts, Symbol: field("symbol")}
+		values := []struct {
+			name string
+			dst  *float64
+		}{
+			{"open", &candle.Open},
+			{"high", &candle.High},
+			{"low", &candle.Low},
+			{"close", &candle.Close},
+			{"volume", &candle.Volume},
+		}
+		for _, v := range values {
+			raw := field(v.name)
+			if raw == "" {
+				continue
+			}
+			if *v.dst, err = strconv.ParseFloat(raw, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %q", line, v.name, raw)
+			}
+		}
+		if err := normalize(&candle); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		candles = append(candles, candle)
+	}
+
+	sortCandles(candles)
+	return candles, nil
+}
+
+// LoadJSONL reads one JSON object per line. Objects may be candles or
+// market data ticks with a price field.
+func LoadJSONL(r io.Reader) ([]Candle, error) {
+	var candles []Candle
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record struct {
+			Candle
+			Timestamp json.RawMessage `json:"timestamp"`
+			Price     float64         `json:"price"`
+		}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		candle := record.Candle
+		ts, err := parseTime(strings.Trim(string(record.Timestamp), `"`))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		candle.Time = ts
+		if candle.Close == 0 {
+			candle.Close = record.Price
+		}
+		if err := normalize(&candle); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		candles = append(candles, candle)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sortCandles(candles)
+	return candles, nil
+}
+
+// parseTime accepts RFC 3339 or unix seconds/milliseconds.
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("missing timestamp")
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	ts, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", raw)
+	}
+	return ts.UTC(), nil
+}
+
+func normalize(candle *Candle) error {
+	if candle.Symbol == "" {
+		return errors.New("missing symbol")
+	}
+	if candle.Close <= 0 {
+		return fmt.Errorf("invalid close price for %s: %f", candle.Symbol, candle.Close)
+	}
+	if candle.Open == 0 {
+		candle.Open = candle.Close
+	}
+	if candle.High == 0 {
+		candle.High = candle.Close
+	}
+	if candle.Low == 0 {
+		candle.Low = candle.Close
+	}
+	return nil
+}
+
+// sortCandles orders by time, then symbol, keeping file order for ties so
+// replays are deterministic.
+func sortCandles(candles []Candle) {
+	sort.SliceStable(candles, func(i, j int) bool {
+		if candles[i].Time.Equal(candles[j].Time) {
+			return candles[i].Symbol < candles[j].Symbol
+		}
+		return candles[i].Time.Before(candles[j].Time)
+	})
+}
@@ -0,0 +1,83 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFile(t *testing.T) {
+	candles, err := LoadFile("testdata/sol_usdc.csv")
+	assert.NoError(t, err)
+	assert.Len(t, candles, 12)
+	assert.Equal(t, Candle{
+		Time:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Symbol: "SOL/USDC",
+		Open:   100,
+		High:   101,
+		Low:    99,
+		Close:  100,
+		Volume: 1200,
+	}, candles[0])
+
+	candles, err = LoadFile("testdata/ticks.jsonl")
+	assert.NoError(t, err)
+	assert.Len(t, candles, 3)
+
+	// Sorted by time then symbol, whatever the timestamp encoding
+	assert.Equal(t, "SOL/USDC", candles[0].Symbol)
+	assert.Equal(t, 100.2, candles[0].Close)
+	assert.Equal(t, 99.5, candles[0].Low)
+	assert.Equal(t, "BONK/USDC", candles[1].Symbol)
+	assert.Equal(t, 0.000021, candles[1].Open)
+	assert.Equal(t, 0.000021, candles[1].High)
+	assert.Equal(t, "SOL/USDC", candles[2].Symbol)
+	assert.Equal(t, 100.5, candles[2].Close)
+	assert.True(t, candles[1].Time.Equal(candles[2].Time))
+
+	_, err = LoadFile("testdata/missing.parquet")
+	assert.Error(t, err)
+}
+
+func TestLoadCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing price column", input: "timestamp,symbol,volume\n1704067200,SOL/USDC,10\n"},
+		{name: "bad timestamp", input: "timestamp,symbol,price\nyesterday,SOL/USDC,100\n"},
+		{name: "bad price", input: "timestamp,symbol,price\n1704067200,SOL/USDC,abc\n"},
+		{name: "zero price", input: "timestamp,symbol,price\n1704067200,SOL/USDC,0\n"},
+		{name: "missing symbol", input: "timestamp,symbol,price\n1704067200,,100\n"},
+		{name: "empty", input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCSV(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadCSV_PriceColumn(t *testing.T) {
+	candles, err := LoadCSV(strings.NewReader("Timestamp, Symbol, Price\n1704067200, SOL/USDC, 101.5\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{{
+		Time:   time.Unix(1704067200, 0).UTC(),
+		Symbol: "SOL/USDC",
+		Open:   101.5,
+		High:   101.5,
+		Low:    101.5,
+		Close:  101.5,
+	}}, candles)
+}
+
+func TestLoadJSONL_Errors(t *testing.T) {
+	_, err := LoadJSONL(strings.NewReader(`{"symbol":"SOL/USDC","price":100}`))
+	assert.Error(t, err)
+	_, err = LoadJSONL(strings.NewReader(`{"symbol":"SOL/USDC",`))
+	assert.Error(t, err)
+}
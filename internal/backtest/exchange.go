@@ -0,0 +1,110 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+)
+
+// SimExchange is an exchange.Exchange that fills orders against the most
+// recently replayed candle of each symbol. Market orders fill in full at the
+// close, moved against the taker by slippageBps; limit orders fill at that
+// price only when it is within their limit.
+type SimExchange struct {
+	mu          sync.RWMutex
+	name        string
+	feeBps      int
+	slippageBps int
+	candles     map[string]Candle
+}
+
+func NewSimExchange(name string, feeBps, slippageBps int) *SimExchange {
+	return &SimExchange{
+		name:        name,
+		feeBps:      feeBps,
+		slippageBps: slippageBps,
+		candles:     make(map[string]Candle),
+	}
+}
+
+// Update makes candle the current market for its symbol.
+func (s *SimExchange) Update(candle Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.candles[candle.Symbol] = candle
+}
+
+func (s *SimExchange) Name() string {
+	return s.name
+}
+
+func (s *SimExchange) GetMarketPrice(symbol string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candle, ok := s.candles[symbol]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", exchange.ErrMarketNotFound, symbol)
+	}
+	return candle.Close, nil
+}
+
+func (s *SimExchange) GetMarketData() ([]*exchange.MarketData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := make([]*exchange.MarketData, 0, len(s.candles))
+	for _, candle := range s.candles {
+		data = append(data, &exchange.MarketData{
+			Symbol: candle.Symbol,
+			Price:  candle.Close,
+			Volume: candle.Volume,
+		})
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Symbol < data[j].Symbol
+	})
+	return data, nil
+}
+
+func (s *SimExchange) ExecuteOrder(order exchange.Order) (*exchange.ExecutionReport, error) {
+	if order.Amount <= 0 {
+		return nil, fmt.Errorf("invalid order amount: %f", order.Amount)
+	}
+
+	s.mu.RLock()
+	candle, ok := s.candles[order.Symbol]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", exchange.ErrMarketNotFound, order.Symbol)
+	}
+
+	slippage := float64(s.slippageBps) / 10000
+	var price float64
+	switch order.Side {
+	case "buy":
+		price = candle.Close * (1 + slippage)
+	case "sell":
+		price = candle.Close * (1 - slippage)
+	default:
+		return nil, fmt.Errorf("invalid order side: %s", order.Side)
+	}
+
+	report := &exchange.ExecutionReport{
+		OrderID:   order.ID,
+		Route:     s.name,
+		Timestamp: candle.Time,
+	}
+	if order.OrderType == "limit" && order.Price > 0 {
+		if (order.Side == "buy" && price > order.Price) || (order.Side == "sell" && price < order.Price) {
+			return report, nil
+		}
+	}
+
+	report.FilledAmount = order.Amount
+	report.AvgPrice = price
+	report.Fee = order.Amount * price * float64(s.feeBps) / 10000
+	return report, nil
+}
@@ -0,0 +1,132 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/portfolio"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Trade is one fill made during a replay. PnL is the realized profit the
+// fill booked, net of its fee; Closing marks fills that reduced or flipped
+// an existing position, which are the ones counted towards the win rate.
+type Trade struct {
+	Time     time.Time `json:"timestamp"`
+	Strategy string    `json:"strategy"`
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"`
+	Amount   float64   `json:"amount"`
+	Price    float64   `json:"price"`
+	Fee      float64   `json:"fee"`
+	PnL      float64   `json:"pnl"`
+	Closing  bool      `json:"closing"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// EquityPoint is the marked-to-market equity at one replayed timestamp.
+// Drawdown is the fractional decline from the highest equity seen so far.
+type EquityPoint struct {
+	Time     time.Time `json:"timestamp"`
+	Equity   float64   `json:"equity"`
+	Drawdown float64   `json:"drawdown"`
+}
+
+// Report summarises a replay. Returns and drawdowns are fractions; Sharpe is
+// annualized from per-sample returns, assuming a zero risk-free rate and the
+// median spacing between samples.
+type Report struct {
+	Start          time.Time            `json:"start"`
+	End            time.Time            `json:"end"`
+	InitialCapital float64              `json:"initialCapital"`
+	FinalEquity    float64              `json:"finalEquity"`
+	TotalReturn    float64              `json:"totalReturn"`
+	MaxDrawdown    float64              `json:"maxDrawdown"`
+	Sharpe         float64              `json:"sharpe"`
+	WinRate        float64              `json:"winRate"`
+	Rejected       int                  `json:"rejected"`
+	Trades         []Trade              `json:"trades"`
+	EquityCurve    []EquityPoint        `json:"equityCurve"`
+	Positions      []portfolio.Position `json:"positions"`
+
+	peak float64
+}
+
+func (r *Report) addEquity(at time.Time, equity float64) {
+	r.peak = math.Max(math.Max(r.peak, r.InitialCapital), equity)
+
+	point := EquityPoint{Time: at, Equity: equity}
+	if r.peak > 0 {
+		point.Drawdown = (r.peak - equity) / r.peak
+	}
+	r.EquityCurve = append(r.EquityCurve, point)
+}
+
+func (r *Report) finish() {
+	r.FinalEquity = r.InitialCapital
+	if n := len(r.EquityCurve); n > 0 {
+		r.FinalEquity = r.EquityCurve[n-1].Equity
+	}
+	r.TotalReturn = (r.FinalEquity - r.InitialCapital) / r.InitialCapital
+
+	for _, point := range r.EquityCurve {
+		r.MaxDrawdown = math.Max(r.MaxDrawdown, point.Drawdown)
+	}
+
+	var closed, wins int
+	for _, trade := range r.Trades {
+		if !trade.Closing {
+			continue
+		}
+		closed++
+		if trade.PnL > 0 {
+			wins++
+		}
+	}
+	if closed > 0 {
+		r.WinRate = float64(wins) / float64(closed)
+	}
+
+	r.Sharpe = sharpe(r.EquityCurve)
+}
+
+func sharpe(curve []EquityPoint) float64 {
+	if len(curve) < 3 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(curve)-1)
+	intervals := make([]time.Duration, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		prev := curve[i-1].Equity
+		if prev <= 0 {
+			return 0
+		}
+		returns = append(returns, curve[i].Equity/prev-1)
+		intervals = append(intervals, curve[i].Time.Sub(curve[i-1].Time))
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	interval := intervals[len(intervals)/2]
+	if interval <= 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(float64(year)/float64(interval))
+}
@@ -1,5 +1,38 @@
 package exchange
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMarketNotFound        = errors.New("market not found")
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+)
+
+// MarketDataError is returned by GetMarketData when some markets could not
+// be fetched. The data returned with it covers the rest.
+type MarketDataError struct {
+	Failed map[string]error // symbol -> cause
+}
+
+func (e *MarketDataError) Error() string {
+	symbols := make([]string, 0, len(e.Failed))
+	for symbol := range e.Failed {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	causes := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		causes[i] = fmt.Sprintf("%s: %v", symbol, e.Failed[symbol])
+	}
+	return fmt.Sprintf("failed to get market data for %d markets: %s", len(symbols), strings.Join(causes, "; "))
+}
+
 type Manager interface {
 	GetExchange(name string) (Exchange, error)
 }
@@ -7,11 +40,12 @@ type Manager interface {
 type Exchange interface {
 	Name() string
 	GetMarketPrice(symbol string) (float64, error)
-	ExecuteOrder(order Order) error
+	ExecuteOrder(order Order) (*ExecutionReport, error)
 	GetMarketData() ([]*MarketData, error)
 }
 
 type Order struct {
+	ID        string
 	Symbol    string
 	Side      string
 	Amount    float64
@@ -19,6 +53,18 @@ type Order struct {
 	OrderType string
 }
 
+// ExecutionReport describes what an exchange actually did with an order.
+// Price and Fee are expressed in the quote asset of the order's symbol.
+type ExecutionReport struct {
+	OrderID      string
+	FilledAmount float64
+	AvgPrice     float64
+	Fee          float64
+	TxID         string
+	Route        string
+	Timestamp    time.Time
+}
+
 type SolanaAdapter struct {
 	client interface{} // Solana client interface
 	config Config
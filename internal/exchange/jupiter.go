@@ -1,11 +1,14 @@
 package exchange
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
-	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,27 +20,94 @@ const (
 	PriceEndpoint  = "/price/v2"
 )
 
+// maxPriceIDs is the most mints the price endpoint accepts per request.
+const maxPriceIDs = 100
+
+const (
+	solMint      = "So11111111111111111111111111111111111111112"
+	usdcMint     = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	solDecimals  = 9
+	usdcDecimals = 6
+)
+
+// knownTokens resolve without a token list fetch. USDC is also the quote
+// for symbols given without one, e.g. "BONK".
+var knownTokens = []TokenInfo{
+	{Symbol: "SOL", Mint: solMint, Decimals: solDecimals},
+	{Symbol: "USDC", Mint: usdcMint, Decimals: usdcDecimals},
+}
+
+const defaultQuoteSymbol = "USDC"
+
+// defaultJupiterSlippageBps bounds market orders.
+const defaultJupiterSlippageBps = 100
+
+// jupiterPair is a market symbol resolved to its token mints.
+type jupiterPair struct {
+	base  TokenInfo
+	quote TokenInfo
+}
+
 type JupiterDEX struct {
-	client     *RateLimitedClient
-	name       string
-	tokenCache *TokenCache
-	updateMu   sync.Mutex
+	client      *RateLimitedClient
+	name        string
+	tokenCache  *TokenCache
+	updateMu    sync.Mutex
+	priceBatch  int
+	mu          sync.RWMutex
+	submitter   *SwapSubmitter
+	slippageBps uint64
 }
 
 func NewJupiterDEX() *JupiterDEX {
+	return NewJupiterDEXWithClient(NewRateLimitedClient(1.0)) // 1 request per second for free plan
+}
+
+// NewJupiterDEXWithClient uses client for every API call, e.g. a replay
+// client in tests.
+func NewJupiterDEXWithClient(client *RateLimitedClient) *JupiterDEX {
 	return &JupiterDEX{
-		client: NewRateLimitedClient(1.0), // 1 request per second for free plan
-		name:   "Jupiter",
+		client:      client,
+		name:        "Jupiter",
+		priceBatch:  maxPriceIDs,
+		slippageBps: defaultJupiterSlippageBps,
 		tokenCache: &TokenCache{
-			tokens: make(map[string]TokenInfo),
+			tokens:   make(map[string]TokenInfo),
+			all:      make(map[string]TokenInfo),
+			bySymbol: make(map[string]string),
 		},
 	}
 }
 
+// SetSubmitter makes ExecuteOrder sign and broadcast swaps through
+// submitter, building them for the submitter's key. Without one,
+// ExecuteOrder fails with ErrNoSubmitter.
+func (j *JupiterDEX) SetSubmitter(submitter *SwapSubmitter) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.submitter = submitter
+}
+
+// SetSlippage sets how far market orders may fill from their quote, at
+// most 10000 bps.
+func (j *JupiterDEX) SetSlippage(bps uint64) error {
+	if bps > 10000 {
+		return fmt.Errorf("invalid slippage: %d bps", bps)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.slippageBps = bps
+	return nil
+}
+
 func (j *JupiterDEX) Name() string {
 	return j.name
 }
 
+// GetMarketData prices the most traded tokens in USDC, up to
+// maxPriceIDs mints per request. Each request waits its turn on the
+// client's rate limiter. Tokens that could not be priced are left out of
+// the data and reported in a *MarketDataError alongside it.
 func (j *JupiterDEX) GetMarketData() ([]*MarketData, error) {
 	if err := j.updateTokenList(); err != nil {
 		return nil, fmt.Errorf("failed to update token list: %w", err)
@@ -49,90 +119,105 @@ func (j *JupiterDEX) GetMarketData() ([]*MarketData, error) {
 		tokens = append(tokens, token)
 	}
 	j.tokenCache.mu.RUnlock()
+	sort.Slice(tokens, func(a, b int) bool { return tokens[a].Mint < tokens[b].Mint })
 
 	var marketData []*MarketData
-	for _, token := range tokens {
-		data, err := j.getTokenMarketData(token.Mint)
-		if err != nil {
-			continue // Skip failed tokens but continue
+	failed := make(map[string]error)
+	for start := 0; start < len(tokens); start += j.priceBatch {
+		end := start + j.priceBatch
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		batch := tokens[start:end]
+
+		prices, err := j.getPrices(batch, usdcMint)
+		for _, token := range batch {
+			if err != nil {
+				failed[token.Symbol] = err
+				continue
+			}
+			price, ok := prices[token.Mint]
+			switch {
+			case !ok:
+				failed[token.Symbol] = fmt.Errorf("no price returned for %s", token.Mint)
+			case price.Price <= 0:
+				failed[token.Symbol] = fmt.Errorf("invalid price for %s: %f", token.Mint, price.Price)
+			default:
+				marketData = append(marketData, &MarketData{
+					Symbol: token.Symbol,
+					Price:  price.Price,
+					Volume: price.Volume,
+				})
+			}
 		}
-		marketData = append(marketData, data)
-		time.Sleep(time.Second) // Respect rate limit
+	}
+
+	if len(failed) > 0 {
+		return marketData, &MarketDataError{Failed: failed}
 	}
 	return marketData, nil
 }
 
-func (j *JupiterDEX) getTokenMarketData(mint string) (*MarketData, error) {
-	url := fmt.Sprintf("%s%s?inputMint=%s&outputMint=%s", 
-		JupiterBaseURL, PriceEndpoint, mint,
-		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v") // USDC
-	
+// jupiterPrice is one token's entry in a price response.
+type jupiterPrice struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume24h"`
+}
+
+// getPrices fetches the price of every token in quoteMint in one request,
+// keyed by mint. Tokens Jupiter cannot price are missing from the result.
+func (j *JupiterDEX) getPrices(tokens []TokenInfo, quoteMint string) (map[string]jupiterPrice, error) {
+	ids := make([]string, len(tokens))
+	for i, token := range tokens {
+		ids[i] = token.Mint
+	}
+	url := fmt.Sprintf("%s%s?ids=%s&vsToken=%s", JupiterBaseURL, PriceEndpoint, strings.Join(ids, ","), quoteMint)
+
 	resp, err := j.client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get market data for %s: %w", mint, err)
+		return nil, fmt.Errorf("failed to get prices: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("rate limit exceeded for %s", mint)
+		return nil, fmt.Errorf("rate limit exceeded")
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code for %s: %d", mint, resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var priceData struct {
-		Data struct {
-			Price  float64 `json:"price"`
-			Volume float64 `json:"volume24h"`
-		} `json:"data"`
+		Data map[string]*jupiterPrice `json:"data"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&priceData); err != nil {
-		return nil, fmt.Errorf("failed to decode market data for %s: %w", mint, err)
+		return nil, fmt.Errorf("failed to decode prices: %w", err)
 	}
 
-	j.tokenCache.mu.RLock()
-	tokenInfo, exists := j.tokenCache.tokens[mint]
-	j.tokenCache.mu.RUnlock()
-	if !exists {
-		return nil, fmt.Errorf("token info not found for %s", mint)
-	}
-
-	if priceData.Data.Price <= 0 {
-		return nil, fmt.Errorf("invalid price for %s: %f", mint, priceData.Data.Price)
+	prices := make(map[string]jupiterPrice, len(priceData.Data))
+	for mint, price := range priceData.Data {
+		if price != nil { // Unpriceable mints come back as null
+			prices[mint] = *price
+		}
 	}
-
-	return &MarketData{
-		Symbol: tokenInfo.Symbol,
-		Price:  priceData.Data.Price,
-		Volume: priceData.Data.Volume,
-	}, nil
+	return prices, nil
 }
 
+// GetMarketPrice returns the price of symbol's base token in its quote token.
 func (j *JupiterDEX) GetMarketPrice(symbol string) (float64, error) {
-	// Convert symbol to mint addresses (e.g., "SOL/USDC" -> solMint/usdcMint)
-	inputMint, outputMint := "So11111111111111111111111111111111111111112", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
-	
-	url := fmt.Sprintf("%s%s?inputMint=%s&outputMint=%s", JupiterBaseURL, PriceEndpoint, inputMint, outputMint)
-	resp, err := j.client.Get(url)
+	pair, err := j.resolvePair(symbol)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get price for %s: %w", symbol, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, symbol)
+		return 0, err
 	}
 
-	var priceData struct {
-		Data struct {
-			Price float64 `json:"price"`
-		} `json:"data"`
+	prices, err := j.getPrices([]TokenInfo{pair.base}, pair.quote.Mint)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", symbol, err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&priceData); err != nil {
-		return 0, fmt.Errorf("failed to decode price data for %s: %w", symbol, err)
+	price, ok := prices[pair.base.Mint]
+	if !ok || price.Price <= 0 {
+		return 0, fmt.Errorf("no price returned for %s", symbol)
 	}
-
-	return priceData.Data.Price, nil
+	return price.Price, nil
 }
 
 func (j *JupiterDEX) updateTokenList() error {
@@ -163,12 +248,22 @@ func (j *JupiterDEX) updateTokenList() error {
 	sort.Slice(tokens, func(i, j int) bool {
 		return tokens[i].Volume24h > tokens[j].Volume24h
 	})
-	if len(tokens) > 30 {
-		tokens = tokens[:30]
-	}
 
 	j.tokenCache.mu.Lock()
 	defer j.tokenCache.mu.Unlock()
+	j.tokenCache.all = make(map[string]TokenInfo, len(tokens))
+	j.tokenCache.bySymbol = make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		j.tokenCache.all[token.Mint] = token
+		// Symbols are not unique; the most traded token keeps the name
+		symbol := strings.ToUpper(token.Symbol)
+		if _, taken := j.tokenCache.bySymbol[symbol]; !taken {
+			j.tokenCache.bySymbol[symbol] = token.Mint
+		}
+	}
+	if len(tokens) > 30 {
+		tokens = tokens[:30]
+	}
 	j.tokenCache.tokens = make(map[string]TokenInfo)
 	for _, token := range tokens {
 		j.tokenCache.tokens[token.Mint] = token
@@ -177,62 +272,241 @@ func (j *JupiterDEX) updateTokenList() error {
 	return nil
 }
 
-func (j *JupiterDEX) ExecuteOrder(order Order) error {
-	// Get quote first
-	quoteURL := fmt.Sprintf("%s%s", JupiterBaseURL, QuoteEndpoint)
-	quoteReq := JupiterQuoteRequest{
-		InputMint:   "So11111111111111111111111111111111111111112", // SOL
-		OutputMint:  "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", // USDC
-		Amount:      fmt.Sprintf("%.0f", order.Amount * 1e9), // Convert to lamports
-		SlippageBps: 100, // 1% slippage
+// resolvePair maps "BASE/QUOTE", or a bare "BASE" quoted in USDC, to token
+// mints and decimals. Each side may be a symbol or a mint address.
+func (j *JupiterDEX) resolvePair(symbol string) (jupiterPair, error) {
+	baseName, quoteName := symbol, defaultQuoteSymbol
+	if parts := strings.Split(symbol, "/"); len(parts) == 2 {
+		baseName, quoteName = parts[0], parts[1]
+	} else if len(parts) > 2 {
+		return jupiterPair{}, fmt.Errorf("invalid symbol %q", symbol)
+	}
+	if baseName == "" || quoteName == "" {
+		return jupiterPair{}, fmt.Errorf("invalid symbol %q", symbol)
 	}
 
-	quoteBody, err := json.Marshal(quoteReq)
+	base, err := j.resolveToken(baseName)
 	if err != nil {
-		return fmt.Errorf("failed to marshal quote request: %w", err)
+		return jupiterPair{}, err
+	}
+	quote, err := j.resolveToken(quoteName)
+	if err != nil {
+		return jupiterPair{}, err
+	}
+	if base.Mint == quote.Mint {
+		return jupiterPair{}, fmt.Errorf("invalid symbol %q: base and quote are the same token", symbol)
+	}
+	return jupiterPair{base: base, quote: quote}, nil
+}
+
+func (j *JupiterDEX) resolveToken(name string) (TokenInfo, error) {
+	for _, token := range knownTokens {
+		if strings.EqualFold(token.Symbol, name) || token.Mint == name {
+			return token, nil
+		}
+	}
+	if token, ok := j.tokenCache.lookup(name); ok {
+		return token, nil
 	}
 
-	resp, err := j.client.Post(quoteURL, "application/json", quoteBody)
+	if err := j.updateTokenList(); err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	if token, ok := j.tokenCache.lookup(name); ok {
+		return token, nil
+	}
+	return TokenInfo{}, fmt.Errorf("%w: unknown token %s", ErrMarketNotFound, name)
+}
+
+// ExecuteOrder swaps order.Amount of the base token of order.Symbol. Sells
+// swap exactly that amount into the quote token; buys swap the quote token
+// for exactly that amount out. A report is only returned once the submitter
+// has confirmed the swap on chain; a swap sent but not confirmed fails with
+// a *SubmitError carrying its signature.
+func (j *JupiterDEX) ExecuteOrder(order Order) (*ExecutionReport, error) {
+	j.mu.RLock()
+	submitter, slippageBps := j.submitter, j.slippageBps
+	j.mu.RUnlock()
+	if submitter == nil {
+		return nil, ErrNoSubmitter
+	}
+	pair, err := j.resolvePair(order.Symbol)
 	if err != nil {
-		return fmt.Errorf("failed to get quote: %w", err)
+		return nil, err
+	}
+	if order.Amount <= 0 {
+		return nil, fmt.Errorf("invalid order amount: %f", order.Amount)
+	}
+	amount, err := toBaseUnits(order.Amount, pair.base.Decimals)
+	if err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code for quote: %d", resp.StatusCode)
+	quoteReq := JupiterQuoteRequest{
+		Amount:      amount,
+		SlippageBps: int(slippageBps),
+	}
+	switch order.Side {
+	case "sell":
+		quoteReq.InputMint, quoteReq.OutputMint = pair.base.Mint, pair.quote.Mint
+	case "buy":
+		quoteReq.InputMint, quoteReq.OutputMint = pair.quote.Mint, pair.base.Mint
+		quoteReq.SwapMode = "ExactOut"
+	default:
+		return nil, fmt.Errorf("invalid order side: %s", order.Side)
 	}
 
-	var quoteResp JupiterQuoteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&quoteResp); err != nil {
-		return fmt.Errorf("failed to decode quote response: %w", err)
+	quoteResp, err := j.quote(context.Background(), quoteReq)
+	if err != nil {
+		return nil, err
 	}
 
 	// Execute swap
 	swapURL := fmt.Sprintf("%s%s", JupiterBaseURL, SwapEndpoint)
 	swapReq := JupiterSwapRequest{
-		QuoteResponse:  quoteResp,
-		UserPublicKey:  os.Getenv("wallet"),
+		QuoteResponse: *quoteResp,
+		UserPublicKey: submitter.PublicKey().String(),
 	}
 
 	swapBody, err := json.Marshal(swapReq)
 	if err != nil {
-		return fmt.Errorf("failed to marshal swap request: %w", err)
+		return nil, fmt.Errorf("failed to marshal swap request: %w", err)
 	}
 
-	resp, err = j.client.Post(swapURL, "application/json", swapBody)
+	resp, err := j.client.Post(swapURL, "application/json", swapBody)
 	if err != nil {
-		return fmt.Errorf("failed to execute swap: %w", err)
+		return nil, fmt.Errorf("failed to execute swap: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code for swap: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code for swap: %d", resp.StatusCode)
 	}
 
 	var swapResp JupiterSwapResponse
 	if err := json.NewDecoder(resp.Body).Decode(&swapResp); err != nil {
-		return fmt.Errorf("failed to decode swap response: %w", err)
+		return nil, fmt.Errorf("failed to decode swap response: %w", err)
 	}
 
-	return nil
+	report, err := executionReport(order, *quoteResp, pair)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := swapResp.Transaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode swap transaction: %w", err)
+	}
+	sig, err := submitter.Submit(context.Background(), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit swap for order %s: %w", order.ID, submitError(sig, err))
+	}
+	report.TxID = sig.String()
+	return report, nil
+}
+
+// Quote asks Jupiter how it would swap exactly amount base units of
+// inputMint into outputMint, failing beyond slippageBps, without executing
+// anything. The response carries the route plan, price impact and minimum
+// output, for checking a trade before placing it.
+func (j *JupiterDEX) Quote(ctx context.Context, inputMint, outputMint string, amount, slippageBps uint64) (*JupiterQuoteResponse, error) {
+	if inputMint == "" || outputMint == "" || inputMint == outputMint {
+		return nil, fmt.Errorf("invalid quote from %q to %q", inputMint, outputMint)
+	}
+	if amount == 0 {
+		return nil, fmt.Errorf("invalid quote amount: %d", amount)
+	}
+	if slippageBps > 10000 {
+		return nil, fmt.Errorf("invalid slippage: %d bps", slippageBps)
+	}
+	return j.quote(ctx, JupiterQuoteRequest{
+		InputMint:   inputMint,
+		OutputMint:  outputMint,
+		Amount:      strconv.FormatUint(amount, 10),
+		SlippageBps: int(slippageBps),
+	})
+}
+
+func (j *JupiterDEX) quote(ctx context.Context, req JupiterQuoteRequest) (*JupiterQuoteResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quote request: %w", err)
+	}
+
+	resp, err := j.client.PostContext(ctx, JupiterBaseURL+QuoteEndpoint, "application/json", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quote: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code for quote: %d", resp.StatusCode)
+	}
+
+	var quote JupiterQuoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return nil, fmt.Errorf("failed to decode quote response: %w", err)
+	}
+	quote, err = quote.withDefaults(req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote: %w", err)
+	}
+	return &quote, nil
+}
+
+// toBaseUnits converts a token amount into the integer string of base units
+// Jupiter expects, e.g. 1.5 SOL -> "1500000000".
+func toBaseUnits(amount float64, decimals int) (string, error) {
+	units := math.Round(amount * math.Pow10(decimals))
+	if units < 1 {
+		return "", fmt.Errorf("amount %f is below the token's smallest unit", amount)
+	}
+	return strconv.FormatFloat(units, 'f', 0, 64), nil
+}
+
+// executionReport converts the amounts of an executed quote from base units
+// into an ExecutionReport for the order's base token, priced in its quote
+// token. Route fees are taken to be in the output token.
+func executionReport(order Order, quote JupiterQuoteResponse, pair jupiterPair) (*ExecutionReport, error) {
+	inAmount, err := strconv.ParseFloat(quote.InputAmount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input amount %q: %w", quote.InputAmount, err)
+	}
+	outAmount, err := strconv.ParseFloat(quote.OutputAmount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output amount %q: %w", quote.OutputAmount, err)
+	}
+
+	// Sells spend the base token; buys receive it
+	baseAmount, quoteAmount := inAmount, outAmount
+	outDecimals := pair.quote.Decimals
+	if order.Side == "buy" {
+		baseAmount, quoteAmount = outAmount, inAmount
+		outDecimals = pair.base.Decimals
+	}
+
+	filled := baseAmount / math.Pow10(pair.base.Decimals)
+	if filled <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	price := quoteAmount / math.Pow10(pair.quote.Decimals) / filled
+
+	var fee float64
+	for _, info := range quote.MarketInfos {
+		if amount, err := strconv.ParseFloat(info.FeeAmount, 64); err == nil {
+			fee += amount / math.Pow10(outDecimals)
+		}
+	}
+	if order.Side == "buy" {
+		// Report fees in the quote token like every other exchange
+		fee *= price
+	}
+
+	return &ExecutionReport{
+		OrderID:      order.ID,
+		FilledAmount: filled,
+		AvgPrice:     price,
+		Fee:          fee,
+		Route:        quote.Route(),
+		Timestamp:    time.Now(),
+	}, nil
 }
@@ -1,22 +1,28 @@
 package exchange
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func newFixtureJupiterDEX(t *testing.T) *JupiterDEX {
+	return NewJupiterDEXWithClient(fixtureClient(t, "testdata/jupiter.json"))
+}
+
 func TestJupiterDEX_GetMarketData_MultipleTokens(t *testing.T) {
-	dex := NewJupiterDEX()
+	dex := newFixtureJupiterDEX(t)
 	data, err := dex.GetMarketData()
 	assert.NoError(t, err)
-	assert.NotEmpty(t, data)
-	
-	// Verify rate limiting
-	start := time.Now()
-	data, err = dex.GetMarketData()
-	assert.NoError(t, err)
-	assert.True(t, time.Since(start) >= time.Second)
+	assert.Len(t, data, 2)
 
 	// Verify token data
 	for _, d := range data {
@@ -26,11 +32,312 @@ func TestJupiterDEX_GetMarketData_MultipleTokens(t *testing.T) {
 	}
 }
 
+// handlerTransport serves every request in process from handler, whatever
+// its host.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (h handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func TestJupiterDEX_GetMarketData_Batched(t *testing.T) {
+	var batches [][]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == "token.jup.ag" {
+			w.Write([]byte(`[
+				{"symbol":"AAA","mint":"A1","volume24h":6},{"symbol":"BBB","mint":"B2","volume24h":5},
+				{"symbol":"CCC","mint":"C3","volume24h":4},{"symbol":"DDD","mint":"D4","volume24h":3},
+				{"symbol":"EEE","mint":"E5","volume24h":2},{"symbol":"FFF","mint":"F6","volume24h":1}
+			]`))
+			return
+		}
+		assert.Equal(t, PriceEndpoint, r.URL.Path)
+		assert.Equal(t, usdcMint, r.URL.Query().Get("vsToken"))
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		batches = append(batches, ids)
+		switch ids[0] {
+		case "A1":
+			w.Write([]byte(`{"data":{"A1":{"id":"A1","price":1.5,"volume24h":6},"B2":null}}`))
+		case "C3":
+			w.Write([]byte(`{"data":{"C3":{"id":"C3","price":2.5,"volume24h":4},"D4":{"id":"D4","price":0}}}`))
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+
+	client := NewRateLimitedClient(20)
+	client.client.Transport = handlerTransport{handler}
+	dex := NewJupiterDEXWithClient(client)
+	dex.priceBatch = 2
+
+	start := time.Now()
+	data, err := dex.GetMarketData()
+	// Four requests at 20 per second wait on the limiter three times
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+	assert.Equal(t, [][]string{{"A1", "B2"}, {"C3", "D4"}, {"E5", "F6"}}, batches)
+	assert.Equal(t, []*MarketData{
+		{Symbol: "AAA", Price: 1.5, Volume: 6},
+		{Symbol: "CCC", Price: 2.5, Volume: 4},
+	}, data)
+
+	var partial *MarketDataError
+	require.ErrorAs(t, err, &partial)
+	assert.Len(t, partial.Failed, 4)
+	assert.Contains(t, partial.Failed["BBB"].Error(), "no price")
+	assert.Contains(t, partial.Failed["DDD"].Error(), "invalid price")
+	assert.Contains(t, partial.Failed["EEE"].Error(), "rate limit")
+	assert.Contains(t, partial.Failed["FFF"].Error(), "rate limit")
+	assert.Contains(t, err.Error(), "4 markets: BBB: no price")
+}
+
 func TestJupiterDEX_GetMarketPrice(t *testing.T) {
-	dex := NewJupiterDEX()
+	dex := newFixtureJupiterDEX(t)
 	price, err := dex.GetMarketPrice("SOL/USDC")
 	assert.NoError(t, err)
-	assert.Greater(t, price, float64(0))
+	assert.Equal(t, 187.42, price)
+
+	// Resolving BONK needs the token list
+	price, err = dex.GetMarketPrice("bonk")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.00002314, price)
+
+	_, err = dex.GetMarketPrice("WIF/USDC")
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+}
+
+func TestJupiterDEX_ExecuteOrder(t *testing.T) {
+	dex, _, _ := newSubmittingJupiterDEX(t)
+
+	tests := []struct {
+		name      string
+		order     Order
+		wantFill  float64
+		wantPrice float64
+		wantFee   float64
+		wantRoute string
+	}{
+		{
+			name:      "sell SOL for USDC",
+			order:     Order{ID: "o1", Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market"},
+			wantFill:  1,
+			wantPrice: 187.231,
+			wantFee:   0.093615,
+			wantRoute: "Whirlpool",
+		},
+		{
+			name:      "buy BONK with USDC",
+			order:     Order{ID: "o2", Symbol: "BONK", Side: "buy", Amount: 1000000, OrderType: "market"},
+			wantFill:  1000000,
+			wantPrice: 0.00002319,
+			wantFee:   2500 * 0.00002319,
+			wantRoute: "Raydium CLMM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := dex.ExecuteOrder(tt.order)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.order.ID, report.OrderID)
+			assert.InDelta(t, tt.wantFill, report.FilledAmount, 1e-9)
+			assert.InDelta(t, tt.wantPrice, report.AvgPrice, 1e-12)
+			assert.InDelta(t, tt.wantFee, report.Fee, 1e-9)
+			assert.Equal(t, tt.wantRoute, report.Route)
+		})
+	}
+}
+
+func TestExecutionReport_FromQuote(t *testing.T) {
+	solUSDC := jupiterPair{base: knownTokens[0], quote: knownTokens[1]}
+	bonkUSDC := jupiterPair{base: TokenInfo{Symbol: "BONK", Decimals: 5}, quote: knownTokens[1]}
+
+	tests := []struct {
+		name      string
+		order     Order
+		quote     JupiterQuoteResponse
+		pair      jupiterPair
+		wantFill  float64
+		wantPrice float64
+		wantFee   float64
+		wantRoute string
+	}{
+		{
+			name:  "sell spends base for quote",
+			order: Order{ID: "swap", Side: "sell"},
+			quote: JupiterQuoteResponse{
+				InputAmount:  "2000000000",
+				OutputAmount: "300000000",
+				MarketInfos: []MarketInfo{
+					{Label: "Orca", FeeAmount: "100000"},
+					{Label: "Raydium", FeeAmount: "50000"},
+				},
+			},
+			pair:      solUSDC,
+			wantFill:  2,
+			wantPrice: 150,
+			wantFee:   0.15,
+			wantRoute: "Orca -> Raydium",
+		},
+		{
+			name:  "buy receives base and reports fee in quote",
+			order: Order{ID: "swap", Side: "buy"},
+			quote: JupiterQuoteResponse{
+				InputAmount:  "25000000",
+				OutputAmount: "100000000000",
+				MarketInfos:  []MarketInfo{{Label: "Meteora", FeeAmount: "200000000"}},
+			},
+			pair:      bonkUSDC,
+			wantFill:  1000000,
+			wantPrice: 0.000025,
+			wantFee:   0.05,
+			wantRoute: "Meteora",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := executionReport(tt.order, tt.quote, tt.pair)
+			assert.NoError(t, err)
+			assert.Equal(t, "swap", report.OrderID)
+			assert.InDelta(t, tt.wantFill, report.FilledAmount, 1e-9)
+			assert.InDelta(t, tt.wantPrice, report.AvgPrice, 1e-12)
+			assert.InDelta(t, tt.wantFee, report.Fee, 1e-9)
+			assert.Equal(t, tt.wantRoute, report.Route)
+		})
+	}
+
+	_, err := executionReport(Order{}, JupiterQuoteResponse{InputAmount: "x"}, solUSDC)
+	assert.Error(t, err)
+	_, err = executionReport(Order{Side: "sell"}, JupiterQuoteResponse{InputAmount: "0", OutputAmount: "0"}, solUSDC)
+	assert.ErrorIs(t, err, ErrInsufficientLiquidity)
+}
+
+func TestJupiterDEX_Quote(t *testing.T) {
+	dex := newFixtureJupiterDEX(t)
+	quote, err := dex.Quote(context.Background(), solMint, usdcMint, 1000000000, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "187231000", quote.OutputAmount)
+	assert.Equal(t, 0.0012, quote.PriceImpactPct)
+	assert.Equal(t, "Whirlpool", quote.Route())
+	assert.Equal(t, "ExactIn", quote.SwapMode)
+	minOut, err := quote.MinimumOut()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(185358690), minOut)
+
+	tests := []struct {
+		name        string
+		input       string
+		output      string
+		amount      uint64
+		slippageBps uint64
+	}{
+		{name: "same token", input: solMint, output: solMint, amount: 1, slippageBps: 100},
+		{name: "no input", output: usdcMint, amount: 1, slippageBps: 100},
+		{name: "zero amount", input: solMint, output: usdcMint, slippageBps: 100},
+		{name: "slippage over 100%", input: solMint, output: usdcMint, amount: 1, slippageBps: 10001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dex.Quote(context.Background(), tt.input, tt.output, tt.amount, tt.slippageBps)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJupiterDEX_QuoteResponse(t *testing.T) {
+	var requests []JupiterQuoteRequest
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != QuoteEndpoint {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req JupiterQuoteRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		if req.SwapMode == "ExactOut" {
+			w.Write([]byte(`{"inputAmount":"2000","outputAmount":"1000","marketInfos":[{"label":"Orca"}]}`))
+			return
+		}
+		w.Write([]byte(`{"inputAmount":"1000","outputAmount":"2000","priceImpactPct":0.5,"otherAmountThreshold":"1990",
+			"marketInfos":[{"label":"Orca"},{"label":"Meteora"}]}`))
+	})
+	limited := NewRateLimitedClient(1000)
+	limited.client.Transport = handlerTransport{handler}
+	dex := NewJupiterDEXWithClient(limited)
+
+	// Jupiter's own threshold wins over one worked out from the slippage
+	quote, err := dex.Quote(context.Background(), "IN", "OUT", 1000, 300)
+	require.NoError(t, err)
+	assert.Equal(t, JupiterQuoteRequest{InputMint: "IN", OutputMint: "OUT", Amount: "1000", SlippageBps: 300}, requests[0])
+	assert.Equal(t, "Orca -> Meteora", quote.Route())
+	assert.Equal(t, 0.5, quote.PriceImpactPct)
+	assert.Equal(t, 300, quote.SlippageBps)
+	minOut, err := quote.MinimumOut()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1990), minOut)
+
+	// Exact-out swaps receive the full amount and bound what they spend
+	quote, err = dex.quote(context.Background(), JupiterQuoteRequest{InputMint: "IN", OutputMint: "OUT", Amount: "1000", SlippageBps: 50, SwapMode: "ExactOut"})
+	require.NoError(t, err)
+	assert.Equal(t, "2010", quote.OtherAmountThreshold)
+	minOut, err = quote.MinimumOut()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), minOut)
+
+	// Orders use the configured slippage
+	assert.Error(t, dex.SetSlippage(10001))
+	require.NoError(t, dex.SetSlippage(25))
+	dex.SetSubmitter(newTestSubmitter(t, "http://127.0.0.1:0", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))))
+	_, err = dex.ExecuteOrder(Order{Symbol: "SOL/USDC", Side: "sell", Amount: 1})
+	assert.Contains(t, err.Error(), "status code for swap")
+	assert.Equal(t, 25, requests[len(requests)-1].SlippageBps)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = dex.Quote(ctx, "IN", "OUT", 1000, 300)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestJupiterDEX_ResolvePair(t *testing.T) {
+	dex := newFixtureJupiterDEX(t)
+
+	tests := []struct {
+		symbol    string
+		wantBase  string
+		wantQuote string
+		wantErr   bool
+	}{
+		{symbol: "SOL/USDC", wantBase: solMint, wantQuote: usdcMint},
+		{symbol: "sol", wantBase: solMint, wantQuote: usdcMint},
+		{symbol: "BONK/SOL", wantBase: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", wantQuote: solMint},
+		{symbol: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263/" + usdcMint, wantBase: "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", wantQuote: usdcMint},
+		{symbol: "USDC/USDC", wantErr: true},
+		{symbol: "SOL/", wantErr: true},
+		{symbol: "A/B/C", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			pair, err := dex.resolvePair(tt.symbol)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBase, pair.base.Mint)
+			assert.Equal(t, tt.wantQuote, pair.quote.Mint)
+		})
+	}
+
+	dex.SetSubmitter(newTestSubmitter(t, "http://127.0.0.1:0", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))))
+	_, err := dex.ExecuteOrder(Order{Symbol: "SOL/USDC", Side: "sell", Amount: 1e-12})
+	assert.Error(t, err)
+	_, err = dex.ExecuteOrder(Order{Symbol: "SOL/USDC", Side: "hold", Amount: 1})
+	assert.Error(t, err)
 }
 
 func TestJupiterDEX_Name(t *testing.T) {
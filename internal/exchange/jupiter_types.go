@@ -1,8 +1,13 @@
 package exchange
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
 )
 
 type JupiterQuoteRequest struct {
@@ -10,13 +15,88 @@ type JupiterQuoteRequest struct {
 	OutputMint  string `json:"outputMint"`
 	Amount      string `json:"amount"`
 	SlippageBps int    `json:"slippageBps"`
+	// SwapMode is "ExactIn" (the default) or "ExactOut", where Amount is
+	// the output to receive.
+	SwapMode string `json:"swapMode,omitempty"`
 }
 
 type JupiterQuoteResponse struct {
-	InputAmount    string       `json:"inputAmount"`
-	OutputAmount   string       `json:"outputAmount"`
-	PriceImpactPct float64     `json:"priceImpactPct"`
-	MarketInfos    []MarketInfo `json:"marketInfos"`
+	InputAmount    string  `json:"inputAmount"`
+	OutputAmount   string  `json:"outputAmount"`
+	PriceImpactPct float64 `json:"priceImpactPct"`
+	// MarketInfos is the route plan: every hop in order, with what it
+	// swaps and charges.
+	MarketInfos []MarketInfo `json:"marketInfos"`
+
+	InputMint   string `json:"inputMint,omitempty"`
+	OutputMint  string `json:"outputMint,omitempty"`
+	SwapMode    string `json:"swapMode,omitempty"`
+	SlippageBps int    `json:"slippageBps,omitempty"`
+	// OtherAmountThreshold is the least output an ExactIn swap accepts, or
+	// the most input an ExactOut swap spends, after slippage.
+	OtherAmountThreshold string `json:"otherAmountThreshold,omitempty"`
+}
+
+// MinimumOut is the least output, in base units, the quoted swap accepts
+// before it fails. ExactOut swaps receive exactly OutputAmount.
+func (q JupiterQuoteResponse) MinimumOut() (uint64, error) {
+	amount := q.OtherAmountThreshold
+	if q.SwapMode == "ExactOut" {
+		amount = q.OutputAmount
+	}
+	out, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid minimum out %q: %w", amount, err)
+	}
+	return out, nil
+}
+
+// Route names the hops of the route plan, e.g. "Whirlpool -> Raydium".
+func (q JupiterQuoteResponse) Route() string {
+	labels := make([]string, 0, len(q.MarketInfos))
+	for _, info := range q.MarketInfos {
+		labels = append(labels, info.Label)
+	}
+	return strings.Join(labels, " -> ")
+}
+
+// withDefaults fills in what older API versions leave out of a response to
+// req, so every quote states its mints, mode, slippage and threshold.
+func (q JupiterQuoteResponse) withDefaults(req JupiterQuoteRequest) (JupiterQuoteResponse, error) {
+	if q.InputMint == "" {
+		q.InputMint = req.InputMint
+	}
+	if q.OutputMint == "" {
+		q.OutputMint = req.OutputMint
+	}
+	if q.SwapMode == "" {
+		q.SwapMode = req.SwapMode
+		if q.SwapMode == "" {
+			q.SwapMode = "ExactIn"
+		}
+	}
+	if q.SlippageBps == 0 {
+		q.SlippageBps = req.SlippageBps
+	}
+	if q.OtherAmountThreshold != "" {
+		return q, nil
+	}
+
+	bps := uint64(q.SlippageBps)
+	if q.SwapMode == "ExactOut" {
+		in, err := strconv.ParseUint(q.InputAmount, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("invalid input amount %q: %w", q.InputAmount, err)
+		}
+		q.OtherAmountThreshold = strconv.FormatUint(in+mulDivBps(in, bps), 10)
+		return q, nil
+	}
+	out, err := strconv.ParseUint(q.OutputAmount, 10, 64)
+	if err != nil {
+		return q, fmt.Errorf("invalid output amount %q: %w", q.OutputAmount, err)
+	}
+	q.OtherAmountThreshold = strconv.FormatUint(mulDivBps(out, 10000-bps), 10)
+	return q, nil
 }
 
 type MarketInfo struct {
@@ -24,6 +104,7 @@ type MarketInfo struct {
 	Label     string `json:"label"`
 	InAmount  string `json:"inAmount"`
 	OutAmount string `json:"outAmount"`
+	FeeAmount string `json:"feeAmount,omitempty"`
 }
 
 type JupiterSwapRequest struct {
@@ -36,9 +117,18 @@ type JupiterSwapResponse struct {
 	Message         string `json:"message,omitempty"`
 }
 
+// Transaction decodes the unsigned swap transaction Jupiter built.
+func (r JupiterSwapResponse) Transaction() (*solana.Transaction, error) {
+	if r.SwapTransaction == "" {
+		return nil, fmt.Errorf("swap response has no transaction: %s", r.Message)
+	}
+	return solana.ParseTransactionBase64(r.SwapTransaction)
+}
+
 type TokenInfo struct {
 	Symbol    string  `json:"symbol"`
 	Mint      string  `json:"mint"`
+	Decimals  int     `json:"decimals"`
 	Volume24h float64 `json:"volume24h"`
 }
 
@@ -51,4 +141,23 @@ type TokenCache struct {
 	mu        sync.RWMutex
 	tokens    map[string]TokenInfo  // mint -> info
 	updatedAt time.Time
+
+	// Every listed token, not just the most traded ones polled for
+	// market data, so orders can resolve any routable mint.
+	all      map[string]TokenInfo // mint -> info
+	bySymbol map[string]string    // upper-case symbol -> mint
+}
+
+// lookup finds a token by mint address or case-insensitive symbol.
+func (c *TokenCache) lookup(name string) (TokenInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if token, ok := c.all[name]; ok {
+		return token, true
+	}
+	if mint, ok := c.bySymbol[strings.ToUpper(name)]; ok {
+		return c.all[mint], true
+	}
+	return TokenInfo{}, false
 }
@@ -10,13 +10,13 @@ type ExchangeManager struct {
 	exchanges map[string]Exchange
 }
 
-func NewExchangeManager(solanaURL, pumpURL string) *ExchangeManager {
+func NewExchangeManager(solanaURL string) *ExchangeManager {
 	manager := &ExchangeManager{
 		exchanges: make(map[string]Exchange),
 	}
 
 	manager.exchanges["solana"] = NewSolanaDEX(solanaURL)
-	manager.exchanges["pump"] = NewPumpFun(pumpURL)
+	manager.exchanges["pump"] = NewPumpFun(solanaURL)
 	manager.exchanges["jupiter"] = NewJupiterDEX()
 
 	return manager
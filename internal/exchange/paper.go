@@ -0,0 +1,287 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// FeeModel prices the fee, in the quote asset, for filling order at price.
+type FeeModel interface {
+	Fee(order Order, price float64) float64
+}
+
+// SlippageModel moves the market price against the taker to give the price
+// order actually fills at.
+type SlippageModel interface {
+	Apply(order Order, price float64) float64
+}
+
+// LatencyModel decides how long order takes to reach the market.
+type LatencyModel interface {
+	Delay(order Order) time.Duration
+}
+
+// BpsFee charges a fixed share of notional, in basis points.
+type BpsFee float64
+
+func (f BpsFee) Fee(order Order, price float64) float64 {
+	return order.Amount * price * float64(f) / 10000
+}
+
+// BpsSlippage moves every fill by a fixed number of basis points.
+type BpsSlippage float64
+
+func (s BpsSlippage) Apply(order Order, price float64) float64 {
+	return slip(order.Side, price, float64(s))
+}
+
+// ImpactSlippage adds ImpactBps for every Depth of quote notional traded on
+// top of a fixed BaseBps, so larger orders fill progressively worse.
+type ImpactSlippage struct {
+	BaseBps   float64
+	ImpactBps float64
+	Depth     float64
+}
+
+func (s ImpactSlippage) Apply(order Order, price float64) float64 {
+	bps := s.BaseBps
+	if s.Depth > 0 {
+		bps += s.ImpactBps * order.Amount * price / s.Depth
+	}
+	return slip(order.Side, price, bps)
+}
+
+func slip(side string, price, bps float64) float64 {
+	if side == "sell" {
+		return price * (1 - bps/10000)
+	}
+	return price * (1 + bps/10000)
+}
+
+// FixedLatency delays every order by the same duration.
+type FixedLatency time.Duration
+
+func (l FixedLatency) Delay(order Order) time.Duration {
+	return time.Duration(l)
+}
+
+// JitterLatency delays orders by a base duration plus a uniformly random
+// share of the jitter. It is seeded so soak runs can be repeated.
+type JitterLatency struct {
+	mu     sync.Mutex
+	base   time.Duration
+	jitter time.Duration
+	rng    *rand.Rand
+}
+
+func NewJitterLatency(base, jitter time.Duration, seed int64) *JitterLatency {
+	return &JitterLatency{
+		base:   base,
+		jitter: jitter,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (l *JitterLatency) Delay(order Order) time.Duration {
+	if l.jitter <= 0 {
+		return l.base
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.base + time.Duration(l.rng.Int63n(int64(l.jitter)))
+}
+
+type PaperConfig struct {
+	// Name defaults to the price source's name so the engine routes
+	// orders and strategy intents exactly as it would live.
+	Name string
+	// Balances are the starting virtual holdings by asset, e.g. "USDC".
+	Balances map[string]float64
+	Fee      FeeModel
+	Slippage SlippageModel
+	Latency  LatencyModel
+}
+
+// PaperExchange simulates trading against a price source without moving
+// funds. Prices and market data come from source, which may be a live
+// exchange or a replay; fills are priced by the configured models and
+// settled against virtual balances. Orders that would overdraw a balance
+// fail with ErrInsufficientBalance.
+type PaperExchange struct {
+	mu       sync.Mutex
+	source   Exchange
+	name     string
+	balances map[string]float64
+	fee      FeeModel
+	slippage SlippageModel
+	latency  LatencyModel
+	sleep    func(time.Duration)
+	now      func() time.Time
+	sequence int
+}
+
+func NewPaperExchange(source Exchange, config PaperConfig) *PaperExchange {
+	name := config.Name
+	if name == "" {
+		name = source.Name()
+	}
+	balances := make(map[string]float64, len(config.Balances))
+	for asset, amount := range config.Balances {
+		balances[asset] = amount
+	}
+
+	return &PaperExchange{
+		source:   source,
+		name:     name,
+		balances: balances,
+		fee:      config.Fee,
+		slippage: config.Slippage,
+		latency:  config.Latency,
+		sleep:    time.Sleep,
+		now:      time.Now,
+	}
+}
+
+func (p *PaperExchange) Name() string {
+	return p.name
+}
+
+func (p *PaperExchange) GetMarketPrice(symbol string) (float64, error) {
+	return p.source.GetMarketPrice(symbol)
+}
+
+func (p *PaperExchange) GetMarketData() ([]*MarketData, error) {
+	return p.source.GetMarketData()
+}
+
+// Subscribe passes through the source's market data stream, if it has one.
+func (p *PaperExchange) Subscribe(ctx context.Context, symbols []string) (<-chan MarketEvent, error) {
+	streamer, ok := p.source.(Streamer)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	return streamer.Subscribe(ctx, symbols)
+}
+
+// SubscribeLaunches passes through the source's token launches, if it
+// announces them.
+func (p *PaperExchange) SubscribeLaunches(ctx context.Context) (<-chan TokenLaunch, error) {
+	source, ok := p.source.(LaunchSource)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	return source.SubscribeLaunches(ctx)
+}
+
+// ExecuteOrder waits out the latency model, then fills order in full at the
+// source's price after slippage. Limit orders priced through that level are
+// left unfilled.
+func (p *PaperExchange) ExecuteOrder(order Order) (*ExecutionReport, error) {
+	if order.Amount <= 0 {
+		return nil, fmt.Errorf("invalid order amount: %f", order.Amount)
+	}
+	if order.Side != "buy" && order.Side != "sell" {
+		return nil, fmt.Errorf("invalid order side: %s", order.Side)
+	}
+	base, quote, err := splitSymbol(order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.latency != nil {
+		if delay := p.latency.Delay(order); delay > 0 {
+			p.sleep(delay)
+		}
+	}
+
+	// Price after the delay, as a real order would see it
+	market, err := p.source.GetMarketPrice(order.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price %s: %w", order.Symbol, err)
+	}
+	if market <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMarketNotFound, order.Symbol)
+	}
+
+	price := market
+	if p.slippage != nil {
+		price = p.slippage.Apply(order, market)
+	}
+
+	report := &ExecutionReport{
+		OrderID:   order.ID,
+		Route:     "paper:" + p.source.Name(),
+		Timestamp: p.now(),
+	}
+	if order.OrderType == "limit" && order.Price > 0 {
+		if (order.Side == "buy" && price > order.Price) || (order.Side == "sell" && price < order.Price) {
+			return report, nil
+		}
+	}
+
+	var fee float64
+	if p.fee != nil {
+		fee = p.fee.Fee(order, price)
+	}
+	notional := order.Amount * price
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if order.Side == "buy" {
+		if p.balances[quote] < notional+fee {
+			return nil, fmt.Errorf("%w: need %f %s, have %f", ErrInsufficientBalance, notional+fee, quote, p.balances[quote])
+		}
+		p.balances[quote] -= notional + fee
+		p.balances[base] += order.Amount
+	} else {
+		if p.balances[base] < order.Amount {
+			return nil, fmt.Errorf("%w: need %f %s, have %f", ErrInsufficientBalance, order.Amount, base, p.balances[base])
+		}
+		p.balances[base] -= order.Amount
+		p.balances[quote] += notional - fee
+	}
+
+	p.sequence++
+	report.FilledAmount = order.Amount
+	report.AvgPrice = price
+	report.Fee = fee
+	report.TxID = fmt.Sprintf("paper-%d", p.sequence)
+	return report, nil
+}
+
+// Balance returns the virtual holding of asset.
+func (p *PaperExchange) Balance(asset string) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.balances[asset]
+}
+
+// Balances returns a copy of every non-zero virtual holding.
+func (p *PaperExchange) Balances() map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	balances := make(map[string]float64, len(p.balances))
+	for asset, amount := range p.balances {
+		if amount != 0 {
+			balances[asset] = amount
+		}
+	}
+	return balances
+}
+
+func splitSymbol(symbol string) (string, string, error) {
+	parts := strings.Split(symbol, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid symbol %q: expected BASE/QUOTE", symbol)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,182 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// priceSource is an Exchange whose prices are set directly by tests.
+type priceSource struct {
+	prices map[string]float64
+}
+
+func (s *priceSource) Name() string {
+	return "Replay"
+}
+
+func (s *priceSource) GetMarketPrice(symbol string) (float64, error) {
+	price, ok := s.prices[symbol]
+	if !ok {
+		return 0, ErrMarketNotFound
+	}
+	return price, nil
+}
+
+func (s *priceSource) ExecuteOrder(order Order) (*ExecutionReport, error) {
+	panic("paper trading must not execute on its price source")
+}
+
+func (s *priceSource) GetMarketData() ([]*MarketData, error) {
+	var data []*MarketData
+	for symbol, price := range s.prices {
+		data = append(data, &MarketData{Symbol: symbol, Price: price})
+	}
+	return data, nil
+}
+
+func TestPaperExchange_ExecuteOrder(t *testing.T) {
+	source := &priceSource{prices: map[string]float64{"SOL/USDC": 100}}
+	paper := NewPaperExchange(source, PaperConfig{
+		Balances: map[string]float64{"USDC": 1000},
+		Fee:      BpsFee(10),
+		Slippage: BpsSlippage(50),
+	})
+	paper.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, "Replay", paper.Name())
+
+	tests := []struct {
+		name       string
+		order      Order
+		wantFilled float64
+		wantPrice  float64
+		wantErr    error
+		wantUSDC   float64
+		wantSOL    float64
+	}{
+		{
+			name:       "market buy pays slippage and fee",
+			order:      Order{ID: "1", Symbol: "SOL/USDC", Side: "buy", Amount: 5, OrderType: "market"},
+			wantFilled: 5,
+			wantPrice:  100.5,
+			wantUSDC:   1000 - 502.5 - 0.5025,
+			wantSOL:    5,
+		},
+		{
+			name:     "buy beyond balance is refused",
+			order:    Order{ID: "2", Symbol: "SOL/USDC", Side: "buy", Amount: 5, OrderType: "market"},
+			wantErr:  ErrInsufficientBalance,
+			wantUSDC: 1000 - 502.5 - 0.5025,
+			wantSOL:  5,
+		},
+		{
+			name:     "limit sell above market is left unfilled",
+			order:    Order{ID: "3", Symbol: "SOL/USDC", Side: "sell", Amount: 2, Price: 101, OrderType: "limit"},
+			wantUSDC: 1000 - 502.5 - 0.5025,
+			wantSOL:  5,
+		},
+		{
+			name:       "market sell receives slippage less fee",
+			order:      Order{ID: "4", Symbol: "SOL/USDC", Side: "sell", Amount: 2, OrderType: "market"},
+			wantFilled: 2,
+			wantPrice:  99.5,
+			wantUSDC:   1000 - 502.5 - 0.5025 + 199 - 0.199,
+			wantSOL:    3,
+		},
+		{
+			name:     "sell beyond holdings is refused",
+			order:    Order{ID: "5", Symbol: "SOL/USDC", Side: "sell", Amount: 4, OrderType: "market"},
+			wantErr:  ErrInsufficientBalance,
+			wantUSDC: 1000 - 502.5 - 0.5025 + 199 - 0.199,
+			wantSOL:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := paper.ExecuteOrder(tt.order)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.order.ID, report.OrderID)
+				assert.Equal(t, "paper:Replay", report.Route)
+				assert.Equal(t, tt.wantFilled, report.FilledAmount)
+				assert.InDelta(t, tt.wantPrice, report.AvgPrice, 1e-9)
+				assert.InDelta(t, tt.wantFilled*tt.wantPrice*0.001, report.Fee, 1e-9)
+			}
+			assert.InDelta(t, tt.wantUSDC, paper.Balance("USDC"), 1e-9)
+			assert.InDelta(t, tt.wantSOL, paper.Balance("SOL"), 1e-9)
+		})
+	}
+
+	_, err := paper.ExecuteOrder(Order{Symbol: "BONK/USDC", Side: "buy", Amount: 1})
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+	_, err = paper.ExecuteOrder(Order{Symbol: "SOL", Side: "buy", Amount: 1})
+	assert.Error(t, err)
+	_, err = paper.ExecuteOrder(Order{Symbol: "SOL/USDC", Side: "hold", Amount: 1})
+	assert.Error(t, err)
+}
+
+func TestPaperExchange_LatencyRepricesOrder(t *testing.T) {
+	source := &priceSource{prices: map[string]float64{"SOL/USDC": 100}}
+	paper := NewPaperExchange(source, PaperConfig{
+		Name:     "Paper",
+		Balances: map[string]float64{"USDC": 1000},
+		Latency:  FixedLatency(250 * time.Millisecond),
+	})
+
+	var slept []time.Duration
+	paper.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		source.prices["SOL/USDC"] = 110
+	}
+
+	report, err := paper.ExecuteOrder(Order{ID: "1", Symbol: "SOL/USDC", Side: "buy", Amount: 1, OrderType: "market"})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{250 * time.Millisecond}, slept)
+	assert.Equal(t, 110.0, report.AvgPrice)
+	assert.Equal(t, "Paper", paper.Name())
+	assert.Equal(t, map[string]float64{"USDC": 890, "SOL": 1}, paper.Balances())
+}
+
+func TestSlippageAndLatencyModels(t *testing.T) {
+	impact := ImpactSlippage{BaseBps: 10, ImpactBps: 100, Depth: 10000}
+	assert.InDelta(t, 100*(1+0.0011), impact.Apply(Order{Side: "buy", Amount: 1}, 100), 1e-9)
+	assert.InDelta(t, 100*(1-0.002), impact.Apply(Order{Side: "sell", Amount: 10}, 100), 1e-9)
+
+	a := NewJitterLatency(time.Second, 100*time.Millisecond, 42)
+	b := NewJitterLatency(time.Second, 100*time.Millisecond, 42)
+	for i := 0; i < 10; i++ {
+		delay := a.Delay(Order{})
+		assert.Equal(t, delay, b.Delay(Order{}))
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.Less(t, delay, 1100*time.Millisecond)
+	}
+	assert.Equal(t, time.Second, NewJitterLatency(time.Second, 0, 1).Delay(Order{}))
+}
+
+type streamingSource struct {
+	priceSource
+	events chan MarketEvent
+}
+
+func (s *streamingSource) Subscribe(ctx context.Context, symbols []string) (<-chan MarketEvent, error) {
+	return s.events, nil
+}
+
+func TestPaperExchange_Streams(t *testing.T) {
+	paper := NewPaperExchange(&priceSource{}, PaperConfig{})
+	_, err := paper.Subscribe(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrStreamingUnsupported)
+	_, err = paper.SubscribeLaunches(context.Background())
+	assert.ErrorIs(t, err, ErrStreamingUnsupported)
+
+	source := &streamingSource{events: make(chan MarketEvent, 1)}
+	source.events <- MarketEvent{Type: MarketEventPrice, Market: MarketData{Symbol: "SOL/USDC", Price: 100}}
+	events, err := NewPaperExchange(source, PaperConfig{}).Subscribe(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, (<-events).Market.Price)
+}
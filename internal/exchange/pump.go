@@ -1,57 +1,456 @@
 package exchange
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
 )
 
+// defaultPumpSlippageBps bounds market orders; launches move fast.
+const defaultPumpSlippageBps = 500
+
+const pumpRPCTimeout = 30 * time.Second
+
+// PumpMarket is a token trading on its Pump.fun bonding curve.
+type PumpMarket struct {
+	Symbol string
+	Mint   solana.PublicKey
+	Curve  solana.PublicKey
+	State  BondingCurve
+	// Progress runs from 0 at launch to 1 when the curve completes and the
+	// token migrates.
+	Progress  float64
+	UpdatedAt time.Time
+}
+
+// PumpQuote is what an order would trade against the curve right now.
+// Lamports is the SOL paid for a buy or received for a sell, fee included.
+type PumpQuote struct {
+	Tokens   uint64
+	Lamports uint64
+	Fee      uint64
+}
+
+// PumpFun trades tokens on their Pump.fun bonding curves. Markets are
+// symbols quoted in SOL, either registered with AddToken or named by mint,
+// e.g. "<mint>/SOL". Prices come from curve state read over RPC.
 type PumpFun struct {
-	mu      sync.RWMutex
-	client  interface{}
-	markets map[string]*Market
-	name    string
+	mu          sync.RWMutex
+	client      *solana.Client
+	markets     map[string]*PumpMarket
+	added       chan struct{}
+	global      *PumpGlobal
+	name        string
+	slippageBps uint64
+	submitter   *SwapSubmitter
+	stream      StreamConfig
+	now         func() time.Time
 }
 
 func (p *PumpFun) Name() string {
 	return p.name
 }
 
-func (p *PumpFun) GetMarketData() ([]*MarketData, error) {
-	return []*MarketData{}, nil
+func NewPumpFun(rpcURL string) *PumpFun {
+	return NewPumpFunWithClient(solana.NewClient(rpcURL))
 }
 
-func NewPumpFun(apiURL string) *PumpFun {
+// NewPumpFunWithClient reads curves through client, e.g. one pointed at a
+// stub node in tests.
+func NewPumpFunWithClient(client *solana.Client) *PumpFun {
 	return &PumpFun{
-		markets: make(map[string]*Market),
-		name:    "Pump.fun",
+		client:      client,
+		markets:     make(map[string]*PumpMarket),
+		added:       make(chan struct{}),
+		name:        "Pump.fun",
+		slippageBps: defaultPumpSlippageBps,
+		now:         time.Now,
+	}
+}
+
+// SetSubmitter makes ExecuteOrder trade from the submitter's wallet.
+func (p *PumpFun) SetSubmitter(submitter *SwapSubmitter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.submitter = submitter
+}
+
+// SetSlippage sets how far market orders may fill from the quote, at most
+// 10000 bps.
+func (p *PumpFun) SetSlippage(bps uint64) error {
+	if bps > 10000 {
+		return fmt.Errorf("invalid slippage: %d bps", bps)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.slippageBps = bps
+	return nil
+}
+
+// AddToken registers symbol for mint, reading its curve to make sure the
+// token trades on Pump.fun.
+func (p *PumpFun) AddToken(symbol string, mint solana.PublicKey) error {
+	if _, quote, err := splitSymbol(symbol); err != nil {
+		return err
+	} else if quote != "SOL" {
+		return fmt.Errorf("invalid symbol %q: pump.fun tokens are quoted in SOL", symbol)
+	}
+	curve, err := BondingCurveAddress(mint)
+	if err != nil {
+		return err
+	}
+	p.mu.RLock()
+	_, exists := p.markets[symbol]
+	p.mu.RUnlock()
+	if exists {
+		return errors.New("market already exists")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pumpRPCTimeout)
+	defer cancel()
+	market := &PumpMarket{Symbol: symbol, Mint: mint, Curve: curve}
+	if _, err := p.read(ctx, market); err != nil {
+		return fmt.Errorf("failed to load curve for %s: %w", mint, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.markets[symbol]; exists {
+		return errors.New("market already exists")
+	}
+	p.register(market)
+	return nil
+}
+
+// register adds market and wakes everything waiting on marketsAdded. The
+// caller holds p.mu.
+func (p *PumpFun) register(market *PumpMarket) {
+	p.markets[market.Symbol] = market
+	close(p.added)
+	p.added = make(chan struct{})
+}
+
+// marketsAdded is closed the next time a market is registered.
+func (p *PumpFun) marketsAdded() <-chan struct{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.added
+}
+
+// Markets lists every registered market with its last known curve state,
+// including completed ones.
+func (p *PumpFun) Markets() []PumpMarket {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	markets := make([]PumpMarket, 0, len(p.markets))
+	for _, market := range p.markets {
+		markets = append(markets, *market)
+	}
+	sort.Slice(markets, func(i, j int) bool { return markets[i].Symbol < markets[j].Symbol })
+	return markets
+}
+
+// GetMarketData reports the current price of every market still on its
+// curve. Completed markets are skipped; they trade on an AMM now. Markets
+// whose curve could not be read are left out of the data and reported in a
+// *MarketDataError alongside it.
+func (p *PumpFun) GetMarketData() ([]*MarketData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pumpRPCTimeout)
+	defer cancel()
+
+	p.mu.RLock()
+	symbols := make([]string, 0, len(p.markets))
+	for symbol := range p.markets {
+		symbols = append(symbols, symbol)
 	}
+	p.mu.RUnlock()
+	sort.Strings(symbols)
+
+	var data []*MarketData
+	failed := make(map[string]error)
+	for _, symbol := range symbols {
+		market, err := p.refresh(ctx, symbol)
+		if err != nil {
+			failed[symbol] = err
+			continue
+		}
+		if market.State.Complete {
+			continue
+		}
+		data = append(data, &MarketData{Symbol: symbol, Price: market.State.Price()})
+	}
+
+	if len(failed) > 0 {
+		return data, &MarketDataError{Failed: failed}
+	}
+	return data, nil
 }
 
 func (p *PumpFun) GetMarketPrice(symbol string) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pumpRPCTimeout)
+	defer cancel()
+
+	market, err := p.refresh(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	if market.State.Complete {
+		return 0, ErrCurveComplete
+	}
+	return market.State.Price(), nil
+}
+
+// Quote prices order against the curve's current state.
+func (p *PumpFun) Quote(order Order) (*PumpQuote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pumpRPCTimeout)
+	defer cancel()
+
+	_, quote, err := p.quote(ctx, order)
+	return quote, err
+}
+
+func (p *PumpFun) quote(ctx context.Context, order Order) (PumpMarket, *PumpQuote, error) {
+	if order.Amount <= 0 || math.IsNaN(order.Amount) || math.IsInf(order.Amount, 0) {
+		return PumpMarket{}, nil, fmt.Errorf("invalid order amount: %f", order.Amount)
+	}
+	market, err := p.refresh(ctx, order.Symbol)
+	if err != nil {
+		return market, nil, err
+	}
+	global, err := p.loadGlobal(ctx)
+	if err != nil {
+		return market, nil, err
+	}
+
+	units := math.Round(order.Amount * math.Pow10(pumpTokenDecimals))
+	if units < 1 || units >= math.MaxUint64 {
+		return market, nil, fmt.Errorf("invalid order amount: %f", order.Amount)
+	}
+	quote := &PumpQuote{Tokens: uint64(units)}
+	switch order.Side {
+	case "buy":
+		quote.Lamports, quote.Fee, err = market.State.BuyCost(quote.Tokens, global.FeeBasisPoints)
+	case "sell":
+		quote.Lamports, quote.Fee, err = market.State.SellProceeds(quote.Tokens, global.FeeBasisPoints)
+	default:
+		return market, nil, fmt.Errorf("invalid order side: %s", order.Side)
+	}
+	if err != nil {
+		return market, nil, err
+	}
+	return market, quote, nil
+}
+
+// ExecuteOrder buys or sells Order.Amount tokens on the curve from the
+// submitter's wallet and waits for confirmation. Market orders may fill up
+// to the slippage setting from the quote; limit orders never fill beyond
+// their price. The report prices the curve trade in SOL, with the fee
+// separate. A trade sent but not confirmed fails with a *SubmitError
+// carrying its signature.
+func (p *PumpFun) ExecuteOrder(order Order) (*ExecutionReport, error) {
 	p.mu.RLock()
-	market, exists := p.markets[symbol]
+	submitter, slippageBps := p.submitter, p.slippageBps
 	p.mu.RUnlock()
+	if submitter == nil {
+		return nil, ErrNoSubmitter
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pumpRPCTimeout)
+	defer cancel()
+
+	market, quote, err := p.quote(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	global, err := p.loadGlobal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Curve SOL excluding the fee, which buys pay on top and sells lose
+	curveLamports := quote.Lamports - quote.Fee
+	if order.Side == "sell" {
+		curveLamports = quote.Lamports + quote.Fee
+	}
+	filled := float64(quote.Tokens) / math.Pow10(pumpTokenDecimals)
+	price := float64(curveLamports) / float64(solana.LamportsPerSOL) / filled
+	if order.OrderType == "limit" && order.Price > 0 {
+		if (order.Side == "buy" && price > order.Price) || (order.Side == "sell" && price < order.Price) {
+			return nil, fmt.Errorf("%w: curve price %g is beyond limit %g", ErrInsufficientLiquidity, price, order.Price)
+		}
+	}
+
+	user := submitter.PublicKey()
+	var instructions []solana.Instruction
+	if order.Side == "buy" {
+		limit := quote.Lamports + mulDivBps(quote.Lamports, slippageBps)
+		if order.OrderType == "limit" && order.Price > 0 {
+			limit = limitLamports(order.Price, filled, global.FeeBasisPoints, true)
+		}
+		createATA, err := solana.CreateAssociatedTokenAccountIdempotent(user, user, market.Mint, solana.TokenProgramID)
+		if err != nil {
+			return nil, err
+		}
+		buy, err := PumpBuy(global, market.Mint, user, quote.Tokens, limit)
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, createATA, buy)
+	} else {
+		limit := quote.Lamports - mulDivBps(quote.Lamports, slippageBps)
+		if order.OrderType == "limit" && order.Price > 0 {
+			limit = limitLamports(order.Price, filled, global.FeeBasisPoints, false)
+		}
+		sell, err := PumpSell(global, market.Mint, user, quote.Tokens, limit)
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, sell)
+	}
+
+	blockhash, err := p.client.GetLatestBlockhash(ctx, solana.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockhash: %w", err)
+	}
+	msg, err := solana.NewLegacyMessage(user, instructions, blockhash.Blockhash)
+	if err != nil {
+		return nil, err
+	}
+	// Submit bounds confirmation with its own timeout
+	sig, err := submitter.Submit(context.Background(), solana.NewTransaction(msg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit pump.fun %s for order %s: %w", order.Side, order.ID, submitError(sig, err))
+	}
 
-	if !exists {
-		return 0, errors.New("market not found")
+	return &ExecutionReport{
+		OrderID:      order.ID,
+		FilledAmount: filled,
+		AvgPrice:     price,
+		Fee:          float64(quote.Fee) / float64(solana.LamportsPerSOL),
+		TxID:         sig.String(),
+		Route:        "pump.fun bonding curve",
+		Timestamp:    p.now(),
+	}, nil
+}
+
+// limitLamports is the on-chain bound for a limit order: the most a buy may
+// pay or the least a sell may receive for tokens at price, fee included.
+func limitLamports(price, tokens float64, feeBps uint64, buy bool) uint64 {
+	lamports := price * tokens * float64(solana.LamportsPerSOL)
+	fee := lamports * float64(feeBps) / 10000
+	if buy {
+		return uint64(math.Floor(lamports + fee))
+	}
+	return uint64(math.Ceil(lamports - fee))
+}
+
+// market resolves symbol to its registered market, or to a new unregistered
+// one for "<mint>/SOL" symbols not seen before.
+func (p *PumpFun) market(symbol string) (*PumpMarket, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if market, exists := p.markets[symbol]; exists {
+		return market, nil
+	}
+	base, quote, err := splitSymbol(symbol)
+	if err != nil || quote != "SOL" {
+		return nil, ErrMarketNotFound
+	}
+	mint, err := solana.PublicKeyFromBase58(base)
+	if err != nil {
+		return nil, ErrMarketNotFound
+	}
+	curve, err := BondingCurveAddress(mint)
+	if err != nil {
+		return nil, err
+	}
+	return &PumpMarket{Symbol: symbol, Mint: mint, Curve: curve}, nil
+}
+
+// refresh reads symbol's curve from chain and returns the updated market.
+// "<mint>/SOL" symbols are registered once their curve has been read, so
+// mints without one are never kept.
+func (p *PumpFun) refresh(ctx context.Context, symbol string) (PumpMarket, error) {
+	market, err := p.market(symbol)
+	if err != nil {
+		return PumpMarket{}, err
+	}
+	updated, err := p.read(ctx, market)
+	if err != nil {
+		return PumpMarket{}, err
 	}
 
-	if len(market.OrderBook.Asks) == 0 {
-		return 0, errors.New("no asks in orderbook")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.markets[symbol]; !exists {
+		p.register(market)
 	}
+	return updated, nil
+}
 
-	return market.OrderBook.Asks[0].Price, nil
+// read loads market's curve over RPC, whether or not it is registered yet.
+func (p *PumpFun) read(ctx context.Context, market *PumpMarket) (PumpMarket, error) {
+	account, err := p.client.GetAccountInfo(ctx, market.Curve, solana.CommitmentConfirmed)
+	if errors.Is(err, solana.ErrAccountNotFound) {
+		return PumpMarket{}, fmt.Errorf("%w: no bonding curve for %s", ErrMarketNotFound, market.Mint)
+	}
+	if err != nil {
+		return PumpMarket{}, fmt.Errorf("failed to read bonding curve %s: %w", market.Curve, err)
+	}
+	return p.update(ctx, market, account)
 }
 
-func (p *PumpFun) ExecuteOrder(order Order) error {
+// update stores the curve state in account, read over RPC or streamed, as
+// market's latest.
+func (p *PumpFun) update(ctx context.Context, market *PumpMarket, account *solana.AccountInfo) (PumpMarket, error) {
+	if account.Owner != PumpFunProgramID {
+		return PumpMarket{}, ErrNotBondingCurve
+	}
+	state, err := ParseBondingCurve(account.Data)
+	if err != nil {
+		return PumpMarket{}, err
+	}
+	global, err := p.loadGlobal(ctx)
+	if err != nil {
+		return PumpMarket{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	market.State = *state
+	market.Progress = state.Progress(global.InitialRealTokenReserves)
+	market.UpdatedAt = p.now()
+	return *market, nil
+}
+
+// loadGlobal reads the program's fee and initial reserves once.
+func (p *PumpFun) loadGlobal(ctx context.Context) (*PumpGlobal, error) {
 	p.mu.RLock()
-	_, exists := p.markets[order.Symbol]
+	global := p.global
 	p.mu.RUnlock()
+	if global != nil {
+		return global, nil
+	}
 
-	if !exists {
-		return errors.New("market not found")
+	account, err := p.client.GetAccountInfo(ctx, pumpGlobalAccount, solana.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pump.fun global config: %w", err)
+	}
+	global, err = ParsePumpGlobal(account.Data)
+	if err != nil {
+		return nil, err
 	}
 
-	// In production, this would interact with Pump.fun API
-	return nil
+	p.mu.Lock()
+	p.global = global
+	p.mu.Unlock()
+	return global, nil
 }
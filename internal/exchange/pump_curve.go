@@ -0,0 +1,291 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+var (
+	PumpFunProgramID = mustPublicKey("6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P")
+
+	pumpGlobalAccount  = mustPublicKey("4wTV1YmiEkRvAtNtsSGPtUrqRYQMe5SKy2uB4Jjaxnjf")
+	pumpEventAuthority = mustPublicKey("Ce6TQqeHC9p8KetsN6JsjHK7UTZk7nasjjnr7XxXp9F1")
+	sysvarRent         = mustPublicKey("SysvarRent111111111111111111111111111111111")
+)
+
+// Anchor discriminators: the first 8 bytes of sha256("account:<Name>") and
+// sha256("global:<instruction>").
+var (
+	bondingCurveDiscriminator = []byte{23, 183, 248, 55, 96, 216, 172, 96}
+	pumpGlobalDiscriminator   = []byte{167, 232, 232, 177, 200, 108, 114, 127}
+	pumpBuyDiscriminator      = []byte{102, 6, 61, 18, 1, 218, 235, 234}
+	pumpSellDiscriminator     = []byte{51, 230, 133, 164, 1, 127, 131, 173}
+)
+
+// Pump.fun tokens have 6 decimals and trade against SOL.
+const pumpTokenDecimals = 6
+
+var (
+	ErrCurveComplete   = errors.New("bonding curve complete; token has migrated")
+	ErrNotBondingCurve = errors.New("account is not a pump.fun bonding curve")
+)
+
+// BondingCurve is the on-chain state of one Pump.fun token's curve. Trades
+// price off the virtual reserves as a constant product; the real reserves
+// are what the curve actually holds. Once the real token reserves are sold
+// the curve is complete and liquidity migrates to an AMM.
+type BondingCurve struct {
+	VirtualTokenReserves uint64
+	VirtualSolReserves   uint64
+	RealTokenReserves    uint64
+	RealSolReserves      uint64
+	TokenTotalSupply     uint64
+	Complete             bool
+}
+
+// bondingCurveSize is the classic layout; newer curves append fields, which
+// are ignored.
+const bondingCurveSize = 8 + 5*8 + 1
+
+func ParseBondingCurve(data []byte) (*BondingCurve, error) {
+	if len(data) < bondingCurveSize || !bytes.Equal(data[:8], bondingCurveDiscriminator) {
+		return nil, ErrNotBondingCurve
+	}
+	le := binary.LittleEndian
+	return &BondingCurve{
+		VirtualTokenReserves: le.Uint64(data[8:]),
+		VirtualSolReserves:   le.Uint64(data[16:]),
+		RealTokenReserves:    le.Uint64(data[24:]),
+		RealSolReserves:      le.Uint64(data[32:]),
+		TokenTotalSupply:     le.Uint64(data[40:]),
+		Complete:             data[48] != 0,
+	}, nil
+}
+
+// Price is the marginal price in SOL per whole token.
+func (c *BondingCurve) Price() float64 {
+	if c.VirtualTokenReserves == 0 {
+		return 0
+	}
+	sol := float64(c.VirtualSolReserves) / float64(solana.LamportsPerSOL)
+	tokens := float64(c.VirtualTokenReserves) / math.Pow10(pumpTokenDecimals)
+	return sol / tokens
+}
+
+// BuyCost is the lamports, fee included, needed to buy exactly tokens base
+// units, and the fee of feeBps within them. It fails when the curve cannot
+// supply that many tokens.
+func (c *BondingCurve) BuyCost(tokens uint64, feeBps uint64) (cost, fee uint64, err error) {
+	if c.Complete {
+		return 0, 0, ErrCurveComplete
+	}
+	if tokens == 0 || tokens > c.RealTokenReserves || tokens >= c.VirtualTokenReserves {
+		return 0, 0, fmt.Errorf("%w: %d tokens requested, %d left on the curve", ErrInsufficientLiquidity, tokens, c.RealTokenReserves)
+	}
+	// sol = tokens * vsol / (vtok - tokens), rounded up as the program does
+	n := new(big.Int).Mul(new(big.Int).SetUint64(tokens), new(big.Int).SetUint64(c.VirtualSolReserves))
+	n.Quo(n, new(big.Int).SetUint64(c.VirtualTokenReserves-tokens))
+	if !n.IsUint64() {
+		return 0, 0, ErrInsufficientLiquidity
+	}
+	cost = n.Uint64() + 1
+	fee = mulDivBps(cost, feeBps)
+	return cost + fee, fee, nil
+}
+
+// BuyTokens is how many token base units lamports buys once the fee of
+// feeBps is taken out, capped at what is left on the curve.
+func (c *BondingCurve) BuyTokens(lamports uint64, feeBps uint64) (tokens, fee uint64, err error) {
+	if c.Complete {
+		return 0, 0, ErrCurveComplete
+	}
+	// The fee is charged on top of the SOL that reaches the curve
+	spend := new(big.Int).Mul(new(big.Int).SetUint64(lamports), big.NewInt(10000))
+	spend.Quo(spend, new(big.Int).SetUint64(10000+feeBps))
+	sol := spend.Uint64()
+	fee = lamports - sol
+
+	// tokens = vtok - vsol*vtok / (vsol + sol) - 1
+	k := new(big.Int).Mul(new(big.Int).SetUint64(c.VirtualSolReserves), new(big.Int).SetUint64(c.VirtualTokenReserves))
+	k.Quo(k, new(big.Int).Add(new(big.Int).SetUint64(c.VirtualSolReserves), new(big.Int).SetUint64(sol)))
+	remaining := k.Uint64() + 1
+	if remaining >= c.VirtualTokenReserves {
+		return 0, fee, nil
+	}
+	tokens = c.VirtualTokenReserves - remaining
+	if tokens > c.RealTokenReserves {
+		tokens = c.RealTokenReserves
+	}
+	return tokens, fee, nil
+}
+
+// SellProceeds is the lamports received for selling tokens base units after
+// the fee of feeBps, and the fee itself.
+func (c *BondingCurve) SellProceeds(tokens uint64, feeBps uint64) (proceeds, fee uint64, err error) {
+	if c.Complete {
+		return 0, 0, ErrCurveComplete
+	}
+	if tokens == 0 {
+		return 0, 0, errors.New("sell amount must be positive")
+	}
+	// sol = tokens * vsol / (vtok + tokens)
+	n := new(big.Int).Mul(new(big.Int).SetUint64(tokens), new(big.Int).SetUint64(c.VirtualSolReserves))
+	n.Quo(n, new(big.Int).Add(new(big.Int).SetUint64(c.VirtualTokenReserves), new(big.Int).SetUint64(tokens)))
+	sol := n.Uint64()
+	if sol > c.RealSolReserves {
+		return 0, 0, fmt.Errorf("%w: curve holds %d lamports, sale needs %d", ErrInsufficientLiquidity, c.RealSolReserves, sol)
+	}
+	fee = mulDivBps(sol, feeBps)
+	return sol - fee, fee, nil
+}
+
+// Progress is the share of the curve's sellable tokens already bought, from
+// 0 at launch to 1 when the curve completes.
+func (c *BondingCurve) Progress(initialRealTokenReserves uint64) float64 {
+	if c.Complete || initialRealTokenReserves == 0 {
+		return 1
+	}
+	if c.RealTokenReserves >= initialRealTokenReserves {
+		return 0
+	}
+	return 1 - float64(c.RealTokenReserves)/float64(initialRealTokenReserves)
+}
+
+// PumpGlobal is the program-wide configuration: where fees go, what they
+// are and the reserves every new curve starts with.
+type PumpGlobal struct {
+	FeeRecipient                solana.PublicKey
+	InitialVirtualTokenReserves uint64
+	InitialVirtualSolReserves   uint64
+	InitialRealTokenReserves    uint64
+	TokenTotalSupply            uint64
+	FeeBasisPoints              uint64
+}
+
+const pumpGlobalSize = 8 + 1 + 32 + 32 + 5*8
+
+func ParsePumpGlobal(data []byte) (*PumpGlobal, error) {
+	if len(data) < pumpGlobalSize || !bytes.Equal(data[:8], pumpGlobalDiscriminator) {
+		return nil, errors.New("account is not the pump.fun global config")
+	}
+	global := &PumpGlobal{}
+	copy(global.FeeRecipient[:], data[41:73])
+	le := binary.LittleEndian
+	global.InitialVirtualTokenReserves = le.Uint64(data[73:])
+	global.InitialVirtualSolReserves = le.Uint64(data[81:])
+	global.InitialRealTokenReserves = le.Uint64(data[89:])
+	global.TokenTotalSupply = le.Uint64(data[97:])
+	global.FeeBasisPoints = le.Uint64(data[105:])
+	return global, nil
+}
+
+// InitialCurve is the state a newly created curve starts in.
+func (g *PumpGlobal) InitialCurve() BondingCurve {
+	return BondingCurve{
+		VirtualTokenReserves: g.InitialVirtualTokenReserves,
+		VirtualSolReserves:   g.InitialVirtualSolReserves,
+		RealTokenReserves:    g.InitialRealTokenReserves,
+		TokenTotalSupply:     g.TokenTotalSupply,
+	}
+}
+
+// BondingCurveAddress derives the curve account for mint.
+func BondingCurveAddress(mint solana.PublicKey) (solana.PublicKey, error) {
+	address, _, err := solana.FindProgramAddress([][]byte{[]byte("bonding-curve"), mint[:]}, PumpFunProgramID)
+	return address, err
+}
+
+// pumpTradeAccounts are the accounts shared by the buy and sell
+// instructions, in the program's order up to the user.
+func pumpTradeAccounts(global *PumpGlobal, mint, user solana.PublicKey) ([]solana.AccountMeta, error) {
+	curve, err := BondingCurveAddress(mint)
+	if err != nil {
+		return nil, err
+	}
+	curveTokens, err := solana.AssociatedTokenAddress(curve, mint, solana.TokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+	userTokens, err := solana.AssociatedTokenAddress(user, mint, solana.TokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+	return []solana.AccountMeta{
+		{PublicKey: pumpGlobalAccount},
+		{PublicKey: global.FeeRecipient, IsWritable: true},
+		{PublicKey: mint},
+		{PublicKey: curve, IsWritable: true},
+		{PublicKey: curveTokens, IsWritable: true},
+		{PublicKey: userTokens, IsWritable: true},
+		{PublicKey: user, IsSigner: true, IsWritable: true},
+	}, nil
+}
+
+// PumpBuy buys exactly tokens base units of mint for at most maxSolCost
+// lamports, fee included.
+func PumpBuy(global *PumpGlobal, mint, user solana.PublicKey, tokens, maxSolCost uint64) (solana.Instruction, error) {
+	accounts, err := pumpTradeAccounts(global, mint, user)
+	if err != nil {
+		return solana.Instruction{}, err
+	}
+	accounts = append(accounts,
+		solana.AccountMeta{PublicKey: solana.SystemProgramID},
+		solana.AccountMeta{PublicKey: solana.TokenProgramID},
+		solana.AccountMeta{PublicKey: sysvarRent},
+		solana.AccountMeta{PublicKey: pumpEventAuthority},
+		solana.AccountMeta{PublicKey: PumpFunProgramID},
+	)
+	return solana.Instruction{
+		ProgramID: PumpFunProgramID,
+		Accounts:  accounts,
+		Data:      pumpTradeData(pumpBuyDiscriminator, tokens, maxSolCost),
+	}, nil
+}
+
+// PumpSell sells tokens base units of mint for at least minSolOutput
+// lamports after fees.
+func PumpSell(global *PumpGlobal, mint, user solana.PublicKey, tokens, minSolOutput uint64) (solana.Instruction, error) {
+	accounts, err := pumpTradeAccounts(global, mint, user)
+	if err != nil {
+		return solana.Instruction{}, err
+	}
+	accounts = append(accounts,
+		solana.AccountMeta{PublicKey: solana.SystemProgramID},
+		solana.AccountMeta{PublicKey: solana.AssociatedTokenProgramID},
+		solana.AccountMeta{PublicKey: solana.TokenProgramID},
+		solana.AccountMeta{PublicKey: pumpEventAuthority},
+		solana.AccountMeta{PublicKey: PumpFunProgramID},
+	)
+	return solana.Instruction{
+		ProgramID: PumpFunProgramID,
+		Accounts:  accounts,
+		Data:      pumpTradeData(pumpSellDiscriminator, tokens, minSolOutput),
+	}, nil
+}
+
+func pumpTradeData(discriminator []byte, amount, limit uint64) []byte {
+	data := make([]byte, 24)
+	copy(data, discriminator)
+	binary.LittleEndian.PutUint64(data[8:], amount)
+	binary.LittleEndian.PutUint64(data[16:], limit)
+	return data
+}
+
+func mulDivBps(amount, bps uint64) uint64 {
+	n := new(big.Int).Mul(new(big.Int).SetUint64(amount), new(big.Int).SetUint64(bps))
+	return n.Quo(n, big.NewInt(10000)).Uint64()
+}
+
+func mustPublicKey(s string) solana.PublicKey {
+	key, err := solana.PublicKeyFromBase58(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
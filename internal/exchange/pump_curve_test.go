@@ -0,0 +1,335 @@
+package exchange
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// launchCurve is the state every Pump.fun curve starts in.
+var launchCurve = BondingCurve{
+	VirtualTokenReserves: 1073000000000000,
+	VirtualSolReserves:   30000000000,
+	RealTokenReserves:    793100000000000,
+	TokenTotalSupply:     1000000000000000,
+}
+
+var testPumpGlobal = PumpGlobal{
+	FeeRecipient:                solana.PublicKey{0xfe},
+	InitialVirtualTokenReserves: 1073000000000000,
+	InitialVirtualSolReserves:   30000000000,
+	InitialRealTokenReserves:    793100000000000,
+	TokenTotalSupply:            1000000000000000,
+	FeeBasisPoints:              100,
+}
+
+func encodeCurve(c BondingCurve) []byte {
+	data := make([]byte, bondingCurveSize+32)
+	copy(data, bondingCurveDiscriminator)
+	le := binary.LittleEndian
+	le.PutUint64(data[8:], c.VirtualTokenReserves)
+	le.PutUint64(data[16:], c.VirtualSolReserves)
+	le.PutUint64(data[24:], c.RealTokenReserves)
+	le.PutUint64(data[32:], c.RealSolReserves)
+	le.PutUint64(data[40:], c.TokenTotalSupply)
+	if c.Complete {
+		data[48] = 1
+	}
+	return data
+}
+
+func encodeGlobal(g PumpGlobal) []byte {
+	data := make([]byte, pumpGlobalSize)
+	copy(data, pumpGlobalDiscriminator)
+	data[8] = 1
+	copy(data[41:], g.FeeRecipient[:])
+	le := binary.LittleEndian
+	le.PutUint64(data[73:], g.InitialVirtualTokenReserves)
+	le.PutUint64(data[81:], g.InitialVirtualSolReserves)
+	le.PutUint64(data[89:], g.InitialRealTokenReserves)
+	le.PutUint64(data[97:], g.TokenTotalSupply)
+	le.PutUint64(data[105:], g.FeeBasisPoints)
+	return data
+}
+
+type pumpAccount struct {
+	owner solana.PublicKey
+	data  []byte
+}
+
+// pumpChain is a JSON-RPC node serving Pump.fun accounts and accepting any
+// transaction signed by its payer.
+type pumpChain struct {
+	t        *testing.T
+	mu       sync.Mutex
+	accounts map[solana.PublicKey]pumpAccount
+	sent     []*solana.Message
+	// unconfirmed leaves sent transactions without a status
+	unconfirmed bool
+}
+
+func newPumpChain(t *testing.T) (*pumpChain, *httptest.Server) {
+	chain := &pumpChain{t: t, accounts: make(map[solana.PublicKey]pumpAccount)}
+	chain.accounts[pumpGlobalAccount] = pumpAccount{PumpFunProgramID, encodeGlobal(testPumpGlobal)}
+	server := httptest.NewServer(chain)
+	t.Cleanup(server.Close)
+	return chain, server
+}
+
+func (c *pumpChain) setCurve(mint solana.PublicKey, curve BondingCurve) {
+	address, err := BondingCurveAddress(mint)
+	require.NoError(c.t, err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts[address] = pumpAccount{PumpFunProgramID, encodeCurve(curve)}
+}
+
+func (c *pumpChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int               `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	require.NoError(c.t, json.NewDecoder(r.Body).Decode(&req))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wrap := func(value interface{}) interface{} {
+		return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": value}
+	}
+	var result interface{}
+	switch req.Method {
+	case "getAccountInfo":
+		var key solana.PublicKey
+		require.NoError(c.t, json.Unmarshal(req.Params[0], &key))
+		account, ok := c.accounts[key]
+		if !ok {
+			result = wrap(nil)
+			break
+		}
+		result = wrap(map[string]interface{}{
+			"lamports": 1461600,
+			"owner":    account.owner,
+			"data":     []string{base64.StdEncoding.EncodeToString(account.data), "base64"},
+		})
+	case "getLatestBlockhash":
+		result = wrap(map[string]interface{}{"blockhash": solana.Hash{7}, "lastValidBlockHeight": 100})
+	case "sendTransaction":
+		var encoded string
+		require.NoError(c.t, json.Unmarshal(req.Params[0], &encoded))
+		tx, err := solana.ParseTransactionBase64(encoded)
+		require.NoError(c.t, err)
+		msg, err := tx.DecodeMessage()
+		require.NoError(c.t, err)
+		assert.True(c.t, ed25519.Verify(msg.AccountKeys[0][:], tx.Message, tx.Signatures[0][:]))
+		c.sent = append(c.sent, msg)
+		result = tx.Signatures[0].String()
+	case "getSignatureStatuses":
+		if c.unconfirmed {
+			result = wrap([]interface{}{nil})
+			break
+		}
+		result = wrap([]interface{}{map[string]interface{}{"slot": 1, "confirmations": 1, "err": nil, "confirmationStatus": "confirmed"}})
+	default:
+		c.t.Errorf("unexpected method %s", req.Method)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func TestBondingCurve(t *testing.T) {
+	curve, err := ParseBondingCurve(encodeCurve(launchCurve))
+	require.NoError(t, err)
+	assert.Equal(t, launchCurve, *curve)
+	assert.InDelta(t, 2.7958993e-8, curve.Price(), 1e-15)
+	assert.Zero(t, curve.Progress(testPumpGlobal.InitialRealTokenReserves))
+
+	// One million tokens off a fresh curve
+	cost, fee, err := curve.BuyCost(1e12, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(28264925), cost)
+	assert.Equal(t, uint64(279850), fee)
+
+	tokens, fee, err := curve.BuyTokens(28264925, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(999999977620), tokens)
+	assert.Equal(t, uint64(279851), fee)
+
+	bought := BondingCurve{
+		VirtualTokenReserves: launchCurve.VirtualTokenReserves - 1e12,
+		VirtualSolReserves:   launchCurve.VirtualSolReserves + 27985075,
+		RealTokenReserves:    launchCurve.RealTokenReserves - 1e12,
+		RealSolReserves:      27985075,
+		TokenTotalSupply:     launchCurve.TokenTotalSupply,
+	}
+	proceeds, fee, err := bought.SellProceeds(1e12, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(27705224), proceeds)
+	assert.Equal(t, uint64(279850), fee)
+	assert.InDelta(t, 0.00126, bought.Progress(testPumpGlobal.InitialRealTokenReserves), 1e-5)
+
+	_, _, err = curve.BuyCost(launchCurve.RealTokenReserves+1, 100)
+	assert.ErrorIs(t, err, ErrInsufficientLiquidity)
+	_, _, err = curve.SellProceeds(1e12, 100)
+	assert.ErrorIs(t, err, ErrInsufficientLiquidity)
+
+	complete := bought
+	complete.Complete = true
+	_, _, err = complete.BuyCost(1, 100)
+	assert.ErrorIs(t, err, ErrCurveComplete)
+	_, _, err = complete.SellProceeds(1, 100)
+	assert.ErrorIs(t, err, ErrCurveComplete)
+	assert.Equal(t, 1.0, complete.Progress(testPumpGlobal.InitialRealTokenReserves))
+
+	_, err = ParseBondingCurve(encodeGlobal(testPumpGlobal))
+	assert.ErrorIs(t, err, ErrNotBondingCurve)
+	global, err := ParsePumpGlobal(encodeGlobal(testPumpGlobal))
+	require.NoError(t, err)
+	assert.Equal(t, testPumpGlobal, *global)
+}
+
+func TestPumpFun_MarketData(t *testing.T) {
+	chain, server := newPumpChain(t)
+	live, migrated := solana.PublicKey{0x01, 0x70}, solana.PublicKey{0x02, 0x70}
+	chain.setCurve(live, launchCurve)
+	chain.setCurve(migrated, BondingCurve{VirtualTokenReserves: 279900000000000, VirtualSolReserves: 115005359057, TokenTotalSupply: 1e15, Complete: true})
+
+	pump := NewPumpFunWithClient(solana.NewClient(server.URL))
+	require.NoError(t, pump.AddToken("LIVE/SOL", live))
+	require.NoError(t, pump.AddToken("DONE/SOL", migrated))
+	assert.Error(t, pump.AddToken("LIVE/SOL", live))
+	assert.ErrorIs(t, pump.AddToken("NONE/SOL", solana.PublicKey{0x03}), ErrMarketNotFound)
+	assert.Error(t, pump.AddToken("LIVE/USDC", live))
+
+	price, err := pump.GetMarketPrice("LIVE/SOL")
+	require.NoError(t, err)
+	assert.Equal(t, launchCurve.Price(), price)
+	_, err = pump.GetMarketPrice("DONE/SOL")
+	assert.ErrorIs(t, err, ErrCurveComplete)
+	_, err = pump.GetMarketPrice("BONK/SOL")
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+
+	// Tokens can be named by mint without registering them first, but
+	// mints without a curve are not kept
+	price, err = pump.GetMarketPrice(live.String() + "/SOL")
+	require.NoError(t, err)
+	assert.Equal(t, launchCurve.Price(), price)
+	_, err = pump.GetMarketPrice(solana.PublicKey{0x03}.String() + "/SOL")
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+
+	data, err := pump.GetMarketData()
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+	assert.Equal(t, live.String()+"/SOL", data[0].Symbol)
+	assert.Equal(t, "LIVE/SOL", data[1].Symbol)
+
+	markets := pump.Markets()
+	require.Len(t, markets, 3)
+	assert.Equal(t, "DONE/SOL", markets[1].Symbol)
+	assert.True(t, markets[1].State.Complete)
+	assert.Equal(t, 1.0, markets[1].Progress)
+	assert.Zero(t, markets[2].Progress)
+
+	// Curves that cannot be read are reported with the rest of the data
+	curve, err := BondingCurveAddress(live)
+	require.NoError(t, err)
+	chain.mu.Lock()
+	chain.accounts[curve] = pumpAccount{PumpFunProgramID, []byte{1}}
+	chain.mu.Unlock()
+	data, err = pump.GetMarketData()
+	var dataErr *MarketDataError
+	require.ErrorAs(t, err, &dataErr)
+	assert.Len(t, dataErr.Failed, 2)
+	assert.Contains(t, dataErr.Failed, "LIVE/SOL")
+	assert.Empty(t, data)
+}
+
+func TestPumpFun_ExecuteOrder(t *testing.T) {
+	chain, server := newPumpChain(t)
+	mint := solana.PublicKey{0x01, 0x70}
+	chain.setCurve(mint, launchCurve)
+
+	pump := NewPumpFunWithClient(solana.NewClient(server.URL))
+	require.NoError(t, pump.AddToken("LIVE/SOL", mint))
+	_, err := pump.ExecuteOrder(Order{ID: "1", Symbol: "LIVE/SOL", Side: "buy", Amount: 1e6})
+	assert.ErrorIs(t, err, ErrNoSubmitter)
+
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	submitter := newTestSubmitter(t, server.URL, key)
+	pump.SetSubmitter(submitter)
+	user := submitter.PublicKey()
+	assert.Error(t, pump.SetSlippage(10001))
+
+	report, err := pump.ExecuteOrder(Order{ID: "1", Symbol: "LIVE/SOL", Side: "buy", Amount: 1e6})
+	require.NoError(t, err)
+	assert.Equal(t, 1e6, report.FilledAmount)
+	assert.InDelta(t, 0.027985075/1e6, report.AvgPrice, 1e-15)
+	assert.InDelta(t, 0.00027985, report.Fee, 1e-12)
+	assert.NotEmpty(t, report.TxID)
+
+	// Creates the user's token account, then buys with 5% slippage on cost
+	require.Len(t, chain.sent, 1)
+	msg := chain.sent[0]
+	require.Len(t, msg.Instructions, 2)
+	buy := msg.Instructions[1]
+	assert.Equal(t, PumpFunProgramID, msg.AccountKeys[buy.ProgramIDIndex])
+	assert.Equal(t, pumpBuyDiscriminator, buy.Data[:8])
+	assert.Equal(t, uint64(1e12), binary.LittleEndian.Uint64(buy.Data[8:]))
+	assert.Equal(t, uint64(28264925+1413246), binary.LittleEndian.Uint64(buy.Data[16:]))
+	require.Len(t, buy.Accounts, 12)
+	assert.Equal(t, user, msg.AccountKeys[buy.Accounts[6]])
+	assert.Equal(t, testPumpGlobal.FeeRecipient, msg.AccountKeys[buy.Accounts[1]])
+	curve, _ := BondingCurveAddress(mint)
+	assert.Equal(t, curve, msg.AccountKeys[buy.Accounts[3]])
+
+	// Limit orders are bounded by their price rather than slippage
+	chain.setCurve(mint, BondingCurve{
+		VirtualTokenReserves: launchCurve.VirtualTokenReserves - 1e12,
+		VirtualSolReserves:   launchCurve.VirtualSolReserves + 27985075,
+		RealTokenReserves:    launchCurve.RealTokenReserves - 1e12,
+		RealSolReserves:      27985075,
+		TokenTotalSupply:     launchCurve.TokenTotalSupply,
+	})
+	_, err = pump.ExecuteOrder(Order{ID: "2", Symbol: "LIVE/SOL", Side: "sell", Amount: 1e6, OrderType: "limit", Price: 3e-8})
+	assert.ErrorIs(t, err, ErrInsufficientLiquidity)
+	report, err = pump.ExecuteOrder(Order{ID: "3", Symbol: "LIVE/SOL", Side: "sell", Amount: 1e6, OrderType: "limit", Price: 2.7e-8})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.027985074/1e6, report.AvgPrice, 1e-15)
+
+	require.Len(t, chain.sent, 2)
+	sell := chain.sent[1].Instructions[0]
+	assert.True(t, bytes.Equal(pumpSellDiscriminator, sell.Data[:8]))
+	assert.Equal(t, uint64(1e12), binary.LittleEndian.Uint64(sell.Data[8:]))
+	assert.Equal(t, uint64(26730000), binary.LittleEndian.Uint64(sell.Data[16:]))
+
+	// A trade sent but never confirmed keeps its signature
+	chain.mu.Lock()
+	chain.unconfirmed = true
+	chain.mu.Unlock()
+	submitter.config.Timeout = 50 * time.Millisecond
+	_, err = pump.ExecuteOrder(Order{ID: "6", Symbol: "LIVE/SOL", Side: "sell", Amount: 1e6})
+	assert.ErrorIs(t, err, solana.ErrConfirmationTimeout)
+	var submitErr *SubmitError
+	require.ErrorAs(t, err, &submitErr)
+	assert.True(t, submitErr.Pending())
+	require.Len(t, chain.sent, 3)
+	sig, err := solana.SignatureFromBase58(submitErr.TxID)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), chain.sent[2].Serialize(), sig[:]))
+
+	_, err = pump.ExecuteOrder(Order{ID: "4", Symbol: "LIVE/SOL", Side: "hold", Amount: 1})
+	assert.Error(t, err)
+	_, err = pump.ExecuteOrder(Order{ID: "5", Symbol: "LIVE/SOL", Side: "buy", Amount: 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,242 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+// createEventDiscriminator is sha256("event:CreateEvent")[:8], the prefix of
+// the event Pump.fun logs for every token it creates.
+var createEventDiscriminator = []byte{27, 114, 169, 77, 222, 235, 99, 118}
+
+const launchBuffer = 64
+
+var ErrNotCreateEvent = errors.New("data is not a pump.fun create event")
+
+// TokenLaunch is a token created on Pump.fun, as announced by its
+// CreateEvent.
+type TokenLaunch struct {
+	Mint         solana.PublicKey
+	BondingCurve solana.PublicKey
+	Creator      solana.PublicKey
+	Name         string
+	Symbol       string
+	// URI points at the token's metadata JSON, usually on IPFS.
+	URI string
+	// Reserves is the curve's state at launch.
+	Reserves  BondingCurve
+	Signature string
+	Slot      uint64
+	// Timestamp is the on-chain creation time, or when the feed saw the
+	// launch if the event does not carry one.
+	Timestamp time.Time
+}
+
+// Market is the symbol PumpFun trades the token under. Ticker symbols are
+// not unique on Pump.fun, so markets are named by mint.
+func (l TokenLaunch) Market() string {
+	return l.Mint.String() + "/SOL"
+}
+
+// LaunchSource is implemented by exchanges that announce new tokens as they
+// are created, so strategies can react without waiting for a poll. The
+// channel closes once ctx is cancelled.
+type LaunchSource interface {
+	SubscribeLaunches(ctx context.Context) (<-chan TokenLaunch, error)
+}
+
+// ParseCreateEvent decodes a CreateEvent. Events from before the program
+// recorded creators, timestamps and reserves leave those fields zero, with
+// Creator taken from the signing user.
+func ParseCreateEvent(data []byte) (*TokenLaunch, error) {
+	if len(data) < 8 || !bytes.Equal(data[:8], createEventDiscriminator) {
+		return nil, ErrNotCreateEvent
+	}
+	r := eventReader{data: data[8:]}
+	launch := &TokenLaunch{
+		Name:   r.string(),
+		Symbol: r.string(),
+		URI:    r.string(),
+	}
+	launch.Mint = r.publicKey()
+	launch.BondingCurve = r.publicKey()
+	launch.Creator = r.publicKey()
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotCreateEvent, r.err)
+	}
+	if len(r.data) == 0 {
+		return launch, nil
+	}
+
+	launch.Creator = r.publicKey()
+	timestamp := int64(r.uint64())
+	launch.Reserves = BondingCurve{
+		VirtualTokenReserves: r.uint64(),
+		VirtualSolReserves:   r.uint64(),
+		RealTokenReserves:    r.uint64(),
+		TokenTotalSupply:     r.uint64(),
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotCreateEvent, r.err)
+	}
+	launch.Timestamp = time.Unix(timestamp, 0).UTC()
+	return launch, nil
+}
+
+// eventReader decodes Borsh fields, remembering the first short read.
+type eventReader struct {
+	data []byte
+	err  error
+}
+
+func (r *eventReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.New("event truncated")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *eventReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *eventReader) string() string {
+	b := r.next(4)
+	if b == nil {
+		return ""
+	}
+	return string(r.next(int(binary.LittleEndian.Uint32(b))))
+}
+
+func (r *eventReader) publicKey() solana.PublicKey {
+	var key solana.PublicKey
+	copy(key[:], r.next(32))
+	return key
+}
+
+// SetStreamConfig configures how SubscribeLaunches and Subscribe reach the
+// node.
+func (p *PumpFun) SetStreamConfig(config StreamConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stream = config
+}
+
+func (p *PumpFun) streamConfig() StreamConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stream.withDefaults(p.client.Endpoint())
+}
+
+// SubscribeLaunches streams tokens created on Pump.fun, as the node confirms
+// their create transactions, until ctx is cancelled and the channel closes.
+// The first subscription must succeed; after that a dropped stream is
+// redialled, and launches made while it was down are not replayed.
+func (p *PumpFun) SubscribeLaunches(ctx context.Context) (<-chan TokenLaunch, error) {
+	config := p.streamConfig()
+	sub, err := solana.SubscribeLogs(ctx, config.Dialer, config.Endpoint, PumpFunProgramID, config.Commitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to pump.fun launches: %w", err)
+	}
+
+	launches := make(chan TokenLaunch, launchBuffer)
+	go p.streamLaunches(ctx, config, sub, launches)
+	return launches, nil
+}
+
+func (p *PumpFun) streamLaunches(ctx context.Context, config StreamConfig, sub *solana.LogsSubscription, launches chan<- TokenLaunch) {
+	defer close(launches)
+
+	delay := config.ReconnectDelay
+	for {
+		if sub != nil {
+			err := p.forwardLaunches(ctx, sub, launches, config.report)
+			if ctx.Err() != nil {
+				return
+			}
+			config.report(fmt.Errorf("pump.fun launch stream dropped: %w", err))
+			delay = config.ReconnectDelay
+		}
+
+		var ok bool
+		if delay, ok = config.wait(ctx, delay); !ok {
+			return
+		}
+		var err error
+		sub, err = solana.SubscribeLogs(ctx, config.Dialer, config.Endpoint, PumpFunProgramID, config.Commitment)
+		if err != nil && ctx.Err() == nil {
+			config.report(fmt.Errorf("failed to resubscribe to pump.fun launches: %w", err))
+		}
+	}
+}
+
+// forwardLaunches sends the launches in sub's notifications until it fails.
+func (p *PumpFun) forwardLaunches(ctx context.Context, sub *solana.LogsSubscription, launches chan<- TokenLaunch, report func(error)) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		sub.Close()
+	}()
+
+	for {
+		logs, err := sub.Recv()
+		if err != nil {
+			return err
+		}
+		if logs.Err != nil {
+			continue // Failed creates launch nothing
+		}
+		events, err := solana.ProgramData(logs.Logs, PumpFunProgramID)
+		if err != nil {
+			report(fmt.Errorf("transaction %s: %w", logs.Signature, err))
+			continue
+		}
+		for _, event := range events {
+			if !bytes.HasPrefix(event, createEventDiscriminator) {
+				continue
+			}
+			launch, err := ParseCreateEvent(event)
+			if err != nil {
+				report(fmt.Errorf("transaction %s: %w", logs.Signature, err))
+				continue
+			}
+			launch.Signature = logs.Signature
+			launch.Slot = logs.Slot
+			if launch.Timestamp.IsZero() {
+				launch.Timestamp = p.now()
+			}
+			if launch.Reserves == (BondingCurve{}) {
+				if global, err := p.loadGlobal(ctx); err == nil {
+					launch.Reserves = global.InitialCurve()
+				} else {
+					report(err)
+				}
+			}
+
+			select {
+			case launches <- *launch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
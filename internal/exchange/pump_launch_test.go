@@ -0,0 +1,240 @@
+package exchange
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLaunch = TokenLaunch{
+	Mint:         solana.PublicKey{0x01, 0x70},
+	BondingCurve: solana.PublicKey{0x02},
+	Creator:      solana.PublicKey{0x03},
+	Name:         "Launch Test",
+	Symbol:       "LT",
+	URI:          "https://ipfs.io/ipfs/QmLaunch",
+	Reserves:     launchCurve,
+	Timestamp:    time.Unix(1718000000, 0).UTC(),
+}
+
+// encodeCreateEvent lays out a CreateEvent; legacy events stop after the
+// signing user.
+func encodeCreateEvent(l TokenLaunch, legacy bool) []byte {
+	data := append([]byte{}, createEventDiscriminator...)
+	for _, s := range []string{l.Name, l.Symbol, l.URI} {
+		length := make([]byte, 4)
+		binary.LittleEndian.PutUint32(length, uint32(len(s)))
+		data = append(append(data, length...), s...)
+	}
+	data = append(data, l.Mint[:]...)
+	data = append(data, l.BondingCurve[:]...)
+	data = append(data, l.Creator[:]...)
+	if legacy {
+		return data
+	}
+	data = append(data, l.Creator[:]...)
+	for _, v := range []uint64{uint64(l.Timestamp.Unix()), l.Reserves.VirtualTokenReserves, l.Reserves.VirtualSolReserves, l.Reserves.RealTokenReserves, l.Reserves.TokenTotalSupply} {
+		field := make([]byte, 8)
+		binary.LittleEndian.PutUint64(field, v)
+		data = append(data, field...)
+	}
+	return data
+}
+
+// createLogs is the log of a create transaction, with an unrelated program
+// logging data inside it.
+func createLogs(event []byte) []string {
+	program := PumpFunProgramID.String()
+	return []string{
+		"Program " + program + " invoke [1]",
+		"Program log: Instruction: Create",
+		"Program " + solana.TokenProgramID.String() + " invoke [2]",
+		"Program data: " + base64.StdEncoding.EncodeToString(encodeCurve(launchCurve)),
+		"Program " + solana.TokenProgramID.String() + " success",
+		"Program data: " + base64.StdEncoding.EncodeToString(event),
+		"Program " + program + " success",
+	}
+}
+
+// nodeConn is the node's side of one PubSub connection. Writes are
+// serialized since the node answers requests while tests send
+// notifications.
+type nodeConn struct {
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	methods []string
+	params  [][]json.RawMessage
+}
+
+func (c *nodeConn) send(t *testing.T, msg interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.NoError(t, c.conn.WriteJSON(msg))
+}
+
+func (c *nodeConn) notify(t *testing.T, method string, subscription uint64, result interface{}) {
+	c.send(t, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  map[string]interface{}{"subscription": subscription, "result": result},
+	})
+}
+
+func (c *nodeConn) Close() error {
+	return c.conn.Close()
+}
+
+// pubsubNode is a local PubSub endpoint numbering subscriptions from 1 in
+// the order they are made. Each connection is handed to the test once it has
+// made as many subscriptions as given for it, the last count applying to
+// every later connection. The test writes notifications to it and closes it
+// to drop the stream.
+func pubsubNode(t *testing.T, subscriptions ...int) (string, chan *nodeConn) {
+	conns := make(chan *nodeConn, 4)
+	upgrader := websocket.Upgrader{}
+	var mu sync.Mutex
+	served := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		mu.Lock()
+		count := subscriptions[len(subscriptions)-1]
+		if served < len(subscriptions) {
+			count = subscriptions[served]
+		}
+		served++
+		mu.Unlock()
+		node := &nodeConn{conn: conn}
+		for i := 1; i <= count; i++ {
+			var req struct {
+				ID     uint64            `json:"id"`
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			node.methods = append(node.methods, req.Method)
+			node.params = append(node.params, req.Params)
+			node.send(t, map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": i})
+		}
+		conns <- node
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return solana.WebSocketURL(server.URL), conns
+}
+
+func notifyLogs(t *testing.T, conn *nodeConn, signature string, txErr interface{}, logs []string) {
+	conn.notify(t, "logsNotification", 1, map[string]interface{}{
+		"context": map[string]int{"slot": 250000000},
+		"value":   map[string]interface{}{"signature": signature, "err": txErr, "logs": logs},
+	})
+}
+
+func receiveLaunch(t *testing.T, launches <-chan TokenLaunch) TokenLaunch {
+	select {
+	case launch, ok := <-launches:
+		require.True(t, ok, "launch feed closed")
+		return launch
+	case <-time.After(2 * time.Second):
+		t.Fatal("no launch received")
+		return TokenLaunch{}
+	}
+}
+
+func TestParseCreateEvent(t *testing.T) {
+	launch, err := ParseCreateEvent(encodeCreateEvent(testLaunch, false))
+	require.NoError(t, err)
+	assert.Equal(t, testLaunch, *launch)
+	assert.Equal(t, testLaunch.Mint.String()+"/SOL", launch.Market())
+
+	launch, err = ParseCreateEvent(encodeCreateEvent(testLaunch, true))
+	require.NoError(t, err)
+	assert.Equal(t, testLaunch.URI, launch.URI)
+	assert.Equal(t, testLaunch.Creator, launch.Creator)
+	assert.Zero(t, launch.Reserves)
+	assert.True(t, launch.Timestamp.IsZero())
+
+	event := encodeCreateEvent(testLaunch, false)
+	_, err = ParseCreateEvent(event[:len(event)-1])
+	assert.ErrorIs(t, err, ErrNotCreateEvent)
+	_, err = ParseCreateEvent(encodeCurve(launchCurve))
+	assert.ErrorIs(t, err, ErrNotCreateEvent)
+}
+
+func TestPumpFun_SubscribeLaunches(t *testing.T) {
+	_, node := newPumpChain(t)
+	endpoint, conns := pubsubNode(t, 1)
+	errs := make(chan error, 10)
+
+	pump := NewPumpFunWithClient(solana.NewClient(node.URL))
+	pump.now = func() time.Time { return time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC) }
+	pump.SetStreamConfig(StreamConfig{
+		Endpoint:       endpoint,
+		ReconnectDelay: 10 * time.Millisecond,
+		OnError:        func(err error) { errs <- err },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	launches, err := pump.SubscribeLaunches(ctx)
+	require.NoError(t, err)
+	conn := <-conns
+	assert.Equal(t, []string{"logsSubscribe"}, conn.methods)
+
+	notifyLogs(t, conn, "failed", map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}, createLogs(encodeCreateEvent(testLaunch, false)))
+	notifyLogs(t, conn, "buy", nil, []string{"Program " + PumpFunProgramID.String() + " invoke [1]", "Program log: Instruction: Buy"})
+	notifyLogs(t, conn, "create", nil, createLogs(encodeCreateEvent(testLaunch, false)))
+
+	launch := receiveLaunch(t, launches)
+	want := testLaunch
+	want.Signature = "create"
+	want.Slot = 250000000
+	assert.Equal(t, want, launch)
+
+	// Legacy events take their reserves from the global config
+	notifyLogs(t, conn, "legacy", nil, createLogs(encodeCreateEvent(testLaunch, true)))
+	launch = receiveLaunch(t, launches)
+	assert.Equal(t, testPumpGlobal.InitialCurve(), launch.Reserves)
+	assert.Equal(t, pump.now(), launch.Timestamp)
+
+	// A dropped stream is redialled
+	conn.Close()
+	conn = <-conns
+	assert.Contains(t, (<-errs).Error(), "dropped")
+	notifyLogs(t, conn, "after", nil, createLogs(encodeCreateEvent(testLaunch, false)))
+	assert.Equal(t, "after", receiveLaunch(t, launches).Signature)
+
+	cancel()
+	select {
+	case _, ok := <-launches:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("launch feed not closed after cancel")
+	}
+}
+
+func TestPumpFun_SubscribeLaunchesUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	pump := NewPumpFunWithClient(solana.NewClient(server.URL))
+	_, err := pump.SubscribeLaunches(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+const marketEventBuffer = 256
+
+// errMarketsAdded ends a connection that follows every market so the next
+// one subscribes to the markets registered since.
+var errMarketsAdded = errors.New("markets added")
+
+// pumpStream follows bonding curves over PubSub. A slot subscription on the
+// same connection is the heartbeat: slots arrive several times a second, so
+// silence means the connection is dead, and a slot whose parent is past the
+// last one seen means notifications were lost. A stream over every market
+// resubscribes whenever another one is registered.
+type pumpStream struct {
+	pump    *PumpFun
+	config  StreamConfig
+	all     bool
+	added   <-chan struct{}
+	markets []*PumpMarket
+	events  chan MarketEvent
+	slot    uint64
+}
+
+// pumpConn is one connection's subscriptions.
+type pumpConn struct {
+	pubsub *solana.PubSub
+	slots  uint64
+	curves map[uint64]*PumpMarket
+}
+
+// Subscribe streams the prices of symbols as their curves change on chain,
+// starting with a snapshot of each. Without symbols it follows every
+// market, including those registered later. Completed curves are silent, as
+// in GetMarketData. The first connection must succeed; later ones are
+// retried until ctx is cancelled.
+func (p *PumpFun) Subscribe(ctx context.Context, symbols []string) (<-chan MarketEvent, error) {
+	sort.Strings(symbols)
+
+	stream := &pumpStream{
+		pump:   p,
+		config: p.streamConfig(),
+		all:    len(symbols) == 0,
+		events: make(chan MarketEvent, marketEventBuffer),
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, pumpRPCTimeout)
+	defer cancel()
+	for _, symbol := range symbols {
+		// Reading the curve registers "<mint>/SOL" symbols before following them
+		if _, err := p.refresh(rpcCtx, symbol); err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		market, err := p.market(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		stream.markets = append(stream.markets, market)
+	}
+
+	conn, err := stream.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to pump.fun markets: %w", err)
+	}
+	go stream.run(ctx, conn)
+	return stream.events, nil
+}
+
+func (s *pumpStream) run(ctx context.Context, conn *pumpConn) {
+	defer close(s.events)
+
+	delay := s.config.ReconnectDelay
+	for {
+		reason := "reconnected"
+		if conn != nil {
+			err := s.serve(ctx, conn)
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, errMarketsAdded) {
+				reason = err.Error()
+			} else {
+				s.config.report(fmt.Errorf("pump.fun market stream dropped: %w", err))
+				delay = s.config.ReconnectDelay
+			}
+		}
+
+		// New markets resubscribe straight away; failures back off first
+		if reason == "reconnected" {
+			var ok bool
+			if delay, ok = s.config.wait(ctx, delay); !ok {
+				return
+			}
+		}
+		var err error
+		conn, err = s.connect(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.config.report(fmt.Errorf("failed to resubscribe to pump.fun markets: %w", err))
+			}
+			continue
+		}
+		if !s.emit(ctx, MarketEvent{Type: MarketEventGap, Sequence: s.slot, Reason: reason}) {
+			conn.pubsub.Close()
+			return
+		}
+	}
+}
+
+// connect dials the node and subscribes to slots and to every curve.
+func (s *pumpStream) connect(ctx context.Context) (*pumpConn, error) {
+	if s.all {
+		s.follow()
+	}
+	pubsub, err := solana.DialPubSub(ctx, s.config.Dialer, s.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn := &pumpConn{pubsub: pubsub, curves: make(map[uint64]*PumpMarket)}
+	if conn.slots, err = pubsub.SlotSubscribe(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	for _, market := range s.markets {
+		id, err := pubsub.AccountSubscribe(ctx, market.Curve, s.config.Commitment)
+		if err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+		conn.curves[id] = market
+	}
+	return conn, nil
+}
+
+// follow takes every registered market, watching for more first so none
+// registered in between is missed.
+func (s *pumpStream) follow() {
+	s.added = s.pump.marketsAdded()
+	s.pump.mu.RLock()
+	defer s.pump.mu.RUnlock()
+
+	s.markets = s.markets[:0]
+	for _, market := range s.pump.markets {
+		s.markets = append(s.markets, market)
+	}
+	sort.Slice(s.markets, func(i, j int) bool { return s.markets[i].Symbol < s.markets[j].Symbol })
+}
+
+// serve forwards conn's notifications until it fails or goes quiet.
+func (s *pumpStream) serve(ctx context.Context, conn *pumpConn) error {
+	notifications := make(chan *solana.Notification)
+	failed := make(chan error, 1)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		conn.pubsub.Close()
+	}()
+	go func() {
+		for {
+			n, err := conn.pubsub.Recv()
+			if err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case notifications <- n:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Subscribed first so no change is missed between snapshot and stream
+	if !s.snapshot(ctx) {
+		return ctx.Err()
+	}
+
+	heartbeat := time.NewTicker(s.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+	heard := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-failed:
+			return err
+		case <-s.added:
+			return errMarketsAdded
+		case <-heartbeat.C:
+			if silent := time.Since(heard); silent > s.config.StaleTimeout {
+				return fmt.Errorf("no slot from node for %s", silent.Round(time.Second))
+			}
+			if !s.emit(ctx, MarketEvent{Type: MarketEventHeartbeat, Sequence: s.slot}) {
+				return ctx.Err()
+			}
+		case n := <-notifications:
+			if !s.handle(ctx, conn, n, &heard) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// handle processes one notification, returning false once ctx is done.
+func (s *pumpStream) handle(ctx context.Context, conn *pumpConn, n *solana.Notification, heard *time.Time) bool {
+	if n.Subscription == conn.slots && n.Method == "slotNotification" {
+		slot, err := n.Slot()
+		if err != nil {
+			s.config.report(err)
+			return true
+		}
+		*heard = time.Now()
+		last := s.slot
+		if slot.Slot > s.slot {
+			s.slot = slot.Slot
+		}
+		if last != 0 && slot.Parent > last {
+			reason := fmt.Sprintf("missed slots %d-%d", last+1, slot.Parent)
+			return s.emit(ctx, MarketEvent{Type: MarketEventGap, Sequence: s.slot, Reason: reason}) && s.snapshot(ctx)
+		}
+		return true
+	}
+
+	market, ok := conn.curves[n.Subscription]
+	if !ok || n.Method != "accountNotification" {
+		return true
+	}
+	slot, account, err := n.Account()
+	if err == nil {
+		var updated PumpMarket
+		if updated, err = s.pump.update(ctx, market, account); err == nil {
+			return s.emitPrice(ctx, updated, slot)
+		}
+	}
+	s.config.report(fmt.Errorf("%s: %w", market.Symbol, err))
+	return true
+}
+
+// snapshot reads every curve over RPC and emits its price.
+func (s *pumpStream) snapshot(ctx context.Context) bool {
+	for _, market := range s.markets {
+		updated, err := s.pump.refresh(ctx, market.Symbol)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			s.config.report(fmt.Errorf("%s: %w", market.Symbol, err))
+			continue
+		}
+		if !s.emitPrice(ctx, updated, s.slot) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *pumpStream) emitPrice(ctx context.Context, market PumpMarket, slot uint64) bool {
+	if market.State.Complete {
+		return true
+	}
+	return s.emit(ctx, MarketEvent{
+		Type:     MarketEventPrice,
+		Market:   MarketData{Symbol: market.Symbol, Price: market.State.Price()},
+		Sequence: slot,
+	})
+}
+
+func (s *pumpStream) emit(ctx context.Context, event MarketEvent) bool {
+	event.Time = s.pump.now()
+	select {
+	case s.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
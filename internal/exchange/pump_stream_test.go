@@ -0,0 +1,192 @@
+package exchange
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func notifySlot(t *testing.T, conn *nodeConn, slot, parent uint64) {
+	conn.notify(t, "slotNotification", 1, map[string]uint64{"slot": slot, "parent": parent, "root": parent - 32})
+}
+
+func notifyCurve(t *testing.T, conn *nodeConn, subscription, slot uint64, curve BondingCurve) {
+	conn.notify(t, "accountNotification", subscription, map[string]interface{}{
+		"context": map[string]uint64{"slot": slot},
+		"value": map[string]interface{}{
+			"lamports": 1461600,
+			"owner":    PumpFunProgramID,
+			"data":     []string{base64.StdEncoding.EncodeToString(encodeCurve(curve)), "base64"},
+		},
+	})
+}
+
+// nextEvent returns the next event other than a heartbeat.
+func nextEvent(t *testing.T, events <-chan MarketEvent) MarketEvent {
+	for {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "market stream closed")
+			if event.Type != MarketEventHeartbeat {
+				return event
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no market event received")
+		}
+	}
+}
+
+func newStreamingPump(t *testing.T, config StreamConfig) (*pumpChain, *PumpFun, chan *nodeConn, chan error) {
+	chain, node := newPumpChain(t)
+	chain.setCurve(solana.PublicKey{0x01, 0x70}, launchCurve)
+	chain.setCurve(solana.PublicKey{0x02, 0x70}, BondingCurve{VirtualTokenReserves: 279900000000000, VirtualSolReserves: 115005359057, TokenTotalSupply: 1e15, Complete: true})
+	endpoint, conns := pubsubNode(t, 3)
+	errs := make(chan error, 10)
+
+	pump := NewPumpFunWithClient(solana.NewClient(node.URL))
+	require.NoError(t, pump.AddToken("LIVE/SOL", solana.PublicKey{0x01, 0x70}))
+	require.NoError(t, pump.AddToken("DONE/SOL", solana.PublicKey{0x02, 0x70}))
+	config.Endpoint = endpoint
+	config.ReconnectDelay = 10 * time.Millisecond
+	config.OnError = func(err error) { errs <- err }
+	pump.SetStreamConfig(config)
+	return chain, pump, conns, errs
+}
+
+func TestPumpFun_Subscribe(t *testing.T) {
+	chain, pump, conns, errs := newStreamingPump(t, StreamConfig{HeartbeatInterval: 20 * time.Millisecond, StaleTimeout: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := pump.Subscribe(ctx, []string{"BONK/SOL"})
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+
+	events, err := pump.Subscribe(ctx, nil)
+	require.NoError(t, err)
+	conn := <-conns
+
+	// Slots first, then the curves in symbol order
+	require.Equal(t, []string{"slotSubscribe", "accountSubscribe", "accountSubscribe"}, conn.methods)
+	done, _ := BondingCurveAddress(solana.PublicKey{0x02, 0x70})
+	live, _ := BondingCurveAddress(solana.PublicKey{0x01, 0x70})
+	for i, curve := range []solana.PublicKey{done, live} {
+		var key solana.PublicKey
+		require.NoError(t, json.Unmarshal(conn.params[i+1][0], &key))
+		assert.Equal(t, curve, key)
+	}
+
+	// A snapshot of every live curve, skipping the completed one
+	event := nextEvent(t, events)
+	assert.Equal(t, MarketEventPrice, event.Type)
+	assert.Equal(t, MarketData{Symbol: "LIVE/SOL", Price: launchCurve.Price()}, event.Market)
+
+	bought := launchCurve
+	bought.VirtualTokenReserves -= 1e12
+	bought.VirtualSolReserves += 27985075
+	bought.RealTokenReserves -= 1e12
+	bought.RealSolReserves = 27985075
+	notifySlot(t, conn, 100, 99)
+	notifyCurve(t, conn, 3, 101, bought)
+	event = nextEvent(t, events)
+	assert.Equal(t, MarketEventPrice, event.Type)
+	assert.Equal(t, MarketData{Symbol: "LIVE/SOL", Price: bought.Price()}, event.Market)
+	assert.Equal(t, uint64(101), event.Sequence)
+	assert.Equal(t, bought, pump.Markets()[1].State)
+
+	// Heartbeats carry the last slot
+	for {
+		event := <-events
+		if event.Type == MarketEventHeartbeat {
+			assert.Equal(t, uint64(100), event.Sequence)
+			break
+		}
+	}
+
+	// A slot building past the last one seen is a gap, followed by a resync
+	chain.setCurve(solana.PublicKey{0x01, 0x70}, bought)
+	notifySlot(t, conn, 101, 100)
+	notifySlot(t, conn, 105, 103)
+	event = nextEvent(t, events)
+	assert.Equal(t, MarketEventGap, event.Type)
+	assert.Equal(t, "missed slots 102-103", event.Reason)
+	assert.Equal(t, uint64(105), event.Sequence)
+	event = nextEvent(t, events)
+	assert.Equal(t, MarketData{Symbol: "LIVE/SOL", Price: bought.Price()}, event.Market)
+
+	// Dropped connections are redialled and resynced
+	conn.Close()
+	<-conns
+	assert.Contains(t, (<-errs).Error(), "dropped")
+	event = nextEvent(t, events)
+	assert.Equal(t, MarketEventGap, event.Type)
+	assert.Equal(t, "reconnected", event.Reason)
+	event = nextEvent(t, events)
+	assert.Equal(t, MarketEventPrice, event.Type)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestPumpFun_SubscribeFollowsNewMarkets(t *testing.T) {
+	chain, node := newPumpChain(t)
+	chain.setCurve(solana.PublicKey{0x01, 0x70}, launchCurve)
+	endpoint, conns := pubsubNode(t, 2, 3)
+	pump := NewPumpFunWithClient(solana.NewClient(node.URL))
+	require.NoError(t, pump.AddToken("LIVE/SOL", solana.PublicKey{0x01, 0x70}))
+	pump.SetStreamConfig(StreamConfig{Endpoint: endpoint, HeartbeatInterval: time.Minute, StaleTimeout: time.Minute, ReconnectDelay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := pump.Subscribe(ctx, nil)
+	require.NoError(t, err)
+	<-conns
+	assert.Equal(t, "LIVE/SOL", nextEvent(t, events).Market.Symbol)
+
+	// A market registered later is subscribed to straight away, without
+	// waiting out the reconnect delay
+	traded := launchCurve
+	traded.VirtualSolReserves += 27985075
+	chain.setCurve(solana.PublicKey{0x03, 0x70}, traded)
+	require.NoError(t, pump.AddToken("NEW/SOL", solana.PublicKey{0x03, 0x70}))
+	conn := <-conns
+	require.Equal(t, []string{"slotSubscribe", "accountSubscribe", "accountSubscribe"}, conn.methods)
+	event := nextEvent(t, events)
+	assert.Equal(t, MarketEventGap, event.Type)
+	assert.Equal(t, "markets added", event.Reason)
+	assert.Equal(t, MarketData{Symbol: "LIVE/SOL", Price: launchCurve.Price()}, nextEvent(t, events).Market)
+	assert.Equal(t, MarketData{Symbol: "NEW/SOL", Price: traded.Price()}, nextEvent(t, events).Market)
+
+	notifyCurve(t, conn, 3, 200, launchCurve)
+	assert.Equal(t, MarketData{Symbol: "NEW/SOL", Price: launchCurve.Price()}, nextEvent(t, events).Market)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestPumpFun_SubscribeStale(t *testing.T) {
+	_, pump, conns, errs := newStreamingPump(t, StreamConfig{HeartbeatInterval: 10 * time.Millisecond, StaleTimeout: 30 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := pump.Subscribe(ctx, nil)
+	require.NoError(t, err)
+	<-conns
+
+	// The node never sends a slot, so the connection is given up on
+	assert.Contains(t, (<-errs).Error(), "no slot from node")
+	select {
+	case <-conns:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stale stream not redialled")
+	}
+	for event := nextEvent(t, events); event.Type != MarketEventGap; event = nextEvent(t, events) {
+	}
+}
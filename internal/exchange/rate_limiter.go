@@ -21,6 +21,28 @@ func NewRateLimitedClient(rps float64) *RateLimitedClient {
 	}
 }
 
+// NewRecordingClient is NewRateLimitedClient with every request and response
+// captured by the returned Recorder, for saving as a test fixture.
+func NewRecordingClient(rps float64) (*RateLimitedClient, *Recorder) {
+	client := NewRateLimitedClient(rps)
+	recorder := NewRecorder(client.client.Transport)
+	client.client.Transport = recorder
+	return client, recorder
+}
+
+// NewReplayClient serves responses from the cassette at path instead of the
+// network. Replays are not rate limited.
+func NewReplayClient(path string) (*RateLimitedClient, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitedClient{
+		limiter: rate.NewLimiter(rate.Inf, 1),
+		client:  &http.Client{Transport: NewReplayer(cassette)},
+	}, nil
+}
+
 func (c *RateLimitedClient) Do(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 	err := c.limiter.Wait(ctx)
@@ -39,7 +61,12 @@ func (c *RateLimitedClient) Get(url string) (*http.Response, error) {
 }
 
 func (c *RateLimitedClient) Post(url string, contentType string, body []byte) (*http.Response, error) {
-	ctx := context.Background()
+	return c.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext is Post with the request, and the wait for the rate limiter,
+// bounded by ctx.
+func (c *RateLimitedClient) PostContext(ctx context.Context, url string, contentType string, body []byte) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
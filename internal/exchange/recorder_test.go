@@ -0,0 +1,121 @@
+package exchange
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureClient replays the cassette at path. With RECORD_FIXTURES=1 it
+// talks to the live APIs instead and rewrites the cassette when the test
+// finishes.
+func fixtureClient(t *testing.T, path string) *RateLimitedClient {
+	t.Helper()
+	if os.Getenv("RECORD_FIXTURES") != "" {
+		client, recorder := NewRecordingClient(1.0)
+		t.Cleanup(func() {
+			if err := recorder.Save(path); err != nil {
+				t.Errorf("failed to save fixture %s: %v", path, err)
+			}
+		})
+		return client
+	}
+
+	client, err := NewReplayClient(path)
+	if err != nil {
+		t.Fatalf("failed to load fixture %s: %v", path, err)
+	}
+	return client
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/price":
+			if calls == 1 {
+				w.Write([]byte(`{"price":100}`))
+			} else {
+				w.Write([]byte(`{"price":101}`))
+			}
+		case "/echo":
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, recorder := NewRecordingClient(1000)
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/price", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"price":100}`, readBody(t, resp))
+	resp, err = client.Get(server.URL + "/price")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"price":101}`, readBody(t, resp))
+	resp, err = client.Post(server.URL+"/echo", "application/json", []byte(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"a":1}`, readBody(t, resp))
+
+	path := filepath.Join(t.TempDir(), "fixtures", "cassette.json")
+	assert.NoError(t, recorder.Save(path))
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret")
+
+	replay, err := NewReplayClient(path)
+	assert.NoError(t, err)
+	server.Close()
+
+	// Identical requests are served in recorded order, then the last repeats
+	for _, want := range []string{`{"price":100}`, `{"price":101}`, `{"price":101}`} {
+		resp, err := replay.Get(server.URL + "/price")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, want, readBody(t, resp))
+	}
+
+	resp, err = replay.Post(server.URL+"/echo", "application/json", []byte(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"a":1}`, readBody(t, resp))
+
+	_, err = replay.Post(server.URL+"/echo", "application/json", []byte(`{"a":2}`))
+	assert.ErrorIs(t, err, ErrNoRecording)
+	_, err = replay.Get(server.URL + "/missing")
+	assert.ErrorIs(t, err, ErrNoRecording)
+	assert.Equal(t, 3, calls)
+}
+
+func TestLoadCassette_Errors(t *testing.T) {
+	_, err := LoadCassette(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "bad.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = NewReplayClient(path)
+	assert.Error(t, err)
+}
@@ -3,11 +3,17 @@ package exchange
 import (
 	"errors"
 	"sync"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
 )
 
+// ErrExecutionUnsupported is returned by exchanges that quote markets but
+// cannot yet trade them on chain.
+var ErrExecutionUnsupported = errors.New("exchange cannot execute orders on chain yet")
+
 type SolanaDEX struct {
 	mu      sync.RWMutex
-	client  interface{}
+	client  *solana.Client
 	markets map[string]*Market
 	name    string
 }
@@ -46,11 +52,17 @@ type MarketData struct {
 
 func NewSolanaDEX(rpcURL string) *SolanaDEX {
 	return &SolanaDEX{
+		client:  solana.NewClient(rpcURL),
 		markets: make(map[string]*Market),
 		name:    "SolanaDEX",
 	}
 }
 
+// RPC is the client for the node at the DEX's RPC URL.
+func (dex *SolanaDEX) RPC() *solana.Client {
+	return dex.client
+}
+
 func (dex *SolanaDEX) AddMarket(symbol string, baseDecimals, quoteDecimals uint8) error {
 	dex.mu.Lock()
 	defer dex.mu.Unlock()
@@ -77,7 +89,7 @@ func (dex *SolanaDEX) UpdateOrderBook(symbol string, bids, asks []PriceLevel) er
 
 	market, exists := dex.markets[symbol]
 	if !exists {
-		return errors.New("market not found")
+		return ErrMarketNotFound
 	}
 
 	market.OrderBook.Bids = bids
@@ -91,7 +103,7 @@ func (dex *SolanaDEX) GetMarketPrice(symbol string) (float64, error) {
 	dex.mu.RUnlock()
 
 	if !exists {
-		return 0, errors.New("market not found")
+		return 0, ErrMarketNotFound
 	}
 
 	if len(market.OrderBook.Asks) == 0 {
@@ -101,15 +113,15 @@ func (dex *SolanaDEX) GetMarketPrice(symbol string) (float64, error) {
 	return market.OrderBook.Asks[0].Price, nil
 }
 
-func (dex *SolanaDEX) ExecuteOrder(order Order) error {
+// ExecuteOrder refuses every order with ErrExecutionUnsupported: nothing is
+// sent on chain yet, so there is no fill to report.
+func (dex *SolanaDEX) ExecuteOrder(order Order) (*ExecutionReport, error) {
 	dex.mu.RLock()
 	_, exists := dex.markets[order.Symbol]
 	dex.mu.RUnlock()
 
 	if !exists {
-		return errors.New("market not found")
+		return nil, ErrMarketNotFound
 	}
-
-	// In production, this would interact with Solana blockchain
-	return nil
+	return nil, ErrExecutionUnsupported
 }
@@ -1,12 +1,12 @@
 package exchange
 
 import (
-	"testing"
 	"github.com/stretchr/testify/assert"
+	"testing"
 )
 
 func TestSolanaDEX_AddMarket(t *testing.T) {
-	dex := NewSolanaDEX()
+	dex := NewSolanaDEX("http://127.0.0.1:0")
 
 	tests := []struct {
 		name          string
@@ -58,7 +58,7 @@ func TestSolanaDEX_AddMarket(t *testing.T) {
 }
 
 func TestSolanaDEX_UpdateOrderBook(t *testing.T) {
-	dex := NewSolanaDEX()
+	dex := NewSolanaDEX("http://127.0.0.1:0")
 	err := dex.AddMarket("SOL/USD", 9, 6)
 	assert.NoError(t, err)
 
@@ -90,10 +90,10 @@ func TestSolanaDEX_UpdateOrderBook(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:   "update with empty order book",
-			symbol: "SOL/USD",
-			bids:   []PriceLevel{},
-			asks:   []PriceLevel{},
+			name:    "update with empty order book",
+			symbol:  "SOL/USD",
+			bids:    []PriceLevel{},
+			asks:    []PriceLevel{},
 			wantErr: false,
 		},
 	}
@@ -114,7 +114,7 @@ func TestSolanaDEX_UpdateOrderBook(t *testing.T) {
 }
 
 func TestSolanaDEX_GetMarketPrice(t *testing.T) {
-	dex := NewSolanaDEX()
+	dex := NewSolanaDEX("http://127.0.0.1:0")
 	err := dex.AddMarket("SOL/USD", 9, 6)
 	assert.NoError(t, err)
 
@@ -138,8 +138,8 @@ func TestSolanaDEX_GetMarketPrice(t *testing.T) {
 			wantErr:   false,
 		},
 		{
-			name:      "get price from empty order book",
-			symbol:    "SOL/USD",
+			name:   "get price from empty order book",
+			symbol: "SOL/USD",
 			setupBook: func() {
 				dex.UpdateOrderBook("SOL/USD", []PriceLevel{}, []PriceLevel{})
 			},
@@ -168,3 +168,17 @@ func TestSolanaDEX_GetMarketPrice(t *testing.T) {
 		})
 	}
 }
+
+func TestSolanaDEX_ExecuteOrder(t *testing.T) {
+	dex := NewSolanaDEX("http://127.0.0.1:0")
+	assert.NoError(t, dex.AddMarket("SOL/USD", 9, 6))
+	assert.NoError(t, dex.UpdateOrderBook("SOL/USD", nil, []PriceLevel{{Price: 101.0, Size: 15.0, Orders: 6}}))
+
+	// Nothing is sent on chain, so no order reports a fill
+	report, err := dex.ExecuteOrder(Order{ID: "o1", Symbol: "SOL/USD", Side: "buy", Amount: 1, OrderType: "market"})
+	assert.ErrorIs(t, err, ErrExecutionUnsupported)
+	assert.Nil(t, report)
+
+	_, err = dex.ExecuteOrder(Order{ID: "o2", Symbol: "BTC/USD", Side: "buy", Amount: 1, OrderType: "market"})
+	assert.ErrorIs(t, err, ErrMarketNotFound)
+}
@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+const (
+	defaultHeartbeatInterval = 5 * time.Second
+	defaultStaleTimeout      = 30 * time.Second
+	defaultReconnectDelay    = time.Second
+	maxReconnectDelay        = 30 * time.Second
+)
+
+// ErrStreamingUnsupported is returned by wrappers such as PaperExchange when
+// the exchange they wrap cannot stream.
+var ErrStreamingUnsupported = errors.New("exchange does not stream market data")
+
+type MarketEventType string
+
+const (
+	// MarketEventPrice carries a new price in Market.
+	MarketEventPrice MarketEventType = "price"
+	// MarketEventHeartbeat says the stream is alive although nothing moved.
+	MarketEventHeartbeat MarketEventType = "heartbeat"
+	// MarketEventGap says updates may have been missed, after a reconnect or
+	// a jump in the source's sequence. The prices that follow are a fresh
+	// snapshot; anything built from earlier updates should be resynced.
+	MarketEventGap MarketEventType = "gap"
+)
+
+// MarketEvent is one message on a market data stream.
+type MarketEvent struct {
+	Type   MarketEventType
+	Market MarketData
+	// Sequence is the source's position when the event was produced, such
+	// as a Solana slot; zero when unknown.
+	Sequence uint64
+	// Reason explains a gap.
+	Reason string
+	Time   time.Time
+}
+
+// Streamer is implemented by exchanges that push market data rather than
+// waiting to be polled with GetMarketData. Subscribe streams events for
+// symbols, or for every market the exchange knows when symbols is empty,
+// until ctx is cancelled and the channel closes. Dropped connections are
+// redialled, each reported as a MarketEventGap.
+type Streamer interface {
+	Subscribe(ctx context.Context, symbols []string) (<-chan MarketEvent, error)
+}
+
+// StreamConfig says how an exchange's streams reach the node's PubSub
+// endpoint and when they give up on a connection.
+type StreamConfig struct {
+	// Endpoint defaults to the WebSocket URL of the RPC endpoint.
+	Endpoint string
+	// Dialer defaults to solana.WebSocketDialer.
+	Dialer     solana.StreamDialer
+	Commitment solana.Commitment
+	// HeartbeatInterval is how often market streams send a heartbeat;
+	// defaults to 5s.
+	HeartbeatInterval time.Duration
+	// StaleTimeout is how long a market stream may go without hearing from
+	// the node before it is redialled; defaults to 30s.
+	StaleTimeout time.Duration
+	// ReconnectDelay is the first wait after a stream drops, doubled on
+	// every failed attempt up to 30s.
+	ReconnectDelay time.Duration
+	// OnError, when set, is told about dropped streams and undecodable
+	// messages. Streams carry on regardless.
+	OnError func(error)
+}
+
+func (c StreamConfig) withDefaults(rpcEndpoint string) StreamConfig {
+	if c.Endpoint == "" {
+		c.Endpoint = solana.WebSocketURL(rpcEndpoint)
+	}
+	if c.Dialer == nil {
+		c.Dialer = solana.WebSocketDialer{}
+	}
+	if c.Commitment == "" {
+		c.Commitment = solana.CommitmentConfirmed
+	}
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if c.StaleTimeout <= 0 {
+		c.StaleTimeout = defaultStaleTimeout
+	}
+	if c.ReconnectDelay <= 0 {
+		c.ReconnectDelay = defaultReconnectDelay
+	}
+	return c
+}
+
+func (c StreamConfig) report(err error) {
+	if c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+// wait sleeps for delay unless ctx ends first, returning the next, doubled
+// delay, or false once ctx is done.
+func (c StreamConfig) wait(ctx context.Context, delay time.Duration) (time.Duration, bool) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return delay, false
+	case <-timer.C:
+	}
+	if delay *= 2; delay > maxReconnectDelay {
+		delay = maxReconnectDelay
+	}
+	return delay, true
+}
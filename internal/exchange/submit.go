@@ -0,0 +1,114 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/devinjacknz/devinsystem/internal/wallet"
+)
+
+const (
+	defaultConfirmInterval = 500 * time.Millisecond
+	defaultConfirmTimeout  = time.Minute
+)
+
+// ErrNoSubmitter is returned by exchanges asked to execute an order before
+// they were given a SwapSubmitter, rather than reporting a fill for a swap
+// that was never sent.
+var ErrNoSubmitter = errors.New("orders need a swap submitter to sign and send them")
+
+// SubmitError is returned by exchanges whose swap transaction was signed but
+// not confirmed. TxID identifies the transaction so it can be reconciled.
+type SubmitError struct {
+	TxID string
+	Err  error
+}
+
+func (e *SubmitError) Error() string {
+	return fmt.Sprintf("swap %s: %v", e.TxID, e.Err)
+}
+
+func (e *SubmitError) Unwrap() error {
+	return e.Err
+}
+
+// Pending reports whether the swap may still have been executed: it was
+// not seen failing on chain, so it may have landed without confirming in
+// time.
+func (e *SubmitError) Pending() bool {
+	var txErr *solana.TransactionError
+	return !errors.As(e.Err, &txErr)
+}
+
+// submitError wraps an error from SwapSubmitter.Submit in a *SubmitError
+// when the transaction was signed, and so may have been sent.
+func submitError(sig solana.Signature, err error) error {
+	if sig == (solana.Signature{}) {
+		return err
+	}
+	return &SubmitError{TxID: sig.String(), Err: err}
+}
+
+type SubmitConfig struct {
+	// Commitment the swap must reach before it counts as executed;
+	// defaults to confirmed.
+	Commitment    solana.Commitment
+	PollInterval  time.Duration
+	Timeout       time.Duration
+	SkipPreflight bool
+}
+
+// SwapSubmitter signs serialized swap transactions with a wallet signer,
+// broadcasts them and waits for confirmation.
+type SwapSubmitter struct {
+	rpc    *solana.Client
+	signer wallet.Signer
+	config SubmitConfig
+}
+
+func NewSwapSubmitter(rpc *solana.Client, signer wallet.Signer, config SubmitConfig) *SwapSubmitter {
+	if config.Commitment == "" {
+		config.Commitment = solana.CommitmentConfirmed
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultConfirmInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultConfirmTimeout
+	}
+
+	return &SwapSubmitter{
+		rpc:    rpc,
+		signer: signer,
+		config: config,
+	}
+}
+
+// PublicKey is the account swaps are built for and signed by.
+func (s *SwapSubmitter) PublicKey() solana.PublicKey {
+	return s.signer.PublicKey()
+}
+
+// Submit signs tx, sends it and waits until it reaches the configured
+// commitment. The returned signature identifies the transaction even when
+// confirmation fails or times out.
+func (s *SwapSubmitter) Submit(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
+	if err := wallet.SignTransaction(ctx, s.signer, tx); err != nil {
+		return solana.Signature{}, fmt.Errorf("failed to sign swap transaction: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	sig, err := s.rpc.SendAndConfirm(ctx, tx, solana.SendOptions{
+		SkipPreflight:       s.config.SkipPreflight,
+		PreflightCommitment: s.config.Commitment,
+	}, s.config.Commitment, s.config.PollInterval)
+	if err != nil {
+		return sig, fmt.Errorf("failed to submit swap transaction: %w", err)
+	}
+	return sig, nil
+}
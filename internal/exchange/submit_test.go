@@ -0,0 +1,172 @@
+package exchange
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/devinjacknz/devinsystem/internal/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureWallet = "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU"
+
+func newTestSubmitter(t *testing.T, rpcURL string, key ed25519.PrivateKey) *SwapSubmitter {
+	keyStore, err := wallet.NewHSMKeyStore()
+	require.NoError(t, err)
+	require.NoError(t, keyStore.Store("trading", key))
+
+	signer, err := wallet.NewLocalSigner(keyStore, "trading")
+	require.NoError(t, err)
+	return NewSwapSubmitter(solana.NewClient(rpcURL), signer, SubmitConfig{PollInterval: 1})
+}
+
+// unsignedSwap is a minimal transaction paying from payer with an empty
+// signature slot, as Jupiter returns it.
+func unsignedSwap(t *testing.T, payer ed25519.PublicKey) *solana.Transaction {
+	var key solana.PublicKey
+	copy(key[:], payer)
+	msg, err := solana.NewV0Message(key, []solana.Instruction{{
+		ProgramID: solana.SystemProgramID,
+		Accounts:  []solana.AccountMeta{{PublicKey: key, IsSigner: true, IsWritable: true}},
+	}}, solana.Hash{}, nil)
+	require.NoError(t, err)
+	return solana.NewTransaction(msg)
+}
+
+// newSwapRPC answers sendTransaction after checking the transaction carries
+// a valid signature from payer, and reports every signature as confirmed.
+func newSwapRPC(t *testing.T, payer ed25519.PublicKey) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+		switch req.Method {
+		case "sendTransaction":
+			var encoded string
+			require.NoError(t, json.Unmarshal(req.Params[0], &encoded))
+			tx, err := solana.ParseTransactionBase64(encoded)
+			require.NoError(t, err)
+			assert.True(t, ed25519.Verify(payer, tx.Message, tx.Signatures[0][:]))
+			result = tx.Signatures[0].String()
+		case "getSignatureStatuses":
+			result = map[string]interface{}{
+				"context": map[string]int{"slot": 1},
+				"value":   []map[string]interface{}{{"slot": 1, "confirmations": 1, "err": nil, "confirmationStatus": "confirmed"}},
+			}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSwapSubmitter_Submit(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	server := newSwapRPC(t, key.Public().(ed25519.PublicKey))
+	submitter := newTestSubmitter(t, server.URL, key)
+
+	sig, err := submitter.Submit(context.Background(), unsignedSwap(t, key.Public().(ed25519.PublicKey)))
+	assert.NoError(t, err)
+	assert.False(t, sig.IsZero())
+
+	// Not a signer of the transaction
+	other := ed25519.NewKeyFromSeed(append(make([]byte, 31), 1))
+	_, err = submitter.Submit(context.Background(), unsignedSwap(t, other.Public().(ed25519.PublicKey)))
+	assert.ErrorIs(t, err, solana.ErrSignerNotFound)
+}
+
+// newSubmittingJupiterDEX replays the Jupiter cassette with every swap
+// re-pointed at a fresh submitter's key and answered with a transaction it
+// can sign, confirmed by a stub node.
+func newSubmittingJupiterDEX(t *testing.T) (*JupiterDEX, ed25519.PublicKey, *solana.Transaction) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	public := key.Public().(ed25519.PublicKey)
+	server := newSwapRPC(t, public)
+	submitter := newTestSubmitter(t, server.URL, key)
+
+	cassette, err := LoadCassette("testdata/jupiter.json")
+	require.NoError(t, err)
+	swap := unsignedSwap(t, public)
+	for i, interaction := range cassette.Interactions {
+		if strings.HasSuffix(interaction.Request.URL, SwapEndpoint) {
+			interaction.Request.Body = strings.Replace(interaction.Request.Body, fixtureWallet, submitter.PublicKey().String(), 1)
+			body, err := json.Marshal(JupiterSwapResponse{SwapTransaction: swap.Base64()})
+			require.NoError(t, err)
+			interaction.Response.Body = string(body)
+			cassette.Interactions[i] = interaction
+		}
+	}
+	path := filepath.Join(t.TempDir(), "jupiter.json")
+	require.NoError(t, cassette.Save(path))
+
+	client, err := NewReplayClient(path)
+	require.NoError(t, err)
+	dex := NewJupiterDEXWithClient(client)
+	dex.SetSubmitter(submitter)
+	return dex, public, swap
+}
+
+func TestJupiterDEX_ExecuteOrder_Submits(t *testing.T) {
+	dex, public, swap := newSubmittingJupiterDEX(t)
+
+	report, err := dex.ExecuteOrder(Order{ID: "o1", Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market"})
+	require.NoError(t, err)
+	assert.InDelta(t, 187.231, report.AvgPrice, 1e-12)
+
+	sig, err := solana.SignatureFromBase58(report.TxID)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(public, swap.Message, sig[:]))
+
+	// Nothing is reported as filled unless it was sent
+	_, err = newFixtureJupiterDEX(t).ExecuteOrder(Order{ID: "o2", Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market"})
+	assert.ErrorIs(t, err, ErrNoSubmitter)
+}
+
+func TestSubmitError(t *testing.T) {
+	// Unsigned transactions were never sent
+	assert.Equal(t, assert.AnError, submitError(solana.Signature{}, assert.AnError))
+
+	sig := solana.Signature{1}
+	err := submitError(sig, fmt.Errorf("%w: %s", solana.ErrConfirmationTimeout, sig))
+	var submitErr *SubmitError
+	require.ErrorAs(t, err, &submitErr)
+	assert.Equal(t, sig.String(), submitErr.TxID)
+	assert.ErrorIs(t, err, solana.ErrConfirmationTimeout)
+	assert.True(t, submitErr.Pending())
+
+	err = submitError(sig, &solana.TransactionError{Signature: sig, Err: "InstructionError"})
+	require.ErrorAs(t, err, &submitErr)
+	assert.False(t, submitErr.Pending())
+}
+
+func TestJupiterSwapResponse_Transaction(t *testing.T) {
+	swap := unsignedSwap(t, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey))
+	tx, err := JupiterSwapResponse{SwapTransaction: swap.Base64()}.Transaction()
+	assert.NoError(t, err)
+	assert.Equal(t, swap, tx)
+
+	msg, err := tx.DecodeMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, solana.MessageVersion0, msg.Version)
+
+	_, err = JupiterSwapResponse{Message: "route not found"}.Transaction()
+	assert.ErrorContains(t, err, "route not found")
+	_, err = JupiterSwapResponse{SwapTransaction: "AQ=="}.Transaction()
+	assert.ErrorIs(t, err, solana.ErrMalformedMessage)
+}
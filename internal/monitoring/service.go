@@ -33,6 +33,14 @@ func (s *Service) LogTrade(symbol string, side string, amount float64, price flo
 	log.Printf("[TRADE] %s %s %.8f @ %.8f", symbol, side, amount, price)
 }
 
+func (s *Service) LogOrder(orderID string, symbol string, status string, reason string) {
+	if reason == "" {
+		log.Printf("[ORDER] %s %s %s", orderID, symbol, status)
+		return
+	}
+	log.Printf("[ORDER] %s %s %s: %s", orderID, symbol, status, reason)
+}
+
 func (s *Service) LogVolatility(symbol string, volatility float64) {
 	log.Printf("[VOLATILITY] %s %.2f%%", symbol, volatility*100)
 }
@@ -41,6 +49,10 @@ func (s *Service) LogAISignal(symbol string, signal string, confidence float64)
 	log.Printf("[AI] %s %s %.2f", symbol, signal, confidence)
 }
 
+func (s *Service) LogIntent(strategy string, symbol string, side string, amount float64, reason string) {
+	log.Printf("[STRATEGY] %s %s %s %.8f (%s)", strategy, symbol, side, amount, reason)
+}
+
 func (s *Service) LogExposure(symbol string, exposure float64) {
 	log.Printf("[EXPOSURE] %s %.2f", symbol, exposure)
 }
@@ -0,0 +1,285 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sizeTolerance treats float residue after closing a position as flat.
+const sizeTolerance = 1e-9
+
+type Fill struct {
+	Wallet   string
+	Symbol   string
+	Exchange string
+	Side     string
+	Amount   float64
+	Price    float64
+	Fee      float64
+	Time     time.Time
+}
+
+// Position is the net holding of one symbol in one wallet. Size is signed:
+// positive for long, negative for short. PnL figures are in the quote asset.
+type Position struct {
+	Wallet        string    `json:"wallet"`
+	Symbol        string    `json:"symbol"`
+	Exchange      string    `json:"exchange"`
+	Size          float64   `json:"size"`
+	EntryPrice    float64   `json:"entryPrice"`
+	CurrentPrice  float64   `json:"currentPrice"`
+	RealizedPnL   float64   `json:"realizedPnl"`
+	UnrealizedPnL float64   `json:"unrealizedPnl"`
+	Fees          float64   `json:"fees"`
+	UpdatedAt     time.Time `json:"timestamp"`
+}
+
+func (p Position) PnL() float64 {
+	return p.RealizedPnL + p.UnrealizedPnL
+}
+
+func (p Position) IsFlat() bool {
+	return math.Abs(p.Size) <= sizeTolerance
+}
+
+// ExposureUpdater receives the absolute net size of a symbol across all
+// wallets whenever it changes. risk.RiskManager satisfies it.
+type ExposureUpdater interface {
+	UpdateExposure(symbol string, amount float64) error
+}
+
+type positionKey struct {
+	wallet string
+	symbol string
+}
+
+type Tracker struct {
+	mu          sync.RWMutex
+	positions   map[positionKey]*Position
+	exposure    ExposureUpdater
+	subscribers map[int]chan Position
+	nextSubID   int
+}
+
+func NewTracker(exposure ExposureUpdater) *Tracker {
+	return &Tracker{
+		positions:   make(map[positionKey]*Position),
+		exposure:    exposure,
+		subscribers: make(map[int]chan Position),
+	}
+}
+
+// ApplyFill folds a fill into the wallet's position using average-cost
+// accounting and returns the updated position.
+func (t *Tracker) ApplyFill(fill Fill) (Position, error) {
+	if fill.Amount <= 0 || fill.Price <= 0 {
+		return Position{}, fmt.Errorf("invalid fill for %s: amount %f price %f", fill.Symbol, fill.Amount, fill.Price)
+	}
+
+	var signed float64
+	switch fill.Side {
+	case "buy":
+		signed = fill.Amount
+	case "sell":
+		signed = -fill.Amount
+	default:
+		return Position{}, fmt.Errorf("invalid side: %s", fill.Side)
+	}
+	if fill.Time.IsZero() {
+		fill.Time = time.Now()
+	}
+
+	t.mu.Lock()
+	key := positionKey{wallet: fill.Wallet, symbol: fill.Symbol}
+	pos, exists := t.positions[key]
+	if !exists {
+		pos = &Position{Wallet: fill.Wallet, Symbol: fill.Symbol}
+		t.positions[key] = pos
+	}
+
+	if pos.IsFlat() || sameSign(pos.Size, signed) {
+		notional := pos.EntryPrice*math.Abs(pos.Size) + fill.Price*fill.Amount
+		pos.Size += signed
+		pos.EntryPrice = notional / math.Abs(pos.Size)
+	} else {
+		closed := math.Min(fill.Amount, math.Abs(pos.Size))
+		direction := 1.0
+		if pos.Size < 0 {
+			direction = -1.0
+		}
+		pos.RealizedPnL += (fill.Price - pos.EntryPrice) * closed * direction
+		pos.Size += signed
+		if pos.IsFlat() {
+			pos.Size = 0
+			pos.EntryPrice = 0
+		} else if !sameSign(pos.Size, direction) {
+			// The fill flipped the position; the remainder opens at the fill price.
+			pos.EntryPrice = fill.Price
+		}
+	}
+
+	pos.RealizedPnL -= fill.Fee
+	pos.Fees += fill.Fee
+	pos.Exchange = fill.Exchange
+	pos.CurrentPrice = fill.Price
+	pos.UnrealizedPnL = unrealized(*pos)
+	pos.UpdatedAt = fill.Time
+	updated := *pos
+	exposure := t.netSize(fill.Symbol)
+	t.mu.Unlock()
+
+	t.publish(updated)
+
+	if t.exposure != nil {
+		if err := t.exposure.UpdateExposure(fill.Symbol, exposure); err != nil {
+			return updated, fmt.Errorf("failed to update exposure: %w", err)
+		}
+	}
+	return updated, nil
+}
+
+// Mark revalues every position in symbol at price.
+func (t *Tracker) Mark(symbol string, price float64) error {
+	return t.MarkAt(symbol, price, time.Now())
+}
+
+// MarkAt is Mark with an explicit valuation time, for replays.
+func (t *Tracker) MarkAt(symbol string, price float64, at time.Time) error {
+	if price <= 0 {
+		return errors.New("invalid mark price")
+	}
+
+	t.mu.Lock()
+	var marked []Position
+	for key, pos := range t.positions {
+		if key.symbol != symbol {
+			continue
+		}
+		pos.CurrentPrice = price
+		pos.UnrealizedPnL = unrealized(*pos)
+		pos.UpdatedAt = at
+		marked = append(marked, *pos)
+	}
+	t.mu.Unlock()
+
+	for _, pos := range marked {
+		t.publish(pos)
+	}
+	return nil
+}
+
+func (t *Tracker) Position(wallet, symbol string) (Position, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	pos, exists := t.positions[positionKey{wallet: wallet, symbol: symbol}]
+	if !exists {
+		return Position{}, false
+	}
+	return *pos, true
+}
+
+// Positions returns every tracked position ordered by wallet and symbol.
+func (t *Tracker) Positions() []Position {
+	t.mu.RLock()
+	positions := make([]Position, 0, len(t.positions))
+	for _, pos := range t.positions {
+		positions = append(positions, *pos)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Wallet == positions[j].Wallet {
+			return positions[i].Symbol < positions[j].Symbol
+		}
+		return positions[i].Wallet < positions[j].Wallet
+	})
+	return positions
+}
+
+// RealizedPnL sums realized PnL across the symbols held by wallet that are
+// quoted in quote, e.g. "SOL/USDC" for "USDC". PnL in other quote assets is
+// a different unit and left out, as are symbols that do not name one.
+func (t *Tracker) RealizedPnL(wallet, quote string) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var total float64
+	for key, pos := range t.positions {
+		if key.wallet == wallet && strings.EqualFold(quoteAsset(key.symbol), quote) {
+			total += pos.RealizedPnL
+		}
+	}
+	return total
+}
+
+// quoteAsset returns the QUOTE of a "BASE/QUOTE" symbol, or "" for symbols
+// without one.
+func quoteAsset(symbol string) string {
+	if i := strings.LastIndex(symbol, "/"); i >= 0 {
+		return symbol[i+1:]
+	}
+	return ""
+}
+
+// Subscribe streams every position update until cancel is called. Updates
+// are dropped for subscribers that fall more than buffer updates behind.
+func (t *Tracker) Subscribe(buffer int) (<-chan Position, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.nextSubID
+	t.nextSubID++
+	ch := make(chan Position, buffer)
+	t.subscribers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.subscribers, id)
+			t.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+func (t *Tracker) publish(pos Position) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, ch := range t.subscribers {
+		select {
+		case ch <- pos:
+		default:
+		}
+	}
+}
+
+// netSize must be called with t.mu held.
+func (t *Tracker) netSize(symbol string) float64 {
+	var size float64
+	for key, pos := range t.positions {
+		if key.symbol == symbol {
+			size += pos.Size
+		}
+	}
+	return math.Abs(size)
+}
+
+func unrealized(pos Position) float64 {
+	if pos.IsFlat() {
+		return 0
+	}
+	return (pos.CurrentPrice - pos.EntryPrice) * pos.Size
+}
+
+func sameSign(a, b float64) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
+}
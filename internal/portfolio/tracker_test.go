@@ -0,0 +1,145 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExposureUpdater struct {
+	mock.Mock
+}
+
+func (m *MockExposureUpdater) UpdateExposure(symbol string, amount float64) error {
+	args := m.Called(symbol, amount)
+	return args.Error(0)
+}
+
+func TestTracker_ApplyFill(t *testing.T) {
+	tracker := NewTracker(nil)
+
+	tests := []struct {
+		name         string
+		fill         Fill
+		wantSize     float64
+		wantEntry    float64
+		wantRealized float64
+	}{
+		{
+			name:      "open long",
+			fill:      Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 10, Price: 100},
+			wantSize:  10,
+			wantEntry: 100,
+		},
+		{
+			name:      "add to long averages entry",
+			fill:      Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 10, Price: 110},
+			wantSize:  20,
+			wantEntry: 105,
+		},
+		{
+			name:         "partial close realizes pnl",
+			fill:         Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "sell", Amount: 5, Price: 115, Fee: 1},
+			wantSize:     15,
+			wantEntry:    105,
+			wantRealized: 49,
+		},
+		{
+			name:         "sell through zero flips to short",
+			fill:         Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "sell", Amount: 20, Price: 100},
+			wantSize:     -5,
+			wantEntry:    100,
+			wantRealized: -26,
+		},
+		{
+			name:         "cover short",
+			fill:         Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 5, Price: 90},
+			wantSize:     0,
+			wantEntry:    0,
+			wantRealized: 24,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, err := tracker.ApplyFill(tt.fill)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.wantSize, pos.Size, 1e-9)
+			assert.InDelta(t, tt.wantEntry, pos.EntryPrice, 1e-9)
+			assert.InDelta(t, tt.wantRealized, pos.RealizedPnL, 1e-9)
+		})
+	}
+
+	_, err := tracker.ApplyFill(Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "hold", Amount: 1, Price: 1})
+	assert.Error(t, err)
+	_, err = tracker.ApplyFill(Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 0, Price: 1})
+	assert.Error(t, err)
+}
+
+func TestTracker_MarkAndAggregate(t *testing.T) {
+	exposure := new(MockExposureUpdater)
+	exposure.On("UpdateExposure", "SOL/USDC", 10.0).Return(nil).Once()
+	exposure.On("UpdateExposure", "SOL/USDC", 6.0).Return(nil).Once()
+	tracker := NewTracker(exposure)
+
+	updates, cancel := tracker.Subscribe(10)
+	defer cancel()
+
+	_, err := tracker.ApplyFill(Fill{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 10, Price: 100})
+	assert.NoError(t, err)
+	_, err = tracker.ApplyFill(Fill{Wallet: "B", Symbol: "SOL/USDC", Side: "sell", Amount: 4, Price: 100})
+	assert.NoError(t, err)
+	exposure.AssertExpectations(t)
+
+	assert.NoError(t, tracker.Mark("SOL/USDC", 105))
+	assert.Error(t, tracker.Mark("SOL/USDC", 0))
+
+	posA, ok := tracker.Position("A", "SOL/USDC")
+	assert.True(t, ok)
+	assert.Equal(t, 105.0, posA.CurrentPrice)
+	assert.InDelta(t, 50, posA.UnrealizedPnL, 1e-9)
+	assert.InDelta(t, 50, posA.PnL(), 1e-9)
+
+	posB, ok := tracker.Position("B", "SOL/USDC")
+	assert.True(t, ok)
+	assert.InDelta(t, -20, posB.UnrealizedPnL, 1e-9)
+
+	_, ok = tracker.Position("A", "BONK/USDC")
+	assert.False(t, ok)
+
+	positions := tracker.Positions()
+	assert.Len(t, positions, 2)
+	assert.Equal(t, "A", positions[0].Wallet)
+	assert.Equal(t, "B", positions[1].Wallet)
+
+	assert.Len(t, updates, 4)
+}
+
+func TestTracker_RealizedPnL(t *testing.T) {
+	tracker := NewTracker(nil)
+
+	fills := []Fill{
+		{Wallet: "A", Symbol: "SOL/USDC", Side: "buy", Amount: 1, Price: 100},
+		{Wallet: "A", Symbol: "SOL/USDC", Side: "sell", Amount: 1, Price: 120},
+		{Wallet: "A", Symbol: "BONK/USDC", Side: "buy", Amount: 1000, Price: 0.01},
+		{Wallet: "A", Symbol: "BONK/USDC", Side: "sell", Amount: 1000, Price: 0.015},
+		{Wallet: "B", Symbol: "SOL/USDC", Side: "buy", Amount: 1, Price: 100},
+		{Wallet: "B", Symbol: "SOL/USDC", Side: "sell", Amount: 1, Price: 90},
+		{Wallet: "A", Symbol: "PUMP/SOL", Side: "buy", Amount: 1000, Price: 0.001},
+		{Wallet: "A", Symbol: "PUMP/SOL", Side: "sell", Amount: 1000, Price: 0.003},
+		{Wallet: "A", Symbol: "WIF", Side: "buy", Amount: 1, Price: 2},
+		{Wallet: "A", Symbol: "WIF", Side: "sell", Amount: 1, Price: 3},
+	}
+	for _, fill := range fills {
+		_, err := tracker.ApplyFill(fill)
+		assert.NoError(t, err)
+	}
+
+	// Each quote asset is counted on its own
+	assert.InDelta(t, 25, tracker.RealizedPnL("A", "USDC"), 1e-9)
+	assert.InDelta(t, 2, tracker.RealizedPnL("A", "sol"), 1e-9)
+	assert.InDelta(t, -10, tracker.RealizedPnL("B", "USDC"), 1e-9)
+	assert.Equal(t, 0.0, tracker.RealizedPnL("B", "SOL"))
+	assert.Equal(t, 0.0, tracker.RealizedPnL("C", "USDC"))
+}
@@ -0,0 +1,175 @@
+package solana
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+func mustPublicKey(s string) PublicKey {
+	key, err := PublicKeyFromBase58(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// GetBalance returns the lamports held by account.
+func (c *Client) GetBalance(ctx context.Context, account PublicKey, commitment Commitment) (uint64, error) {
+	var result struct {
+		Value uint64 `json:"value"`
+	}
+	if err := c.call(ctx, "getBalance", commitmentParams(commitment, account.String()), &result); err != nil {
+		return 0, err
+	}
+	return result.Value, nil
+}
+
+type AccountInfo struct {
+	Lamports   uint64
+	Owner      PublicKey
+	Data       []byte
+	Executable bool
+	RentEpoch  uint64
+}
+
+type accountJSON struct {
+	Lamports   uint64          `json:"lamports"`
+	Owner      PublicKey       `json:"owner"`
+	Data       json.RawMessage `json:"data"`
+	Executable bool            `json:"executable"`
+	RentEpoch  uint64          `json:"rentEpoch"`
+}
+
+// GetAccountInfo returns the account's raw data. Accounts that do not exist
+// return ErrAccountNotFound.
+func (c *Client) GetAccountInfo(ctx context.Context, account PublicKey, commitment Commitment) (*AccountInfo, error) {
+	config := map[string]interface{}{"encoding": "base64"}
+	if commitment != "" {
+		config["commitment"] = commitment
+	}
+
+	var result struct {
+		Value *accountJSON `json:"value"`
+	}
+	if err := c.call(ctx, "getAccountInfo", []interface{}{account.String(), config}, &result); err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, account)
+	}
+	info, err := result.Value.decode()
+	if err != nil {
+		return nil, fmt.Errorf("getAccountInfo: %s: %w", account, err)
+	}
+	return info, nil
+}
+
+// decode unpacks base64 account data, which comes as [payload, encoding].
+func (a *accountJSON) decode() (*AccountInfo, error) {
+	var data []string
+	if err := json.Unmarshal(a.Data, &data); err != nil || len(data) != 2 || data[1] != "base64" {
+		return nil, errors.New("unexpected data encoding")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data: %w", err)
+	}
+
+	return &AccountInfo{
+		Lamports:   a.Lamports,
+		Owner:      a.Owner,
+		Data:       decoded,
+		Executable: a.Executable,
+		RentEpoch:  a.RentEpoch,
+	}, nil
+}
+
+// TokenAccountsFilter selects token accounts by mint or by token program.
+// Exactly one of the two must be set.
+type TokenAccountsFilter struct {
+	Mint      *PublicKey
+	ProgramID *PublicKey
+}
+
+// TokenAccount is an SPL token account and its balance in base units.
+type TokenAccount struct {
+	Address  PublicKey
+	Mint     PublicKey
+	Owner    PublicKey
+	Amount   uint64
+	Decimals uint8
+}
+
+// UIAmount is the balance in whole tokens.
+func (a TokenAccount) UIAmount() float64 {
+	amount := float64(a.Amount)
+	for i := uint8(0); i < a.Decimals; i++ {
+		amount /= 10
+	}
+	return amount
+}
+
+type tokenAccountJSON struct {
+	Pubkey  PublicKey `json:"pubkey"`
+	Account struct {
+		Data struct {
+			Parsed struct {
+				Info struct {
+					Mint        PublicKey `json:"mint"`
+					Owner       PublicKey `json:"owner"`
+					TokenAmount struct {
+						Amount   string `json:"amount"`
+						Decimals uint8  `json:"decimals"`
+					} `json:"tokenAmount"`
+				} `json:"info"`
+			} `json:"parsed"`
+		} `json:"data"`
+	} `json:"account"`
+}
+
+// GetTokenAccountsByOwner lists the SPL token accounts owner holds that
+// match filter.
+func (c *Client) GetTokenAccountsByOwner(ctx context.Context, owner PublicKey, filter TokenAccountsFilter, commitment Commitment) ([]TokenAccount, error) {
+	var selector map[string]string
+	switch {
+	case filter.Mint != nil && filter.ProgramID == nil:
+		selector = map[string]string{"mint": filter.Mint.String()}
+	case filter.ProgramID != nil && filter.Mint == nil:
+		selector = map[string]string{"programId": filter.ProgramID.String()}
+	default:
+		return nil, errors.New("getTokenAccountsByOwner: filter needs exactly one of mint or program id")
+	}
+	config := map[string]interface{}{"encoding": "jsonParsed"}
+	if commitment != "" {
+		config["commitment"] = commitment
+	}
+
+	var result struct {
+		Value []tokenAccountJSON `json:"value"`
+	}
+	params := []interface{}{owner.String(), selector, config}
+	if err := c.call(ctx, "getTokenAccountsByOwner", params, &result); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]TokenAccount, 0, len(result.Value))
+	for _, raw := range result.Value {
+		info := raw.Account.Data.Parsed.Info
+		amount, err := strconv.ParseUint(info.TokenAmount.Amount, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("getTokenAccountsByOwner: invalid amount for %s: %w", raw.Pubkey, err)
+		}
+		accounts = append(accounts, TokenAccount{
+			Address:  raw.Pubkey,
+			Mint:     info.Mint,
+			Owner:    info.Owner,
+			Amount:   amount,
+			Decimals: info.TokenAmount.Decimals,
+		})
+	}
+	return accounts, nil
+}
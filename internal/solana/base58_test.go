@@ -0,0 +1,59 @@
+package solana
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		hex     string
+		encoded string
+	}{
+		{hex: "00", encoded: "1"},
+		{hex: "0000", encoded: "11"},
+		{hex: "61", encoded: "2g"},
+		{hex: "626262", encoded: "a3gV"},
+		{hex: "636363", encoded: "aPEr"},
+		{hex: "73696d706c792061206c6f6e6720737472696e67", encoded: "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{hex: "00eb15231dfceb60925886b67d065299925915aeb172c06647", encoded: "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{hex: "516b6fcd0f", encoded: "ABnLTmg"},
+		{hex: "572e4794", encoded: "3EFU7m"},
+		{hex: "10c8511e", encoded: "Rt5zm"},
+		{hex: "00000000000000000000", encoded: "1111111111"},
+		// System program and wrapped SOL mint
+		{hex: "0000000000000000000000000000000000000000000000000000000000000000", encoded: "11111111111111111111111111111111"},
+		{hex: "069b8857feab8184fb687f634618c035dac439dc1aeb3b5598a0f00000000001", encoded: "So11111111111111111111111111111111111111112"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoded, func(t *testing.T) {
+			raw, err := hex.DecodeString(tt.hex)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.encoded, EncodeBase58(raw))
+
+			decoded, err := DecodeBase58(tt.encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, raw, decoded)
+		})
+	}
+
+	for _, bad := range []string{"", "0", "O", "I", "l", "abc!"} {
+		_, err := DecodeBase58(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestPublicKeyFromBase58(t *testing.T) {
+	key, err := PublicKeyFromBase58("So11111111111111111111111111111111111111112")
+	assert.NoError(t, err)
+	assert.Equal(t, "So11111111111111111111111111111111111111112", key.String())
+	assert.False(t, key.IsZero())
+
+	_, err = PublicKeyFromBase58("2g")
+	assert.Error(t, err)
+	_, err = SignatureFromBase58(key.String())
+	assert.Error(t, err)
+}
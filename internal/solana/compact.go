@@ -0,0 +1,45 @@
+package solana
+
+import "errors"
+
+var ErrInvalidCompactU16 = errors.New("invalid compact-u16")
+
+// AppendCompactU16 appends n in Solana's compact-u16 ("shortvec") encoding:
+// seven bits per byte, least significant first, high bit set while more
+// bytes follow.
+func AppendCompactU16(b []byte, n uint16) []byte {
+	v := uint32(n)
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+// DecodeCompactU16 reads a compact-u16 from the start of b and returns it
+// with the number of bytes consumed. Non-canonical encodings are rejected as
+// the runtime does.
+func DecodeCompactU16(b []byte) (uint16, int, error) {
+	var v uint32
+	for i := 0; i < 3; i++ {
+		if i >= len(b) {
+			return 0, 0, ErrInvalidCompactU16
+		}
+		c := b[i]
+		// An extra byte that adds nothing is an alias of a shorter encoding
+		if i > 0 && c == 0 {
+			return 0, 0, ErrInvalidCompactU16
+		}
+		v |= uint32(c&0x7f) << (7 * i)
+		if c&0x80 == 0 {
+			if v > 0xffff {
+				return 0, 0, ErrInvalidCompactU16
+			}
+			return uint16(v), i + 1, nil
+		}
+	}
+	return 0, 0, ErrInvalidCompactU16
+}
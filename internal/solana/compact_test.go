@@ -0,0 +1,43 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactU16(t *testing.T) {
+	tests := []struct {
+		value   uint16
+		encoded []byte
+	}{
+		{value: 0x0, encoded: []byte{0x00}},
+		{value: 0x7f, encoded: []byte{0x7f}},
+		{value: 0x80, encoded: []byte{0x80, 0x01}},
+		{value: 0xff, encoded: []byte{0xff, 0x01}},
+		{value: 0x100, encoded: []byte{0x80, 0x02}},
+		{value: 0x7fff, encoded: []byte{0xff, 0xff, 0x01}},
+		{value: 0xffff, encoded: []byte{0xff, 0xff, 0x03}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.encoded, AppendCompactU16(nil, tt.value))
+
+		value, n, err := DecodeCompactU16(append(tt.encoded, 0xaa))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.value, value)
+		assert.Equal(t, len(tt.encoded), n)
+	}
+
+	for _, bad := range [][]byte{
+		nil,
+		{0x80},
+		{0x80, 0x00},             // alias of 0
+		{0xff, 0x80, 0x00},       // alias of 0x7f
+		{0xff, 0xff, 0x04},       // overflows u16
+		{0x80, 0x80, 0x80, 0x01}, // too long
+	} {
+		_, _, err := DecodeCompactU16(bad)
+		assert.ErrorIs(t, err, ErrInvalidCompactU16, "%x", bad)
+	}
+}
@@ -0,0 +1,78 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrConfirmationTimeout = errors.New("transaction not confirmed")
+
+// TransactionError reports a transaction that landed but failed on chain.
+type TransactionError struct {
+	Signature Signature
+	Err       string
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction %s failed: %s", e.Signature, e.Err)
+}
+
+// ConfirmTransaction polls the status of sig every interval until it
+// reaches commitment, fails on chain, or ctx ends. Expiry of ctx returns
+// ErrConfirmationTimeout wrapping the context error.
+func (c *Client) ConfirmTransaction(ctx context.Context, sig Signature, commitment Commitment, interval time.Duration) (*SignatureStatus, error) {
+	if commitment.rank() == 0 {
+		return nil, fmt.Errorf("invalid commitment: %q", commitment)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		statuses, err := c.GetSignatureStatuses(ctx, []Signature{sig}, false)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if err == nil {
+			if status := statuses[0]; status != nil {
+				if status.Failed() {
+					return status, &TransactionError{Signature: sig, Err: string(status.Err)}
+				}
+				if status.Reached(commitment) {
+					return status, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s: %v", ErrConfirmationTimeout, sig, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendAndConfirm sends a fully signed tx and waits for it to reach
+// commitment. The returned signature identifies the transaction even when
+// confirmation fails or times out.
+func (c *Client) SendAndConfirm(ctx context.Context, tx *Transaction, opts SendOptions, commitment Commitment, interval time.Duration) (Signature, error) {
+	if len(tx.Signatures) == 0 {
+		return Signature{}, fmt.Errorf("%w: unsigned transaction", ErrMalformedMessage)
+	}
+	sig := tx.Signatures[0]
+
+	sent, err := c.SendTransaction(ctx, tx, opts)
+	if err != nil {
+		return sig, err
+	}
+	if sent != sig {
+		return sig, fmt.Errorf("node returned signature %s for transaction %s", sent, sig)
+	}
+
+	if _, err := c.ConfirmTransaction(ctx, sig, commitment, interval); err != nil {
+		return sig, err
+	}
+	return sig, nil
+}
@@ -0,0 +1,67 @@
+package solana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrAccountNotFound = errors.New("account not found")
+
+// JSON-RPC error codes returned by Solana nodes.
+const (
+	CodeInvalidParams            = -32602
+	CodeInternalError            = -32603
+	CodeTransactionPreflight     = -32002
+	CodeBlockNotAvailable        = -32004
+	CodeNodeUnhealthy            = -32005
+	CodeSignatureVerification    = -32003
+	CodeMinContextSlotNotReached = -32016
+)
+
+// RPCError is an error object returned by the node.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Temporary reports whether the same request may succeed once the node
+// catches up.
+func (e *RPCError) Temporary() bool {
+	switch e.Code {
+	case CodeBlockNotAvailable, CodeNodeUnhealthy, CodeMinContextSlotNotReached:
+		return true
+	default:
+		return false
+	}
+}
+
+// HTTPError is a non-200 response from the RPC endpoint.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the endpoint is throttling or briefly
+// unavailable.
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package solana
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AccountMeta is an account an instruction reads or writes.
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Instruction is a program call before it is compiled into a message.
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// NewLegacyMessage compiles instructions into a legacy message paid for by
+// payer.
+func NewLegacyMessage(payer PublicKey, instructions []Instruction, blockhash Hash) (*Message, error) {
+	return compileMessage(MessageVersionLegacy, payer, instructions, blockhash, nil)
+}
+
+// NewV0Message compiles instructions into a v0 message. Accounts that
+// neither sign nor are invoked as programs are loaded from the first of
+// tables that holds them.
+func NewV0Message(payer PublicKey, instructions []Instruction, blockhash Hash, tables []AddressLookupTable) (*Message, error) {
+	return compileMessage(MessageVersion0, payer, instructions, blockhash, tables)
+}
+
+type compiledAccount struct {
+	AccountMeta
+	program bool
+}
+
+// group is the position of an account class in the key ordering the header
+// describes.
+func (a *compiledAccount) group() int {
+	switch {
+	case a.IsSigner && a.IsWritable:
+		return 0
+	case a.IsSigner:
+		return 1
+	case a.IsWritable:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func compileMessage(version MessageVersion, payer PublicKey, instructions []Instruction, blockhash Hash, tables []AddressLookupTable) (*Message, error) {
+	if len(instructions) == 0 {
+		return nil, errors.New("message needs at least one instruction")
+	}
+
+	// Merge every reference to an account, keeping first-seen order
+	accounts := []*compiledAccount{{AccountMeta: AccountMeta{PublicKey: payer, IsSigner: true, IsWritable: true}}}
+	byKey := map[PublicKey]*compiledAccount{payer: accounts[0]}
+	add := func(meta AccountMeta, program bool) {
+		if account, ok := byKey[meta.PublicKey]; ok {
+			account.IsSigner = account.IsSigner || meta.IsSigner
+			account.IsWritable = account.IsWritable || meta.IsWritable
+			account.program = account.program || program
+			return
+		}
+		account := &compiledAccount{AccountMeta: meta, program: program}
+		accounts = append(accounts, account)
+		byKey[meta.PublicKey] = account
+	}
+	for _, ix := range instructions {
+		for _, meta := range ix.Accounts {
+			add(meta, false)
+		}
+		add(AccountMeta{PublicKey: ix.ProgramID}, true)
+	}
+	for _, account := range accounts {
+		if account.program && account.PublicKey == payer {
+			return nil, errors.New("fee payer cannot be invoked as a program")
+		}
+	}
+
+	// Pull eligible accounts out into lookups, one table at a time
+	msg := &Message{Version: version, RecentBlockhash: blockhash}
+	lookedUp := make(map[PublicKey]bool)
+	var loadedWritable, loadedReadonly []PublicKey
+	for _, table := range tables {
+		lookup := MessageAddressTableLookup{AccountKey: table.Key}
+		var writable, readonly []PublicKey
+		for idx, address := range table.Addresses {
+			account, ok := byKey[address]
+			if !ok || account.IsSigner || account.program || idx > 255 {
+				continue
+			}
+			if lookedUp[address] {
+				continue
+			}
+			lookedUp[address] = true
+			if account.IsWritable {
+				lookup.WritableIndexes = append(lookup.WritableIndexes, uint8(idx))
+				writable = append(writable, address)
+			} else {
+				lookup.ReadonlyIndexes = append(lookup.ReadonlyIndexes, uint8(idx))
+				readonly = append(readonly, address)
+			}
+		}
+		if len(writable)+len(readonly) > 0 {
+			msg.AddressTableLookups = append(msg.AddressTableLookups, lookup)
+			loadedWritable = append(loadedWritable, writable...)
+			loadedReadonly = append(loadedReadonly, readonly...)
+		}
+	}
+
+	var static []*compiledAccount
+	for _, account := range accounts {
+		if !lookedUp[account.PublicKey] {
+			static = append(static, account)
+		}
+	}
+	sort.SliceStable(static, func(i, j int) bool {
+		return static[i].group() < static[j].group()
+	})
+
+	index := make(map[PublicKey]int, len(accounts))
+	for i, account := range static {
+		index[account.PublicKey] = i
+		msg.AccountKeys = append(msg.AccountKeys, account.PublicKey)
+		switch account.group() {
+		case 0:
+			msg.Header.NumRequiredSignatures++
+		case 1:
+			msg.Header.NumRequiredSignatures++
+			msg.Header.NumReadonlySignedAccounts++
+		case 3:
+			msg.Header.NumReadonlyUnsignedAccounts++
+		}
+	}
+	for _, address := range append(loadedWritable, loadedReadonly...) {
+		index[address] = len(index)
+	}
+	if len(index) > 256 {
+		return nil, fmt.Errorf("message references %d accounts, at most 256 fit", len(index))
+	}
+
+	for _, ix := range instructions {
+		compiled := CompiledInstruction{
+			ProgramIDIndex: uint8(index[ix.ProgramID]),
+			Data:           append([]byte(nil), ix.Data...),
+		}
+		for _, meta := range ix.Accounts {
+			compiled.Accounts = append(compiled.Accounts, uint8(index[meta.PublicKey]))
+		}
+		msg.Instructions = append(msg.Instructions, compiled)
+	}
+	return msg, nil
+}
@@ -0,0 +1,63 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+var AddressLookupTableProgramID = mustPublicKey("AddressLookupTab1e1111111111111111111111111")
+
+// lookupTableMetaSize is the fixed header in front of a lookup table's
+// addresses: type, deactivation slot, last extended slot and its start
+// index, optional authority and padding.
+const lookupTableMetaSize = 56
+
+// AddressLookupTable is an on-chain list of addresses v0 messages can refer
+// to by index.
+type AddressLookupTable struct {
+	Key       PublicKey
+	Authority *PublicKey
+	// DeactivationSlot is math.MaxUint64 while the table is active.
+	DeactivationSlot uint64
+	Addresses        []PublicKey
+}
+
+// ParseAddressLookupTable decodes the account data of the table at key.
+func ParseAddressLookupTable(key PublicKey, data []byte) (*AddressLookupTable, error) {
+	if len(data) < lookupTableMetaSize || (len(data)-lookupTableMetaSize)%PublicKeySize != 0 {
+		return nil, fmt.Errorf("invalid address lookup table %s: %d bytes", key, len(data))
+	}
+	if kind := binary.LittleEndian.Uint32(data); kind != 1 {
+		return nil, fmt.Errorf("invalid address lookup table %s: account type %d", key, kind)
+	}
+
+	table := &AddressLookupTable{
+		Key:              key,
+		DeactivationSlot: binary.LittleEndian.Uint64(data[4:]),
+	}
+	if data[21] == 1 {
+		var authority PublicKey
+		copy(authority[:], data[22:])
+		table.Authority = &authority
+	}
+
+	addresses := data[lookupTableMetaSize:]
+	table.Addresses = make([]PublicKey, len(addresses)/PublicKeySize)
+	for i := range table.Addresses {
+		copy(table.Addresses[i][:], addresses[i*PublicKeySize:])
+	}
+	return table, nil
+}
+
+// GetAddressLookupTable loads and decodes the lookup table at key.
+func (c *Client) GetAddressLookupTable(ctx context.Context, key PublicKey, commitment Commitment) (*AddressLookupTable, error) {
+	account, err := c.GetAccountInfo(ctx, key, commitment)
+	if err != nil {
+		return nil, err
+	}
+	if account.Owner != AddressLookupTableProgramID {
+		return nil, fmt.Errorf("account %s is not an address lookup table", key)
+	}
+	return ParseAddressLookupTable(key, account.Data)
+}
@@ -0,0 +1,310 @@
+package solana
+
+import (
+	"fmt"
+)
+
+// MessageVersion is the format of a message. Legacy messages carry no
+// version prefix.
+type MessageVersion int
+
+const (
+	MessageVersionLegacy MessageVersion = -1
+	MessageVersion0      MessageVersion = 0
+)
+
+// MessageHeader says which account keys sign and which are read-only. Keys
+// are ordered writable signers, read-only signers, writable non-signers,
+// read-only non-signers.
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+// CompiledInstruction refers to its program and accounts by index into the
+// message's account keys, followed by any keys loaded from lookup tables.
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// MessageAddressTableLookup loads extra accounts for a v0 message from an
+// on-chain address lookup table.
+type MessageAddressTableLookup struct {
+	AccountKey      PublicKey
+	WritableIndexes []uint8
+	ReadonlyIndexes []uint8
+}
+
+// Message is the signed part of a transaction.
+type Message struct {
+	Version             MessageVersion
+	Header              MessageHeader
+	AccountKeys         []PublicKey
+	RecentBlockhash     Hash
+	Instructions        []CompiledInstruction
+	AddressTableLookups []MessageAddressTableLookup
+}
+
+// ParseMessage decodes a serialized legacy or v0 message. The whole input
+// must be consumed.
+func ParseMessage(b []byte) (*Message, error) {
+	r := &reader{b: b}
+	msg := &Message{Version: MessageVersionLegacy}
+
+	if len(b) > 0 && b[0]&versionPrefix != 0 {
+		version := b[0] &^ versionPrefix
+		if version != 0 {
+			return nil, fmt.Errorf("%w: v%d", ErrUnsupportedFormat, version)
+		}
+		msg.Version = MessageVersion0
+		r.pos++
+	}
+
+	header := r.bytes(3)
+	if r.err != nil {
+		return nil, r.fail("header")
+	}
+	msg.Header = MessageHeader{header[0], header[1], header[2]}
+
+	count := r.compact()
+	msg.AccountKeys = make([]PublicKey, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		var key PublicKey
+		copy(key[:], r.bytes(PublicKeySize))
+		msg.AccountKeys = append(msg.AccountKeys, key)
+	}
+	copy(msg.RecentBlockhash[:], r.bytes(len(msg.RecentBlockhash)))
+	if r.err != nil {
+		return nil, r.fail("account keys")
+	}
+
+	count = r.compact()
+	msg.Instructions = make([]CompiledInstruction, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		var ix CompiledInstruction
+		if program := r.bytes(1); r.err == nil {
+			ix.ProgramIDIndex = program[0]
+		}
+		ix.Accounts = r.bytes(r.compact())
+		ix.Data = r.bytes(r.compact())
+		msg.Instructions = append(msg.Instructions, ix)
+	}
+	if r.err != nil {
+		return nil, r.fail("instructions")
+	}
+
+	if msg.Version == MessageVersion0 {
+		count = r.compact()
+		for i := 0; i < count && r.err == nil; i++ {
+			var lookup MessageAddressTableLookup
+			copy(lookup.AccountKey[:], r.bytes(PublicKeySize))
+			lookup.WritableIndexes = r.bytes(r.compact())
+			lookup.ReadonlyIndexes = r.bytes(r.compact())
+			msg.AddressTableLookups = append(msg.AddressTableLookups, lookup)
+		}
+		if r.err != nil {
+			return nil, r.fail("address table lookups")
+		}
+	}
+
+	if r.pos != len(b) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrMalformedMessage, len(b)-r.pos)
+	}
+	if err := msg.sanitize(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// sanitize applies the runtime's structural checks on the header and
+// account indexes.
+func (m *Message) sanitize() error {
+	h := m.Header
+	if h.NumRequiredSignatures == 0 || h.NumReadonlySignedAccounts >= h.NumRequiredSignatures {
+		return fmt.Errorf("%w: header needs a writable fee payer", ErrMalformedMessage)
+	}
+	if int(h.NumRequiredSignatures)+int(h.NumReadonlyUnsignedAccounts) > len(m.AccountKeys) {
+		return fmt.Errorf("%w: header counts exceed %d account keys", ErrMalformedMessage, len(m.AccountKeys))
+	}
+	if m.Version == MessageVersionLegacy && len(m.AddressTableLookups) > 0 {
+		return fmt.Errorf("%w: legacy message with address table lookups", ErrMalformedMessage)
+	}
+
+	total := m.NumAccounts()
+	if total > 256 {
+		return fmt.Errorf("%w: %d accounts", ErrMalformedMessage, total)
+	}
+	for i, ix := range m.Instructions {
+		// Programs must be static keys so they can be loaded up front
+		if int(ix.ProgramIDIndex) >= len(m.AccountKeys) || ix.ProgramIDIndex == 0 {
+			return fmt.Errorf("%w: instruction %d has invalid program index %d", ErrMalformedMessage, i, ix.ProgramIDIndex)
+		}
+		for _, account := range ix.Accounts {
+			if int(account) >= total {
+				return fmt.Errorf("%w: instruction %d references account %d of %d", ErrMalformedMessage, i, account, total)
+			}
+		}
+	}
+	return nil
+}
+
+// Serialize encodes the message in the form it is signed.
+func (m *Message) Serialize() []byte {
+	var b []byte
+	if m.Version != MessageVersionLegacy {
+		b = append(b, versionPrefix|byte(m.Version))
+	}
+	b = append(b, m.Header.NumRequiredSignatures, m.Header.NumReadonlySignedAccounts, m.Header.NumReadonlyUnsignedAccounts)
+
+	b = AppendCompactU16(b, uint16(len(m.AccountKeys)))
+	for _, key := range m.AccountKeys {
+		b = append(b, key[:]...)
+	}
+	b = append(b, m.RecentBlockhash[:]...)
+
+	b = AppendCompactU16(b, uint16(len(m.Instructions)))
+	for _, ix := range m.Instructions {
+		b = append(b, ix.ProgramIDIndex)
+		b = appendShortVec(b, ix.Accounts)
+		b = appendShortVec(b, ix.Data)
+	}
+
+	if m.Version != MessageVersionLegacy {
+		b = AppendCompactU16(b, uint16(len(m.AddressTableLookups)))
+		for _, lookup := range m.AddressTableLookups {
+			b = append(b, lookup.AccountKey[:]...)
+			b = appendShortVec(b, lookup.WritableIndexes)
+			b = appendShortVec(b, lookup.ReadonlyIndexes)
+		}
+	}
+	return b
+}
+
+// Signers returns the accounts whose signatures the message requires, in
+// signature order.
+func (m *Message) Signers() []PublicKey {
+	return append([]PublicKey(nil), m.AccountKeys[:m.Header.NumRequiredSignatures]...)
+}
+
+// NumAccounts counts the static account keys plus those loaded from lookup
+// tables.
+func (m *Message) NumAccounts() int {
+	n := len(m.AccountKeys)
+	for _, lookup := range m.AddressTableLookups {
+		n += len(lookup.WritableIndexes) + len(lookup.ReadonlyIndexes)
+	}
+	return n
+}
+
+// IsSigner reports whether the account at index signs the message.
+func (m *Message) IsSigner(index int) bool {
+	return index < int(m.Header.NumRequiredSignatures)
+}
+
+// IsWritable reports whether the account at index may be written. Indexes
+// past the static keys refer to loaded writable accounts, then loaded
+// read-only ones.
+func (m *Message) IsWritable(index int) bool {
+	h := m.Header
+	static := len(m.AccountKeys)
+	switch {
+	case index < int(h.NumRequiredSignatures):
+		return index < int(h.NumRequiredSignatures-h.NumReadonlySignedAccounts)
+	case index < static:
+		return index < static-int(h.NumReadonlyUnsignedAccounts)
+	}
+
+	index -= static
+	for _, lookup := range m.AddressTableLookups {
+		if index < len(lookup.WritableIndexes) {
+			return true
+		}
+		index -= len(lookup.WritableIndexes)
+	}
+	return false
+}
+
+// ResolveAccountKeys returns every account the message uses, in index
+// order, loading lookup-table accounts from tables.
+func (m *Message) ResolveAccountKeys(tables []AddressLookupTable) ([]PublicKey, error) {
+	byKey := make(map[PublicKey]AddressLookupTable, len(tables))
+	for _, table := range tables {
+		byKey[table.Key] = table
+	}
+
+	keys := append([]PublicKey(nil), m.AccountKeys...)
+	var readonly []PublicKey
+	for _, lookup := range m.AddressTableLookups {
+		table, ok := byKey[lookup.AccountKey]
+		if !ok {
+			return nil, fmt.Errorf("address lookup table %s not provided", lookup.AccountKey)
+		}
+		load := func(indexes []uint8) ([]PublicKey, error) {
+			loaded := make([]PublicKey, len(indexes))
+			for i, idx := range indexes {
+				if int(idx) >= len(table.Addresses) {
+					return nil, fmt.Errorf("address lookup table %s has no index %d", table.Key, idx)
+				}
+				loaded[i] = table.Addresses[idx]
+			}
+			return loaded, nil
+		}
+		writable, err := load(lookup.WritableIndexes)
+		if err != nil {
+			return nil, err
+		}
+		ro, err := load(lookup.ReadonlyIndexes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, writable...)
+		readonly = append(readonly, ro...)
+	}
+	return append(keys, readonly...), nil
+}
+
+func appendShortVec(b, data []byte) []byte {
+	b = AppendCompactU16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+// reader walks a serialized message, remembering the first error so decoding
+// can check once per section.
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.b)-r.pos {
+		r.err = fmt.Errorf("need %d bytes at offset %d, have %d", n, r.pos, len(r.b)-r.pos)
+		return nil
+	}
+	out := append([]byte(nil), r.b[r.pos:r.pos+n]...)
+	r.pos += n
+	return out
+}
+
+func (r *reader) compact() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n, err := DecodeCompactU16(r.b[r.pos:])
+	if err != nil {
+		r.err = fmt.Errorf("offset %d: %v", r.pos, err)
+		return 0
+	}
+	r.pos += n
+	return int(v)
+}
+
+func (r *reader) fail(section string) error {
+	return fmt.Errorf("%w: %s: %v", ErrMalformedMessage, section, r.err)
+}
@@ -0,0 +1,274 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Golden vectors in testdata/golden.json were serialized independently of
+// this package, byte by byte from the wire format.
+var (
+	goldenPayer     = mustPublicKey("7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU")
+	goldenDest      = mustPublicKey("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	goldenUSDC      = mustPublicKey("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	goldenJupiter   = mustPublicKey("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+	goldenTable     = mustPublicKey("4sKLJ1Qoudh8PJyqBeuKocYdsZvxTcRShUt9aKqwhgvC")
+	goldenPool      = mustPublicKey("Hp53XEtt4S8SvPCXarsLSdGfZBuUr5mMmZmX2DRNXQKp")
+	goldenVault     = mustPublicKey("5zpyutJu9ee6jFymDGoK7F6S5Kczqtc9FomP3ueKuyA9")
+	goldenBlockhash = Hash(mustPublicKey("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"))
+)
+
+type goldenVector struct {
+	Message string `json:"message"`
+	Base64  string `json:"base64"`
+	Base58  string `json:"base58"`
+}
+
+func loadGolden(t *testing.T) map[string]goldenVector {
+	data, err := os.ReadFile("testdata/golden.json")
+	require.NoError(t, err)
+	var vectors map[string]goldenVector
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	return vectors
+}
+
+func decodeBase64(t *testing.T, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func transferData(lamports uint64) []byte {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, 2)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	return data
+}
+
+func swapData() []byte {
+	data := make([]byte, 26)
+	copy(data, []byte{0xe5, 0x17, 0xcb, 0x97, 0x7a, 0xe3, 0xad, 0x2a})
+	binary.LittleEndian.PutUint64(data[8:], 1000000000)
+	binary.LittleEndian.PutUint64(data[16:], 187231000)
+	binary.LittleEndian.PutUint16(data[24:], 50)
+	return data
+}
+
+func TestMessage_Golden(t *testing.T) {
+	golden := loadGolden(t)
+	lookupTables := []AddressLookupTable{{Key: goldenTable, Addresses: []PublicKey{goldenDest, goldenVault, goldenPool}}}
+
+	tests := []struct {
+		name         string
+		want         *Message
+		compile      func() (*Message, error)
+		wantKeys     []PublicKey
+		wantWritable []bool
+	}{
+		{
+			name: "legacy_transfer",
+			want: &Message{
+				Version:         MessageVersionLegacy,
+				Header:          MessageHeader{1, 0, 1},
+				AccountKeys:     []PublicKey{goldenPayer, goldenDest, SystemProgramID},
+				RecentBlockhash: goldenBlockhash,
+				Instructions:    []CompiledInstruction{{ProgramIDIndex: 2, Accounts: []uint8{0, 1}, Data: transferData(1000000)}},
+			},
+			compile: func() (*Message, error) {
+				return NewLegacyMessage(goldenPayer, []Instruction{{
+					ProgramID: SystemProgramID,
+					Accounts: []AccountMeta{
+						{PublicKey: goldenPayer, IsSigner: true, IsWritable: true},
+						{PublicKey: goldenDest, IsWritable: true},
+					},
+					Data: transferData(1000000),
+				}}, goldenBlockhash)
+			},
+			wantKeys:     []PublicKey{goldenPayer, goldenDest, SystemProgramID},
+			wantWritable: []bool{true, true, false},
+		},
+		{
+			name: "v0_lookup",
+			want: &Message{
+				Version:         MessageVersion0,
+				Header:          MessageHeader{1, 0, 2},
+				AccountKeys:     []PublicKey{goldenPayer, goldenUSDC, goldenJupiter},
+				RecentBlockhash: goldenBlockhash,
+				Instructions:    []CompiledInstruction{{ProgramIDIndex: 2, Accounts: []uint8{0, 3, 1, 4}, Data: swapData()}},
+				AddressTableLookups: []MessageAddressTableLookup{
+					{AccountKey: goldenTable, WritableIndexes: []uint8{2}, ReadonlyIndexes: []uint8{1}},
+				},
+			},
+			compile: func() (*Message, error) {
+				return NewV0Message(goldenPayer, []Instruction{{
+					ProgramID: goldenJupiter,
+					Accounts: []AccountMeta{
+						{PublicKey: goldenPayer, IsSigner: true, IsWritable: true},
+						{PublicKey: goldenPool, IsWritable: true},
+						{PublicKey: goldenUSDC},
+						{PublicKey: goldenVault},
+					},
+					Data: swapData(),
+				}}, goldenBlockhash, lookupTables)
+			},
+			wantKeys:     []PublicKey{goldenPayer, goldenUSDC, goldenJupiter, goldenPool, goldenVault},
+			wantWritable: []bool{true, false, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := decodeBase64(t, golden[tt.name].Message)
+
+			msg, err := ParseMessage(raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, msg)
+			assert.Equal(t, raw, msg.Serialize())
+
+			compiled, err := tt.compile()
+			require.NoError(t, err)
+			assert.Equal(t, raw, compiled.Serialize())
+
+			keys, err := msg.ResolveAccountKeys(lookupTables)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKeys, keys)
+			for i, writable := range tt.wantWritable {
+				assert.Equal(t, writable, msg.IsWritable(i), "account %d", i)
+				assert.Equal(t, i == 0, msg.IsSigner(i), "account %d", i)
+			}
+		})
+	}
+}
+
+func TestTransaction_Golden(t *testing.T) {
+	vector := loadGolden(t)["partially_signed"]
+	raw := decodeBase64(t, vector.Base64)
+
+	fromBase64, err := ParseTransactionBase64(vector.Base64)
+	require.NoError(t, err)
+	fromBase58, err := ParseTransactionBase58(vector.Base58)
+	require.NoError(t, err)
+	assert.Equal(t, fromBase64, fromBase58)
+	assert.Equal(t, raw, fromBase64.Serialize())
+	assert.Equal(t, vector.Base58, fromBase64.Base58())
+	assert.Equal(t, decodeBase64(t, vector.Message), fromBase64.Message)
+
+	var first Signature
+	for i := range first {
+		first[i] = 1
+	}
+	assert.Equal(t, []Signature{first, {}}, fromBase64.Signatures)
+
+	signers, err := fromBase64.Signers()
+	assert.NoError(t, err)
+	assert.Equal(t, []PublicKey{goldenPayer, goldenDest}, signers)
+
+	// The same message compiled from instructions gets empty slots
+	msg, err := NewLegacyMessage(goldenPayer, []Instruction{{
+		ProgramID: SystemProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: goldenDest, IsSigner: true, IsWritable: true},
+			{PublicKey: goldenPayer, IsWritable: true},
+		},
+		Data: transferData(1000000),
+	}}, goldenBlockhash)
+	require.NoError(t, err)
+	tx := NewTransaction(msg)
+	assert.Equal(t, fromBase64.Message, tx.Message)
+	assert.Equal(t, []Signature{{}, {}}, tx.Signatures)
+}
+
+func TestParseMessage_Errors(t *testing.T) {
+	golden := loadGolden(t)
+	legacy := decodeBase64(t, golden["legacy_transfer"].Message)
+	v0 := decodeBase64(t, golden["v0_lookup"].Message)
+
+	mutate := func(b []byte, f func([]byte)) []byte {
+		b = append([]byte(nil), b...)
+		f(b)
+		return b
+	}
+	// Offsets into legacy_transfer: header, key count, 3 keys, blockhash,
+	// instruction count, then the instruction
+	ixOffset := 3 + 1 + 3*PublicKeySize + 32 + 1
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		wantErr error
+	}{
+		{name: "empty", raw: nil, wantErr: ErrMalformedMessage},
+		{name: "trailing bytes", raw: append(append([]byte(nil), legacy...), 0), wantErr: ErrMalformedMessage},
+		{name: "truncated data", raw: legacy[:len(legacy)-1], wantErr: ErrMalformedMessage},
+		{name: "truncated lookups", raw: v0[:len(v0)-2], wantErr: ErrMalformedMessage},
+		{name: "no signers", raw: mutate(legacy, func(b []byte) { b[0] = 0 }), wantErr: ErrMalformedMessage},
+		{name: "read-only payer", raw: mutate(legacy, func(b []byte) { b[1] = 1 }), wantErr: ErrMalformedMessage},
+		{name: "header exceeds keys", raw: mutate(legacy, func(b []byte) { b[2] = 3 }), wantErr: ErrMalformedMessage},
+		{name: "payer as program", raw: mutate(legacy, func(b []byte) { b[ixOffset] = 0 }), wantErr: ErrMalformedMessage},
+		{name: "program out of range", raw: mutate(legacy, func(b []byte) { b[ixOffset] = 3 }), wantErr: ErrMalformedMessage},
+		{name: "account out of range", raw: mutate(legacy, func(b []byte) { b[ixOffset+2] = 3 }), wantErr: ErrMalformedMessage},
+		{name: "v1", raw: mutate(v0, func(b []byte) { b[0] = versionPrefix | 1 }), wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMessage(tt.raw)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCompileMessage_Errors(t *testing.T) {
+	_, err := NewLegacyMessage(goldenPayer, nil, goldenBlockhash)
+	assert.Error(t, err)
+
+	_, err = NewLegacyMessage(goldenPayer, []Instruction{{ProgramID: goldenPayer}}, goldenBlockhash)
+	assert.Error(t, err)
+
+	// Signers and programs stay static even when a table holds them
+	msg, err := NewV0Message(goldenPayer, []Instruction{{
+		ProgramID: goldenJupiter,
+		Accounts:  []AccountMeta{{PublicKey: goldenDest, IsSigner: true}},
+	}}, goldenBlockhash, []AddressLookupTable{{Key: goldenTable, Addresses: []PublicKey{goldenDest, goldenJupiter}}})
+	require.NoError(t, err)
+	assert.Empty(t, msg.AddressTableLookups)
+	assert.Equal(t, MessageHeader{2, 1, 1}, msg.Header)
+
+	_, err = msg.ResolveAccountKeys(nil)
+	assert.NoError(t, err)
+	msg.AddressTableLookups = []MessageAddressTableLookup{{AccountKey: goldenTable, ReadonlyIndexes: []uint8{9}}}
+	_, err = msg.ResolveAccountKeys(nil)
+	assert.Error(t, err)
+	_, err = msg.ResolveAccountKeys([]AddressLookupTable{{Key: goldenTable}})
+	assert.Error(t, err)
+}
+
+func TestParseAddressLookupTable(t *testing.T) {
+	data := make([]byte, lookupTableMetaSize)
+	binary.LittleEndian.PutUint32(data, 1)
+	binary.LittleEndian.PutUint64(data[4:], math.MaxUint64)
+	data[21] = 1
+	copy(data[22:], goldenPayer[:])
+	data = append(data, goldenPool[:]...)
+	data = append(data, goldenVault[:]...)
+
+	table, err := ParseAddressLookupTable(goldenTable, data)
+	require.NoError(t, err)
+	assert.Equal(t, goldenTable, table.Key)
+	assert.Equal(t, &goldenPayer, table.Authority)
+	assert.Equal(t, uint64(math.MaxUint64), table.DeactivationSlot)
+	assert.Equal(t, []PublicKey{goldenPool, goldenVault}, table.Addresses)
+
+	_, err = ParseAddressLookupTable(goldenTable, data[:len(data)-1])
+	assert.Error(t, err)
+	data[0] = 0
+	_, err = ParseAddressLookupTable(goldenTable, data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const (
+	maxSeeds      = 16
+	maxSeedLength = 32
+)
+
+var ErrInvalidSeeds = errors.New("invalid seeds for program address")
+
+// CreateProgramAddress derives the address program controls for seeds. The
+// result must not be a valid ed25519 public key, so some seeds have no
+// address; FindProgramAddress searches for a bump seed that gives one.
+func CreateProgramAddress(seeds [][]byte, program PublicKey) (PublicKey, error) {
+	if len(seeds) > maxSeeds {
+		return PublicKey{}, ErrInvalidSeeds
+	}
+	h := sha256.New()
+	for _, seed := range seeds {
+		if len(seed) > maxSeedLength {
+			return PublicKey{}, ErrInvalidSeeds
+		}
+		h.Write(seed)
+	}
+	h.Write(program[:])
+	h.Write([]byte("ProgramDerivedAddress"))
+
+	var address PublicKey
+	copy(address[:], h.Sum(nil))
+	if isOnCurve(address[:]) {
+		return PublicKey{}, ErrInvalidSeeds
+	}
+	return address, nil
+}
+
+// FindProgramAddress returns the first program address for seeds plus a
+// bump seed counting down from 255, and that bump.
+func FindProgramAddress(seeds [][]byte, program PublicKey) (PublicKey, uint8, error) {
+	bumped := append(append([][]byte(nil), seeds...), nil)
+	for bump := 255; bump >= 0; bump-- {
+		bumped[len(seeds)] = []byte{byte(bump)}
+		address, err := CreateProgramAddress(bumped, program)
+		if err == nil {
+			return address, uint8(bump), nil
+		}
+	}
+	return PublicKey{}, 0, ErrInvalidSeeds
+}
+
+var (
+	curveP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// curveD is -121665/121666 mod p
+	curveD, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+)
+
+// isOnCurve reports whether b decompresses to an ed25519 point: x² =
+// (y²-1)/(dy²+1) must have a square root mod p.
+func isOnCurve(b []byte) bool {
+	le := make([]byte, len(b))
+	for i := range b {
+		le[len(b)-1-i] = b[i]
+	}
+	le[0] &= 0x7f // sign of x
+	y := new(big.Int).SetBytes(le)
+	y.Mod(y, curveP)
+
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	u.Mod(u, curveP)
+	v := new(big.Int).Mul(curveD, y2)
+	v.Add(v, big.NewInt(1))
+	v.Mod(v, curveP)
+	if v.Sign() == 0 {
+		return u.Sign() == 0
+	}
+
+	x2 := new(big.Int).ModInverse(v, curveP)
+	x2.Mul(x2, u)
+	x2.Mod(x2, curveP)
+	if x2.Sign() == 0 {
+		return true
+	}
+	// Euler's criterion
+	exp := new(big.Int).Rsh(new(big.Int).Sub(curveP, big.NewInt(1)), 1)
+	return new(big.Int).Exp(x2, exp, curveP).Cmp(big.NewInt(1)) == 0
+}
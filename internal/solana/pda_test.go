@@ -0,0 +1,57 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateProgramAddress(t *testing.T) {
+	program := mustPublicKey("BPFLoaderUpgradeab1e11111111111111111111111")
+	seedKey := mustPublicKey("SeedPubey1111111111111111111111111111111111")
+
+	tests := []struct {
+		seeds [][]byte
+		want  string
+	}{
+		{seeds: [][]byte{{}, {1}}, want: "BwqrghZA2htAcqq8dzP1WDAhTXYTYWj7CHxF5j7TDBAe"},
+		{seeds: [][]byte{[]byte("☉"), {0}}, want: "13yWmRpaTR4r5nAktwLqMpRNr28tnVUZw26rTvPSSB19"},
+		{seeds: [][]byte{[]byte("Talking"), []byte("Squirrels")}, want: "2fnQrngrQT4SeLcdToJAD96phoEjNL2man2kfRLCASVk"},
+		{seeds: [][]byte{seedKey[:], {1}}, want: "976ymqVnfE32QFe6NfGDctSvVa36LWnvYxhU6G2232YL"},
+	}
+
+	for _, tt := range tests {
+		address, err := CreateProgramAddress(tt.seeds, program)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, address.String())
+	}
+
+	_, err := CreateProgramAddress([][]byte{make([]byte, maxSeedLength+1)}, program)
+	assert.ErrorIs(t, err, ErrInvalidSeeds)
+	_, err = CreateProgramAddress(make([][]byte, maxSeeds+1), program)
+	assert.ErrorIs(t, err, ErrInvalidSeeds)
+}
+
+func TestFindProgramAddress(t *testing.T) {
+	program := mustPublicKey("BPFLoaderUpgradeab1e11111111111111111111111")
+	for i := 0; i < 100; i++ {
+		seeds := [][]byte{{byte(i)}, []byte("lookup")}
+		address, bump, err := FindProgramAddress(seeds, program)
+		assert.NoError(t, err)
+
+		derived, err := CreateProgramAddress(append(seeds, []byte{bump}), program)
+		assert.NoError(t, err)
+		assert.Equal(t, address, derived)
+	}
+}
+
+func TestIsOnCurve(t *testing.T) {
+	// Every real public key is on the curve
+	for i := byte(0); i < 50; i++ {
+		key := testKey(i).Public().(ed25519.PublicKey)
+		assert.True(t, isOnCurve(key), "key %d", i)
+	}
+	address := mustPublicKey("BwqrghZA2htAcqq8dzP1WDAhTXYTYWj7CHxF5j7TDBAe")
+	assert.False(t, isOnCurve(address[:]))
+}
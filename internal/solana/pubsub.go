@@ -0,0 +1,368 @@
+package solana
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamConn is a message-oriented connection to a node's PubSub endpoint.
+// WebSocketDialer provides the real one; tests and other transports can
+// supply their own through a StreamDialer.
+type StreamConn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+type StreamDialer interface {
+	Dial(ctx context.Context, endpoint string) (StreamConn, error)
+}
+
+// WebSocketDialer dials PubSub endpoints over WebSocket, sending Header with
+// the handshake, e.g. for providers that authenticate by header.
+type WebSocketDialer struct {
+	Header http.Header
+}
+
+func (d WebSocketDialer) Dial(ctx context.Context, endpoint string) (StreamConn, error) {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, d.Header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to dial %s: %w (HTTP %d)", endpoint, err, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to dial %s: %w", endpoint, err)
+	}
+	return &wsConn{conn: conn}, nil
+}
+
+type wsConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	return data, err
+}
+
+func (c *wsConn) WriteMessage(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
+
+// WebSocketURL is the PubSub endpoint nodes serve alongside an HTTP RPC
+// endpoint: the same URL with a ws or wss scheme.
+func WebSocketURL(endpoint string) string {
+	switch {
+	case strings.HasPrefix(endpoint, "https://"):
+		return "wss://" + strings.TrimPrefix(endpoint, "https://")
+	case strings.HasPrefix(endpoint, "http://"):
+		return "ws://" + strings.TrimPrefix(endpoint, "http://")
+	default:
+		return endpoint
+	}
+}
+
+// PubSub is one connection to a node's PubSub endpoint carrying any number
+// of subscriptions. Subscribe and Recv must be called from one goroutine;
+// Close may be called from any goroutine to unblock them.
+type PubSub struct {
+	conn   StreamConn
+	nextID uint64
+	// pending holds notifications read while waiting for a subscription to
+	// be confirmed.
+	pending []*Notification
+}
+
+// Notification is one message for a subscription. Result is its payload,
+// decoded with the method's accessor such as Logs or Account.
+type Notification struct {
+	Method       string
+	Subscription uint64
+	Result       json.RawMessage
+}
+
+type pubsubMessage struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+	Params struct {
+		Subscription uint64          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+func DialPubSub(ctx context.Context, dialer StreamDialer, endpoint string) (*PubSub, error) {
+	conn, err := dialer.Dial(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &PubSub{conn: conn}, nil
+}
+
+// Subscribe sends a subscription request and returns the subscription id
+// once the node confirms it. The connection is closed if ctx ends first.
+func (p *PubSub) Subscribe(ctx context.Context, method string, params ...interface{}) (uint64, error) {
+	// Reads block, so closing the connection is the only way to honour ctx
+	// while waiting for the confirmation.
+	subscribed := make(chan struct{})
+	defer close(subscribed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.conn.Close()
+		case <-subscribed:
+		}
+	}()
+
+	p.nextID++
+	id := p.nextID
+	request, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return 0, err
+	}
+	if err := p.conn.WriteMessage(request); err != nil {
+		return 0, fmt.Errorf("%s: %w", method, err)
+	}
+
+	for {
+		data, err := p.conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, fmt.Errorf("%s: %w", method, ctx.Err())
+			}
+			return 0, fmt.Errorf("%s: %w", method, err)
+		}
+		var msg pubsubMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		if msg.Method != "" {
+			p.pending = append(p.pending, &Notification{Method: msg.Method, Subscription: msg.Params.Subscription, Result: msg.Params.Result})
+			continue
+		}
+		if msg.ID != id {
+			continue
+		}
+		if msg.Error != nil {
+			return 0, fmt.Errorf("%s: %w", method, msg.Error)
+		}
+		var subscription uint64
+		if err := json.Unmarshal(msg.Result, &subscription); err != nil {
+			return 0, fmt.Errorf("%s: failed to decode subscription id: %w", method, err)
+		}
+		return subscription, nil
+	}
+}
+
+// Recv blocks until the next notification arrives or the connection fails.
+func (p *PubSub) Recv() (*Notification, error) {
+	if len(p.pending) > 0 {
+		n := p.pending[0]
+		p.pending = p.pending[1:]
+		return n, nil
+	}
+	for {
+		data, err := p.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		var msg pubsubMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode notification: %w", err)
+		}
+		if msg.Method == "" {
+			continue // A late reply to a request
+		}
+		return &Notification{Method: msg.Method, Subscription: msg.Params.Subscription, Result: msg.Params.Result}, nil
+	}
+}
+
+func (p *PubSub) Close() error {
+	return p.conn.Close()
+}
+
+// LogsSubscribe streams the logs of transactions that mention address as
+// logsNotification.
+func (p *PubSub) LogsSubscribe(ctx context.Context, address PublicKey, commitment Commitment) (uint64, error) {
+	return p.Subscribe(ctx, "logsSubscribe",
+		map[string][]PublicKey{"mentions": {address}},
+		map[string]Commitment{"commitment": commitment})
+}
+
+// AccountSubscribe streams account's state as accountNotification whenever
+// it changes.
+func (p *PubSub) AccountSubscribe(ctx context.Context, account PublicKey, commitment Commitment) (uint64, error) {
+	return p.Subscribe(ctx, "accountSubscribe", account,
+		map[string]interface{}{"encoding": "base64", "commitment": commitment})
+}
+
+// SlotSubscribe streams every slot the node processes as slotNotification.
+func (p *PubSub) SlotSubscribe(ctx context.Context) (uint64, error) {
+	return p.Subscribe(ctx, "slotSubscribe")
+}
+
+// Logs is one logsNotification: the log of a transaction that mentioned the
+// subscribed address. Err is nil when the transaction succeeded.
+type Logs struct {
+	Slot      uint64
+	Signature string
+	Err       json.RawMessage
+	Logs      []string
+}
+
+func (n *Notification) Logs() (*Logs, error) {
+	var result struct {
+		Context struct {
+			Slot uint64 `json:"slot"`
+		} `json:"context"`
+		Value struct {
+			Signature string          `json:"signature"`
+			Err       json.RawMessage `json:"err"`
+			Logs      []string        `json:"logs"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(n.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", n.Method, err)
+	}
+	logs := &Logs{
+		Slot:      result.Context.Slot,
+		Signature: result.Value.Signature,
+		Logs:      result.Value.Logs,
+	}
+	if len(result.Value.Err) > 0 && string(result.Value.Err) != "null" {
+		logs.Err = result.Value.Err
+	}
+	return logs, nil
+}
+
+// Account decodes an accountNotification into the slot it was observed at
+// and the account's new state.
+func (n *Notification) Account() (uint64, *AccountInfo, error) {
+	var result struct {
+		Context struct {
+			Slot uint64 `json:"slot"`
+		} `json:"context"`
+		Value *accountJSON `json:"value"`
+	}
+	if err := json.Unmarshal(n.Result, &result); err != nil {
+		return 0, nil, fmt.Errorf("failed to decode %s: %w", n.Method, err)
+	}
+	if result.Value == nil {
+		return result.Context.Slot, nil, ErrAccountNotFound
+	}
+	info, err := result.Value.decode()
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", n.Method, err)
+	}
+	return result.Context.Slot, info, nil
+}
+
+// SlotInfo is one slotNotification. Parent is the slot this one builds on,
+// so a Parent beyond the last slot seen means notifications were missed.
+type SlotInfo struct {
+	Slot   uint64 `json:"slot"`
+	Parent uint64 `json:"parent"`
+	Root   uint64 `json:"root"`
+}
+
+func (n *Notification) Slot() (*SlotInfo, error) {
+	var slot SlotInfo
+	if err := json.Unmarshal(n.Result, &slot); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", n.Method, err)
+	}
+	return &slot, nil
+}
+
+// LogsSubscription streams the logs of transactions mentioning an address
+// over a connection of its own. Recv is not safe for concurrent use; Close
+// may be called from any goroutine to unblock it.
+type LogsSubscription struct {
+	pubsub *PubSub
+	id     uint64
+}
+
+// SubscribeLogs dials endpoint and subscribes to the logs of transactions
+// that mention address, returning once the node has confirmed the
+// subscription.
+func SubscribeLogs(ctx context.Context, dialer StreamDialer, endpoint string, address PublicKey, commitment Commitment) (*LogsSubscription, error) {
+	pubsub, err := DialPubSub(ctx, dialer, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	id, err := pubsub.LogsSubscribe(ctx, address, commitment)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	return &LogsSubscription{pubsub: pubsub, id: id}, nil
+}
+
+// Recv blocks until the next notification arrives or the connection fails.
+func (s *LogsSubscription) Recv() (*Logs, error) {
+	for {
+		n, err := s.pubsub.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if n.Method != "logsNotification" || n.Subscription != s.id {
+			continue
+		}
+		return n.Logs()
+	}
+}
+
+func (s *LogsSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// ProgramData decodes the "Program data:" lines that program itself logged,
+// such as Anchor events, skipping those logged by programs it invoked or
+// that invoked it.
+func ProgramData(logs []string, program PublicKey) ([][]byte, error) {
+	id := program.String()
+	var stack []string
+	var data [][]byte
+	for _, line := range logs {
+		if rest := strings.TrimPrefix(line, "Program data: "); rest != line {
+			if len(stack) == 0 || stack[len(stack)-1] != id {
+				continue
+			}
+			for _, field := range strings.Fields(rest) {
+				decoded, err := base64.StdEncoding.DecodeString(field)
+				if err != nil {
+					return nil, fmt.Errorf("malformed program data log: %w", err)
+				}
+				data = append(data, decoded)
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "Program" {
+			continue
+		}
+		switch {
+		case fields[2] == "invoke":
+			stack = append(stack, fields[1])
+		case fields[2] == "success" || strings.HasPrefix(fields[2], "failed"):
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return data, nil
+}
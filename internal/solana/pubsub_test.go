@@ -0,0 +1,218 @@
+package solana
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pubsubStub accepts one logsSubscribe per connection and then sends the
+// queued messages.
+func pubsubStub(t *testing.T, messages ...interface{}) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, conn.ReadJSON(&req))
+		assert.Equal(t, "logsSubscribe", req.Method)
+		assert.JSONEq(t, `{"mentions":["11111111111111111111111111111111"]}`, string(req.Params[0]))
+		assert.JSONEq(t, `{"commitment":"confirmed"}`, string(req.Params[1]))
+
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": 42}))
+		for _, msg := range messages {
+			require.NoError(t, conn.WriteJSON(msg))
+		}
+		conn.ReadMessage() // Hold the connection until the client closes it
+	}))
+	t.Cleanup(server.Close)
+	return WebSocketURL(server.URL)
+}
+
+func logsNotification(subscription uint64, signature string, txErr interface{}, logs ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "logsNotification",
+		"params": map[string]interface{}{
+			"subscription": subscription,
+			"result": map[string]interface{}{
+				"context": map[string]int{"slot": 7},
+				"value":   map[string]interface{}{"signature": signature, "err": txErr, "logs": logs},
+			},
+		},
+	}
+}
+
+func TestSubscribeLogs(t *testing.T) {
+	endpoint := pubsubStub(t,
+		logsNotification(41, "other", nil, "Program log: not ours"),
+		logsNotification(42, "failed", map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}),
+		logsNotification(42, "ok", nil, "Program log: hello"),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	sub, err := SubscribeLogs(ctx, WebSocketDialer{}, endpoint, SystemProgramID, CommitmentConfirmed)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	logs, err := sub.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "failed", logs.Signature)
+	assert.NotNil(t, logs.Err)
+
+	logs, err = sub.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, &Logs{Slot: 7, Signature: "ok", Logs: []string{"Program log: hello"}}, logs)
+
+	sub.Close()
+	_, err = sub.Recv()
+	assert.Error(t, err)
+}
+
+func TestSubscribeLogs_Cancelled(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return // Never confirm the subscription
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := SubscribeLogs(ctx, WebSocketDialer{}, WebSocketURL(server.URL), SystemProgramID, CommitmentConfirmed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestProgramData(t *testing.T) {
+	program := PublicKey{9}
+	other := PublicKey{8}
+	encode := func(b ...byte) string { return "Program data: " + base64.StdEncoding.EncodeToString(b) }
+
+	logs := []string{
+		"Program " + other.String() + " invoke [1]",
+		encode(1),
+		"Program " + program.String() + " invoke [2]",
+		"Program log: Instruction: Create",
+		encode(2),
+		"Program " + other.String() + " invoke [3]",
+		encode(3),
+		"Program " + other.String() + " success",
+		encode(4),
+		"Program " + program.String() + " consumed 1000 of 2000 compute units",
+		"Program " + program.String() + " success",
+		encode(5),
+		"Program " + other.String() + " success",
+	}
+	data, err := ProgramData(logs, program)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{{2}, {4}}, data)
+
+	_, err = ProgramData(append(logs[:3:3], "Program data: !!"), program)
+	assert.Error(t, err)
+}
+
+func TestWebSocketURL(t *testing.T) {
+	assert.Equal(t, "wss://api.mainnet-beta.solana.com", WebSocketURL("https://api.mainnet-beta.solana.com"))
+	assert.Equal(t, "ws://127.0.0.1:8899/rpc", WebSocketURL("http://127.0.0.1:8899/rpc"))
+	assert.Equal(t, "wss://node", WebSocketURL("wss://node"))
+}
+
+func TestPubSub_Multiplexed(t *testing.T) {
+	account := PublicKey{5}
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, conn.ReadJSON(&req))
+		assert.Equal(t, "slotSubscribe", req.Method)
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": 1}))
+
+		require.NoError(t, conn.ReadJSON(&req))
+		assert.Equal(t, "accountSubscribe", req.Method)
+		assert.Equal(t, `"`+account.String()+`"`, string(req.Params[0]))
+		assert.JSONEq(t, `{"encoding":"base64","commitment":"processed"}`, string(req.Params[1]))
+
+		// A slot arrives before the account subscription is confirmed
+		slot := func(slot, parent uint64) map[string]interface{} {
+			return map[string]interface{}{"jsonrpc": "2.0", "method": "slotNotification", "params": map[string]interface{}{
+				"subscription": 1, "result": map[string]uint64{"slot": slot, "parent": parent, "root": parent - 32},
+			}}
+		}
+		require.NoError(t, conn.WriteJSON(slot(100, 99)))
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": 2}))
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "method": "accountNotification", "params": map[string]interface{}{
+			"subscription": 2,
+			"result": map[string]interface{}{
+				"context": map[string]uint64{"slot": 101},
+				"value": map[string]interface{}{
+					"lamports": 10, "owner": SystemProgramID, "data": []string{"AQID", "base64"}, "executable": false, "rentEpoch": 0,
+				},
+			},
+		}}))
+		require.NoError(t, conn.WriteJSON(slot(103, 102)))
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	pubsub, err := DialPubSub(ctx, WebSocketDialer{}, WebSocketURL(server.URL))
+	require.NoError(t, err)
+	defer pubsub.Close()
+
+	slots, err := pubsub.SlotSubscribe(ctx)
+	require.NoError(t, err)
+	accounts, err := pubsub.AccountSubscribe(ctx, account, CommitmentProcessed)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, []uint64{slots, accounts})
+
+	n, err := pubsub.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, slots, n.Subscription)
+	slot, err := n.Slot()
+	require.NoError(t, err)
+	assert.Equal(t, SlotInfo{Slot: 100, Parent: 99, Root: 67}, *slot)
+
+	n, err = pubsub.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "accountNotification", n.Method)
+	at, info, err := n.Account()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), at)
+	assert.Equal(t, []byte{1, 2, 3}, info.Data)
+	assert.Equal(t, uint64(10), info.Lamports)
+
+	n, err = pubsub.Recv()
+	require.NoError(t, err)
+	slot, err = n.Slot()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), slot.Parent)
+}
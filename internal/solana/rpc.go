@@ -0,0 +1,344 @@
+package solana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Commitment is how settled a slot must be before a query counts it.
+type Commitment string
+
+const (
+	CommitmentProcessed Commitment = "processed"
+	CommitmentConfirmed Commitment = "confirmed"
+	CommitmentFinalized Commitment = "finalized"
+)
+
+// rank orders commitment levels so a status can be compared to a target.
+func (c Commitment) rank() int {
+	switch c {
+	case CommitmentProcessed:
+		return 1
+	case CommitmentConfirmed:
+		return 2
+	case CommitmentFinalized:
+		return 3
+	default:
+		return 0
+	}
+}
+
+const (
+	defaultRPCTimeout = 30 * time.Second
+	defaultRetries    = 3
+	defaultBackoff    = 250 * time.Millisecond
+	defaultMaxBackoff = 4 * time.Second
+)
+
+type ClientConfig struct {
+	// HTTPClient defaults to one with a 30s timeout.
+	HTTPClient *http.Client
+	// MaxRetries is how many times a failed call is retried after the first
+	// attempt; defaults to 3, negative disables retries.
+	MaxRetries int
+	// Backoff is the first retry delay, doubled on every further attempt up
+	// to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Client is a Solana JSON-RPC client. Calls that fail on the transport,
+// with a throttling or unavailable HTTP status, or with a node error marked
+// Temporary are retried with exponential backoff.
+type Client struct {
+	endpoint   string
+	http       *http.Client
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	nextID     uint64
+}
+
+func NewClient(endpoint string) *Client {
+	return NewClientWithConfig(endpoint, ClientConfig{})
+}
+
+func NewClientWithConfig(endpoint string, config ClientConfig) *Client {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: defaultRPCTimeout}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultRetries
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultBackoff
+	}
+	if config.MaxBackoff < config.Backoff {
+		config.MaxBackoff = defaultMaxBackoff
+		if config.MaxBackoff < config.Backoff {
+			config.MaxBackoff = config.Backoff
+		}
+	}
+
+	return &Client{
+		endpoint:   endpoint,
+		http:       config.HTTPClient,
+		maxRetries: config.MaxRetries,
+		backoff:    config.Backoff,
+		maxBackoff: config.MaxBackoff,
+	}
+}
+
+// Endpoint is the URL the client sends requests to.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+func (c *Client) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&c.nextID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		raw, err := c.post(ctx, body)
+		if err == nil {
+			if result == nil {
+				return nil
+			}
+			if err := json.Unmarshal(raw, result); err != nil {
+				return fmt.Errorf("%s: failed to decode result: %w", method, err)
+			}
+			return nil
+		}
+		if attempt >= c.maxRetries || !retryable(ctx, err) {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: %w", method, err)
+		case <-timer.C:
+		}
+		if delay *= 2; delay > c.maxBackoff {
+			delay = c.maxBackoff
+		}
+	}
+}
+
+// post sends one JSON-RPC request and returns the raw result.
+func (c *Client) post(ctx context.Context, body []byte) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+
+	var decoded rpcResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if decoded.Error != nil {
+		return nil, decoded.Error
+	}
+	return decoded.Result, nil
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Temporary()
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+	// Anything else that failed on the way to or from the node
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type SendOptions struct {
+	SkipPreflight       bool
+	PreflightCommitment Commitment
+	MaxRetries          *uint
+}
+
+// SendTransaction broadcasts a signed transaction and returns its signature.
+func (c *Client) SendTransaction(ctx context.Context, tx *Transaction, opts SendOptions) (Signature, error) {
+	config := map[string]interface{}{
+		"encoding":      "base64",
+		"skipPreflight": opts.SkipPreflight,
+	}
+	if opts.PreflightCommitment != "" {
+		config["preflightCommitment"] = opts.PreflightCommitment
+	}
+	if opts.MaxRetries != nil {
+		config["maxRetries"] = *opts.MaxRetries
+	}
+
+	var encoded string
+	if err := c.call(ctx, "sendTransaction", []interface{}{tx.Base64(), config}, &encoded); err != nil {
+		return Signature{}, err
+	}
+	return SignatureFromBase58(encoded)
+}
+
+// SignatureStatus is a node's view of a submitted transaction. Err is the
+// raw on-chain error and is null for successful transactions.
+type SignatureStatus struct {
+	Slot               uint64          `json:"slot"`
+	Confirmations      *uint64         `json:"confirmations"`
+	Err                json.RawMessage `json:"err"`
+	ConfirmationStatus Commitment      `json:"confirmationStatus"`
+}
+
+// Failed reports whether the transaction landed with an error.
+func (s *SignatureStatus) Failed() bool {
+	return len(s.Err) > 0 && string(s.Err) != "null"
+}
+
+// Reached reports whether the transaction is at least as settled as
+// commitment.
+func (s *SignatureStatus) Reached(commitment Commitment) bool {
+	return s.ConfirmationStatus.rank() >= commitment.rank()
+}
+
+// GetSignatureStatuses returns one status per signature, nil for those the
+// node does not know.
+func (c *Client) GetSignatureStatuses(ctx context.Context, signatures []Signature, searchHistory bool) ([]*SignatureStatus, error) {
+	encoded := make([]string, len(signatures))
+	for i, sig := range signatures {
+		encoded[i] = sig.String()
+	}
+
+	var result struct {
+		Value []*SignatureStatus `json:"value"`
+	}
+	params := []interface{}{encoded, map[string]bool{"searchTransactionHistory": searchHistory}}
+	if err := c.call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Value) != len(signatures) {
+		return nil, fmt.Errorf("getSignatureStatuses: %d statuses for %d signatures", len(result.Value), len(signatures))
+	}
+	return result.Value, nil
+}
+
+// Blockhash is a recent blockhash and the last block height a transaction
+// using it can land in.
+type Blockhash struct {
+	Blockhash            Hash   `json:"blockhash"`
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+}
+
+func (c *Client) GetLatestBlockhash(ctx context.Context, commitment Commitment) (*Blockhash, error) {
+	var result struct {
+		Value *Blockhash `json:"value"`
+	}
+	if err := c.call(ctx, "getLatestBlockhash", commitmentParams(commitment), &result); err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, errors.New("getLatestBlockhash: empty result")
+	}
+	return result.Value, nil
+}
+
+type SimulateOptions struct {
+	SigVerify              bool
+	ReplaceRecentBlockhash bool
+	Commitment             Commitment
+}
+
+// SimulationResult is the outcome of running a transaction against the
+// node's bank without committing it. Err is null when it would succeed.
+type SimulationResult struct {
+	Err           json.RawMessage `json:"err"`
+	Logs          []string        `json:"logs"`
+	UnitsConsumed *uint64         `json:"unitsConsumed"`
+}
+
+// Failed reports whether the transaction would fail on chain.
+func (r *SimulationResult) Failed() bool {
+	return len(r.Err) > 0 && string(r.Err) != "null"
+}
+
+func (c *Client) SimulateTransaction(ctx context.Context, tx *Transaction, opts SimulateOptions) (*SimulationResult, error) {
+	config := map[string]interface{}{
+		"encoding":               "base64",
+		"sigVerify":              opts.SigVerify,
+		"replaceRecentBlockhash": opts.ReplaceRecentBlockhash,
+	}
+	if opts.Commitment != "" {
+		config["commitment"] = opts.Commitment
+	}
+
+	var result struct {
+		Value *SimulationResult `json:"value"`
+	}
+	if err := c.call(ctx, "simulateTransaction", []interface{}{tx.Base64(), config}, &result); err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, errors.New("simulateTransaction: empty result")
+	}
+	return result.Value, nil
+}
+
+// commitmentParams builds the optional trailing config object shared by
+// most queries.
+func commitmentParams(commitment Commitment, params ...interface{}) []interface{} {
+	if commitment == "" {
+		return params
+	}
+	return append(params, map[string]interface{}{"commitment": commitment})
+}
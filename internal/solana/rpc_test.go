@@ -0,0 +1,375 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rpcStub is a local JSON-RPC endpoint answering each method with a handler.
+type rpcStub struct {
+	mu       sync.Mutex
+	handlers map[string]func(params []json.RawMessage) (interface{}, *RPCError)
+	calls    map[string]int
+}
+
+func newRPCStub(t *testing.T) (*rpcStub, *Client) {
+	stub := &rpcStub{
+		handlers: make(map[string]func(params []json.RawMessage) (interface{}, *RPCError)),
+		calls:    make(map[string]int),
+	}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, NewClient(server.URL)
+}
+
+func (s *rpcStub) handle(method string, handler func(params []json.RawMessage) (interface{}, *RPCError)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = handler
+}
+
+func (s *rpcStub) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *rpcStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	handler, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if !ok {
+		resp["error"] = &RPCError{Code: -32601, Message: "Method not found"}
+	} else if result, rpcErr := handler(req.Params); rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestClient_SendTransaction(t *testing.T) {
+	stub, client := newRPCStub(t)
+	payer := testKey(1)
+	tx, err := ParseTransaction(unsignedTransaction(testMessage(true, publicKeyOf(payer)), 1))
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Sign(payer))
+
+	stub.handle("sendTransaction", func(params []json.RawMessage) (interface{}, *RPCError) {
+		var encoded string
+		var config map[string]interface{}
+		json.Unmarshal(params[0], &encoded)
+		json.Unmarshal(params[1], &config)
+		assert.Equal(t, tx.Base64(), encoded)
+		assert.Equal(t, map[string]interface{}{"encoding": "base64", "skipPreflight": true, "preflightCommitment": "finalized"}, config)
+		return tx.Signatures[0].String(), nil
+	})
+
+	sig, err := client.SendTransaction(context.Background(), tx, SendOptions{SkipPreflight: true, PreflightCommitment: CommitmentFinalized})
+	assert.NoError(t, err)
+	assert.Equal(t, tx.Signatures[0], sig)
+
+	stub.handle("sendTransaction", func(params []json.RawMessage) (interface{}, *RPCError) {
+		return nil, &RPCError{Code: -32002, Message: "Transaction simulation failed: Blockhash not found"}
+	})
+	_, err = client.SendTransaction(context.Background(), tx, SendOptions{})
+	var rpcErr *RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, -32002, rpcErr.Code)
+}
+
+func TestClient_ConfirmTransaction(t *testing.T) {
+	var sig Signature
+	sig[0] = 7
+
+	tests := []struct {
+		name       string
+		statuses   []string
+		commitment Commitment
+		wantPolls  int
+		wantErr    error
+	}{
+		{
+			name:       "waits for confirmed",
+			statuses:   []string{`null`, `{"slot":10,"confirmations":0,"err":null,"confirmationStatus":"processed"}`, `{"slot":10,"confirmations":1,"err":null,"confirmationStatus":"confirmed"}`},
+			commitment: CommitmentConfirmed,
+			wantPolls:  3,
+		},
+		{
+			name:       "processed is enough",
+			statuses:   []string{`{"slot":10,"confirmations":0,"err":null,"confirmationStatus":"processed"}`},
+			commitment: CommitmentProcessed,
+			wantPolls:  1,
+		},
+		{
+			name:       "finalized satisfies confirmed",
+			statuses:   []string{`{"slot":10,"confirmations":null,"err":null,"confirmationStatus":"finalized"}`},
+			commitment: CommitmentConfirmed,
+			wantPolls:  1,
+		},
+		{
+			name:       "on-chain failure",
+			statuses:   []string{`{"slot":10,"confirmations":0,"err":{"InstructionError":[0,{"Custom":6001}]},"confirmationStatus":"processed"}`},
+			commitment: CommitmentFinalized,
+			wantPolls:  1,
+			wantErr:    &TransactionError{},
+		},
+		{
+			name:       "never lands",
+			statuses:   []string{`null`},
+			commitment: CommitmentConfirmed,
+			wantErr:    ErrConfirmationTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, client := newRPCStub(t)
+			stub.handle("getSignatureStatuses", func(params []json.RawMessage) (interface{}, *RPCError) {
+				var sigs []string
+				json.Unmarshal(params[0], &sigs)
+				assert.Equal(t, []string{sig.String()}, sigs)
+
+				idx := stub.count("getSignatureStatuses") - 1
+				if idx >= len(tt.statuses) {
+					idx = len(tt.statuses) - 1
+				}
+				return map[string]interface{}{
+					"context": map[string]int{"slot": 10},
+					"value":   []json.RawMessage{json.RawMessage(tt.statuses[idx])},
+				}, nil
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			status, err := client.ConfirmTransaction(ctx, sig, tt.commitment, time.Millisecond)
+
+			switch want := tt.wantErr.(type) {
+			case nil:
+				assert.NoError(t, err)
+				assert.True(t, status.Reached(tt.commitment))
+				assert.Equal(t, tt.wantPolls, stub.count("getSignatureStatuses"))
+			case *TransactionError:
+				var txErr *TransactionError
+				assert.True(t, errors.As(err, &txErr))
+				assert.Equal(t, sig, txErr.Signature)
+				assert.Contains(t, txErr.Err, "6001")
+				assert.True(t, status.Failed())
+			default:
+				assert.ErrorIs(t, err, want)
+			}
+		})
+	}
+
+	_, client := newRPCStub(t)
+	_, err := client.ConfirmTransaction(context.Background(), sig, "single", time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestClient_UnknownMethod(t *testing.T) {
+	_, client := newRPCStub(t)
+	_, err := client.GetSignatureStatuses(context.Background(), []Signature{{}}, true)
+	var rpcErr *RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, -32601, rpcErr.Code)
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		status    int
+		rpcErr    *RPCError
+		wantCalls int
+		wantErr   interface{}
+	}{
+		{name: "recovers from throttling", failures: 2, status: http.StatusTooManyRequests, wantCalls: 3},
+		{name: "recovers from unhealthy node", failures: 1, rpcErr: &RPCError{Code: CodeNodeUnhealthy, Message: "Node is behind"}, wantCalls: 2},
+		{name: "gives up after max retries", failures: 10, status: http.StatusServiceUnavailable, wantCalls: 3, wantErr: &HTTPError{}},
+		{name: "does not retry bad requests", failures: 10, status: http.StatusBadRequest, wantCalls: 1, wantErr: &HTTPError{}},
+		{name: "does not retry invalid params", failures: 10, rpcErr: &RPCError{Code: CodeInvalidParams, Message: "Invalid param"}, wantCalls: 1, wantErr: &RPCError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls++
+				n := calls
+				mu.Unlock()
+
+				if n <= tt.failures && tt.status != 0 {
+					http.Error(w, "slow down", tt.status)
+					return
+				}
+				resp := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+				if n <= tt.failures {
+					resp["error"] = tt.rpcErr
+				} else {
+					resp["result"] = map[string]interface{}{"context": map[string]int{"slot": 1}, "value": 5000}
+				}
+				json.NewEncoder(w).Encode(resp)
+			}))
+			defer server.Close()
+
+			client := NewClientWithConfig(server.URL, ClientConfig{MaxRetries: 2, Backoff: time.Millisecond})
+			balance, err := client.GetBalance(context.Background(), PublicKey{}, "")
+
+			assert.Equal(t, tt.wantCalls, calls)
+			switch tt.wantErr.(type) {
+			case nil:
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(5000), balance)
+			case *HTTPError:
+				var httpErr *HTTPError
+				assert.True(t, errors.As(err, &httpErr))
+				assert.Equal(t, tt.status, httpErr.StatusCode)
+			case *RPCError:
+				var rpcErr *RPCError
+				assert.True(t, errors.As(err, &rpcErr))
+				assert.Equal(t, tt.rpcErr.Code, rpcErr.Code)
+			}
+		})
+	}
+
+	// Retries stop with the context
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client := NewClientWithConfig(server.URL, ClientConfig{MaxRetries: 100, Backoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetBalance(ctx, PublicKey{}, "")
+	assert.Error(t, err)
+}
+
+func TestClient_Queries(t *testing.T) {
+	stub, client := newRPCStub(t)
+	owner := publicKeyOf(testKey(1))
+	mint := publicKeyOf(testKey(2))
+	tokenAccount := publicKeyOf(testKey(3))
+
+	stub.handle("getBalance", func(params []json.RawMessage) (interface{}, *RPCError) {
+		assert.JSONEq(t, `"`+owner.String()+`"`, string(params[0]))
+		assert.JSONEq(t, `{"commitment":"confirmed"}`, string(params[1]))
+		return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": uint64(2500000000)}, nil
+	})
+	balance, err := client.GetBalance(context.Background(), owner, CommitmentConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2500000000), balance)
+
+	stub.handle("getLatestBlockhash", func(params []json.RawMessage) (interface{}, *RPCError) {
+		assert.Empty(t, params)
+		return map[string]interface{}{
+			"context": map[string]int{"slot": 1},
+			"value":   map[string]interface{}{"blockhash": mint.String(), "lastValidBlockHeight": 3090},
+		}, nil
+	})
+	blockhash, err := client.GetLatestBlockhash(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(mint), blockhash.Blockhash)
+	assert.Equal(t, uint64(3090), blockhash.LastValidBlockHeight)
+
+	stub.handle("getAccountInfo", func(params []json.RawMessage) (interface{}, *RPCError) {
+		var account string
+		json.Unmarshal(params[0], &account)
+		if account != mint.String() {
+			return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": nil}, nil
+		}
+		assert.JSONEq(t, `{"encoding":"base64"}`, string(params[1]))
+		return map[string]interface{}{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]interface{}{
+				"lamports":   1461600,
+				"owner":      TokenProgramID.String(),
+				"data":       []string{"AQID", "base64"},
+				"executable": false,
+				"rentEpoch":  uint64(18446744073709551615),
+			},
+		}, nil
+	})
+	info, err := client.GetAccountInfo(context.Background(), mint, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &AccountInfo{Lamports: 1461600, Owner: TokenProgramID, Data: []byte{1, 2, 3}, RentEpoch: 18446744073709551615}, info)
+	_, err = client.GetAccountInfo(context.Background(), owner, "")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+
+	stub.handle("getTokenAccountsByOwner", func(params []json.RawMessage) (interface{}, *RPCError) {
+		assert.JSONEq(t, `{"mint":"`+mint.String()+`"}`, string(params[1]))
+		assert.JSONEq(t, `{"encoding":"jsonParsed","commitment":"finalized"}`, string(params[2]))
+		return map[string]interface{}{
+			"context": map[string]int{"slot": 1},
+			"value": []interface{}{map[string]interface{}{
+				"pubkey": tokenAccount.String(),
+				"account": map[string]interface{}{
+					"data": map[string]interface{}{
+						"program": "spl-token",
+						"parsed": map[string]interface{}{
+							"type": "account",
+							"info": map[string]interface{}{
+								"mint":        mint.String(),
+								"owner":       owner.String(),
+								"tokenAmount": map[string]interface{}{"amount": "1234500", "decimals": 6, "uiAmount": 1.2345},
+							},
+						},
+					},
+				},
+			}},
+		}, nil
+	})
+	accounts, err := client.GetTokenAccountsByOwner(context.Background(), owner, TokenAccountsFilter{Mint: &mint}, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, []TokenAccount{{Address: tokenAccount, Mint: mint, Owner: owner, Amount: 1234500, Decimals: 6}}, accounts)
+	assert.InDelta(t, 1.2345, accounts[0].UIAmount(), 1e-12)
+
+	_, err = client.GetTokenAccountsByOwner(context.Background(), owner, TokenAccountsFilter{}, "")
+	assert.Error(t, err)
+}
+
+func TestClient_SimulateTransaction(t *testing.T) {
+	stub, client := newRPCStub(t)
+	tx, err := ParseTransaction(unsignedTransaction(testMessage(false, publicKeyOf(testKey(1))), 1))
+	assert.NoError(t, err)
+
+	stub.handle("simulateTransaction", func(params []json.RawMessage) (interface{}, *RPCError) {
+		assert.JSONEq(t, `{"encoding":"base64","sigVerify":false,"replaceRecentBlockhash":true,"commitment":"processed"}`, string(params[1]))
+		return map[string]interface{}{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]interface{}{
+				"err":           map[string]interface{}{"InstructionError": []interface{}{0, "InvalidAccountData"}},
+				"logs":          []string{"Program 11111111111111111111111111111111 invoke [1]"},
+				"unitsConsumed": 150,
+			},
+		}, nil
+	})
+
+	result, err := client.SimulateTransaction(context.Background(), tx, SimulateOptions{ReplaceRecentBlockhash: true, Commitment: CommitmentProcessed})
+	assert.NoError(t, err)
+	assert.True(t, result.Failed())
+	assert.Len(t, result.Logs, 1)
+	assert.Equal(t, uint64(150), *result.UnitsConsumed)
+}
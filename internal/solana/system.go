@@ -0,0 +1,26 @@
+package solana
+
+import "encoding/binary"
+
+var SystemProgramID = PublicKey{}
+
+// LamportsPerSOL is the number of lamports in one SOL.
+const LamportsPerSOL = 1000000000
+
+const systemTransfer = 2
+
+// SystemTransfer moves lamports between two system accounts; from signs.
+func SystemTransfer(from, to PublicKey, lamports uint64) Instruction {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, systemTransfer)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+
+	return Instruction{
+		ProgramID: SystemProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: from, IsSigner: true, IsWritable: true},
+			{PublicKey: to, IsWritable: true},
+		},
+		Data: data,
+	}
+}
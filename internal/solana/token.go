@@ -0,0 +1,107 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	TokenProgramID           = mustPublicKey("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	Token2022ProgramID       = mustPublicKey("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+	AssociatedTokenProgramID = mustPublicKey("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL")
+	// NativeMint is wrapped SOL. Wallets and aggregators use it to mean SOL
+	// itself.
+	NativeMint = mustPublicKey("So11111111111111111111111111111111111111112")
+)
+
+var ErrNotMint = errors.New("account is not a token mint")
+
+const (
+	tokenTransferChecked = 12
+	// mintSize is the length of an SPL mint; Token-2022 mints may carry
+	// extensions after it.
+	mintSize = 82
+)
+
+// Mint is the part of an SPL mint account needed to move its tokens.
+type Mint struct {
+	Address   PublicKey
+	ProgramID PublicKey
+	Supply    uint64
+	Decimals  uint8
+}
+
+// ParseMint decodes the account data of a mint owned by program.
+func ParseMint(address, program PublicKey, data []byte) (*Mint, error) {
+	if program != TokenProgramID && program != Token2022ProgramID {
+		return nil, fmt.Errorf("%w: %s is owned by %s", ErrNotMint, address, program)
+	}
+	if len(data) < mintSize || data[45] != 1 {
+		return nil, fmt.Errorf("%w: %s", ErrNotMint, address)
+	}
+	return &Mint{
+		Address:   address,
+		ProgramID: program,
+		Supply:    binary.LittleEndian.Uint64(data[36:]),
+		Decimals:  data[44],
+	}, nil
+}
+
+// GetMint loads the mint at address.
+func (c *Client) GetMint(ctx context.Context, address PublicKey, commitment Commitment) (*Mint, error) {
+	account, err := c.GetAccountInfo(ctx, address, commitment)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMint(address, account.Owner, account.Data)
+}
+
+// AssociatedTokenAddress is the canonical token account owner holds for
+// mint under the given token program.
+func AssociatedTokenAddress(owner, mint, tokenProgram PublicKey) (PublicKey, error) {
+	address, _, err := FindProgramAddress([][]byte{owner[:], tokenProgram[:], mint[:]}, AssociatedTokenProgramID)
+	return address, err
+}
+
+// CreateAssociatedTokenAccountIdempotent creates owner's associated account
+// for mint, paid for by payer, and succeeds if it already exists.
+func CreateAssociatedTokenAccountIdempotent(payer, owner, mint, tokenProgram PublicKey) (Instruction, error) {
+	account, err := AssociatedTokenAddress(owner, mint, tokenProgram)
+	if err != nil {
+		return Instruction{}, err
+	}
+	return Instruction{
+		ProgramID: AssociatedTokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: payer, IsSigner: true, IsWritable: true},
+			{PublicKey: account, IsWritable: true},
+			{PublicKey: owner},
+			{PublicKey: mint},
+			{PublicKey: SystemProgramID},
+			{PublicKey: tokenProgram},
+		},
+		Data: []byte{1},
+	}, nil
+}
+
+// TransferChecked moves amount base units of mint between token accounts.
+// The program rejects it unless decimals match the mint.
+func TransferChecked(source, mint, destination, owner PublicKey, amount uint64, decimals uint8, tokenProgram PublicKey) Instruction {
+	data := make([]byte, 10)
+	data[0] = tokenTransferChecked
+	binary.LittleEndian.PutUint64(data[1:], amount)
+	data[9] = decimals
+
+	return Instruction{
+		ProgramID: tokenProgram,
+		Accounts: []AccountMeta{
+			{PublicKey: source, IsWritable: true},
+			{PublicKey: mint},
+			{PublicKey: destination, IsWritable: true},
+			{PublicKey: owner, IsSigner: true},
+		},
+		Data: data,
+	}
+}
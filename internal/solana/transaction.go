@@ -0,0 +1,143 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSignerNotFound    = errors.New("key is not a required signer")
+	ErrInvalidSignature  = errors.New("signature does not verify")
+	ErrMalformedMessage  = errors.New("malformed message")
+	ErrUnsupportedFormat = errors.New("unsupported transaction version")
+)
+
+// versionPrefix marks a versioned message; the low bits hold the version.
+const versionPrefix = 0x80
+
+// Transaction is a signed or partially signed transaction as it travels on
+// the wire. Message holds the serialized message exactly as it is signed.
+type Transaction struct {
+	Signatures []Signature
+	Message    []byte
+}
+
+// NewTransaction wraps msg with an empty signature slot for each signer.
+func NewTransaction(msg *Message) *Transaction {
+	return &Transaction{
+		Signatures: make([]Signature, msg.Header.NumRequiredSignatures),
+		Message:    msg.Serialize(),
+	}
+}
+
+func ParseTransaction(b []byte) (*Transaction, error) {
+	count, n, err := DecodeCompactU16(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature count: %w", err)
+	}
+	b = b[n:]
+	if len(b) < int(count)*SignatureSize {
+		return nil, fmt.Errorf("%w: truncated signatures", ErrMalformedMessage)
+	}
+
+	tx := &Transaction{Signatures: make([]Signature, count)}
+	for i := range tx.Signatures {
+		copy(tx.Signatures[i][:], b[i*SignatureSize:])
+	}
+	tx.Message = append([]byte(nil), b[int(count)*SignatureSize:]...)
+
+	signers, err := tx.Signers()
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) != len(tx.Signatures) {
+		return nil, fmt.Errorf("%w: %d signatures for %d signers", ErrMalformedMessage, len(tx.Signatures), len(signers))
+	}
+	return tx, nil
+}
+
+// ParseTransactionBase64 decodes the base64 form RPC nodes and aggregators
+// such as Jupiter use.
+func ParseTransactionBase64(s string) (*Transaction, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 transaction: %w", err)
+	}
+	return ParseTransaction(b)
+}
+
+// ParseTransactionBase58 decodes the base58 form older RPC encodings use.
+func ParseTransactionBase58(s string) (*Transaction, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base58 transaction: %w", err)
+	}
+	return ParseTransaction(b)
+}
+
+func (tx *Transaction) Serialize() []byte {
+	b := AppendCompactU16(nil, uint16(len(tx.Signatures)))
+	for _, sig := range tx.Signatures {
+		b = append(b, sig[:]...)
+	}
+	return append(b, tx.Message...)
+}
+
+func (tx *Transaction) Base64() string {
+	return base64.StdEncoding.EncodeToString(tx.Serialize())
+}
+
+func (tx *Transaction) Base58() string {
+	return EncodeBase58(tx.Serialize())
+}
+
+// DecodeMessage parses the serialized message.
+func (tx *Transaction) DecodeMessage() (*Message, error) {
+	return ParseMessage(tx.Message)
+}
+
+// Signers returns the accounts whose signatures the message requires, in
+// signature order.
+func (tx *Transaction) Signers() ([]PublicKey, error) {
+	msg, err := tx.DecodeMessage()
+	if err != nil {
+		return nil, err
+	}
+	return msg.Signers(), nil
+}
+
+// AddSignature places sig in the slot of signer after checking it signs the
+// message.
+func (tx *Transaction) AddSignature(signer PublicKey, sig Signature) error {
+	signers, err := tx.Signers()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(signer[:], tx.Message, sig[:]) {
+		return ErrInvalidSignature
+	}
+	for i, key := range signers {
+		if key == signer {
+			if len(tx.Signatures) < len(signers) {
+				sigs := make([]Signature, len(signers))
+				copy(sigs, tx.Signatures)
+				tx.Signatures = sigs
+			}
+			tx.Signatures[i] = sig
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrSignerNotFound, signer)
+}
+
+// Sign signs the message with key, which must be one of its signers.
+func (tx *Transaction) Sign(key ed25519.PrivateKey) error {
+	var signer PublicKey
+	copy(signer[:], key.Public().(ed25519.PublicKey))
+
+	var sig Signature
+	copy(sig[:], ed25519.Sign(key, tx.Message))
+	return tx.AddSignature(signer, sig)
+}
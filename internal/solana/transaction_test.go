@@ -0,0 +1,111 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(append(make([]byte, 31), seed))
+}
+
+func publicKeyOf(key ed25519.PrivateKey) PublicKey {
+	var pub PublicKey
+	copy(pub[:], key.Public().(ed25519.PublicKey))
+	return pub
+}
+
+// testMessage serializes a message where every signer is writable and one
+// extra read-only program account is invoked with no data.
+func testMessage(versioned bool, signers ...PublicKey) []byte {
+	var msg []byte
+	if versioned {
+		msg = append(msg, versionPrefix)
+	}
+	msg = append(msg, byte(len(signers)), 0, 1)
+	msg = AppendCompactU16(msg, uint16(len(signers)+1))
+	for _, signer := range signers {
+		msg = append(msg, signer[:]...)
+	}
+	msg = append(msg, make([]byte, PublicKeySize)...) // system program
+	msg = append(msg, make([]byte, 32)...)            // recent blockhash
+	msg = AppendCompactU16(msg, 1)
+	msg = append(msg, byte(len(signers)))
+	msg = AppendCompactU16(msg, 1)
+	msg = append(msg, 0)
+	msg = AppendCompactU16(msg, 0)
+	if versioned {
+		msg = AppendCompactU16(msg, 0) // no lookup tables
+	}
+	return msg
+}
+
+func unsignedTransaction(message []byte, signers int) []byte {
+	raw := AppendCompactU16(nil, uint16(signers))
+	raw = append(raw, make([]byte, signers*SignatureSize)...)
+	return append(raw, message...)
+}
+
+func TestTransaction_ParseAndSign(t *testing.T) {
+	payer, cosigner := testKey(1), testKey(2)
+
+	for _, versioned := range []bool{false, true} {
+		message := testMessage(versioned, publicKeyOf(payer), publicKeyOf(cosigner))
+		raw := unsignedTransaction(message, 2)
+
+		tx, err := ParseTransaction(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, message, tx.Message)
+		assert.Equal(t, raw, tx.Serialize())
+
+		signers, err := tx.Signers()
+		assert.NoError(t, err)
+		assert.Equal(t, []PublicKey{publicKeyOf(payer), publicKeyOf(cosigner)}, signers)
+
+		assert.NoError(t, tx.Sign(cosigner))
+		assert.True(t, tx.Signatures[0].IsZero())
+		assert.True(t, ed25519.Verify(cosigner.Public().(ed25519.PublicKey), message, tx.Signatures[1][:]))
+
+		assert.NoError(t, tx.Sign(payer))
+		decoded, err := ParseTransactionBase64(tx.Base64())
+		assert.NoError(t, err)
+		assert.Equal(t, tx, decoded)
+
+		assert.ErrorIs(t, tx.Sign(testKey(3)), ErrSignerNotFound)
+		var forged Signature
+		assert.ErrorIs(t, tx.AddSignature(publicKeyOf(payer), forged), ErrInvalidSignature)
+	}
+}
+
+func TestParseTransaction_Errors(t *testing.T) {
+	payer := publicKeyOf(testKey(1))
+	message := testMessage(false, payer)
+
+	v1 := testMessage(true, payer)
+	v1[0] = versionPrefix | 1
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		wantErr error
+	}{
+		{name: "empty", raw: nil, wantErr: ErrInvalidCompactU16},
+		{name: "truncated signatures", raw: append([]byte{1}, make([]byte, 10)...), wantErr: ErrMalformedMessage},
+		{name: "signature count mismatch", raw: unsignedTransaction(message, 2), wantErr: ErrMalformedMessage},
+		{name: "missing message", raw: unsignedTransaction(nil, 1), wantErr: ErrMalformedMessage},
+		{name: "truncated keys", raw: unsignedTransaction(message[:20], 1), wantErr: ErrMalformedMessage},
+		{name: "unsupported version", raw: unsignedTransaction(v1, 1), wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTransaction(tt.raw)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := ParseTransactionBase64("not base64!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+// Package solana holds the pieces of the Solana wire format and JSON-RPC API
+// the trading system needs to read accounts and to sign and submit
+// transactions.
+package solana
+
+import (
+	"crypto/ed25519"
+	"fmt"
+)
+
+const (
+	PublicKeySize = ed25519.PublicKeySize
+	SignatureSize = ed25519.SignatureSize
+)
+
+type PublicKey [PublicKeySize]byte
+
+func PublicKeyFromBase58(s string) (PublicKey, error) {
+	var key PublicKey
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return key, fmt.Errorf("invalid public key %q: %w", s, err)
+	}
+	if len(b) != PublicKeySize {
+		return key, fmt.Errorf("invalid public key %q: %d bytes", s, len(b))
+	}
+	copy(key[:], b)
+	return key, nil
+}
+
+func (k PublicKey) String() string {
+	return EncodeBase58(k[:])
+}
+
+func (k PublicKey) IsZero() bool {
+	return k == PublicKey{}
+}
+
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	key, err := PublicKeyFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*k = key
+	return nil
+}
+
+type Signature [SignatureSize]byte
+
+func SignatureFromBase58(s string) (Signature, error) {
+	var sig Signature
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return sig, fmt.Errorf("invalid signature %q: %w", s, err)
+	}
+	if len(b) != SignatureSize {
+		return sig, fmt.Errorf("invalid signature %q: %d bytes", s, len(b))
+	}
+	copy(sig[:], b)
+	return sig, nil
+}
+
+func (s Signature) String() string {
+	return EncodeBase58(s[:])
+}
+
+func (s Signature) IsZero() bool {
+	return s == Signature{}
+}
+
+func (s Signature) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Signature) UnmarshalText(text []byte) error {
+	sig, err := SignatureFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sig
+	return nil
+}
+
+// Hash is a 32-byte digest such as a blockhash.
+type Hash [32]byte
+
+func HashFromBase58(s string) (Hash, error) {
+	var hash Hash
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return hash, fmt.Errorf("invalid hash %q: %w", s, err)
+	}
+	if len(b) != len(hash) {
+		return hash, fmt.Errorf("invalid hash %q: %d bytes", s, len(b))
+	}
+	copy(hash[:], b)
+	return hash, nil
+}
+
+func (h Hash) String() string {
+	return EncodeBase58(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	hash, err := HashFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*h = hash
+	return nil
+}
@@ -0,0 +1,212 @@
+package trading
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// DepthSnapshot is the state of the top of a book as of Sequence.
+type DepthSnapshot struct {
+	Symbol    string       `json:"symbol"`
+	Sequence  uint64       `json:"sequence"`
+	Bids      []PriceLevel `json:"bids"`
+	Asks      []PriceLevel `json:"asks"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// BookUpdate carries the new aggregate size of every level changed by one
+// book operation. A level with zero size has been removed. Updates are
+// numbered consecutively after the snapshot returned by Subscribe, so a gap
+// in Sequence means updates were dropped and the consumer must resubscribe.
+type BookUpdate struct {
+	Symbol    string       `json:"symbol"`
+	Sequence  uint64       `json:"sequence"`
+	Bids      []PriceLevel `json:"bids"`
+	Asks      []PriceLevel `json:"asks"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// MarshalJSON encodes a level as the ["price", "amount"] pair used by the
+// order book websocket events.
+func (l PriceLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]string{
+		strconv.FormatFloat(l.Price, 'f', -1, 64),
+		strconv.FormatFloat(l.Size, 'f', -1, 64),
+	})
+}
+
+func (ob *OrderBook) BestBid() (PriceLevel, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if len(ob.bids) == 0 {
+		return PriceLevel{}, false
+	}
+	return ob.bids[0].view(), true
+}
+
+func (ob *OrderBook) BestAsk() (PriceLevel, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if len(ob.asks) == 0 {
+		return PriceLevel{}, false
+	}
+	return ob.asks[0].view(), true
+}
+
+// Spread returns best ask minus best bid; ok is false unless both sides
+// have liquidity.
+func (ob *OrderBook) Spread() (float64, bool) {
+	bid, ask, ok := ob.top()
+	if !ok {
+		return 0, false
+	}
+	return ask - bid, true
+}
+
+func (ob *OrderBook) Mid() (float64, bool) {
+	bid, ask, ok := ob.top()
+	if !ok {
+		return 0, false
+	}
+	return (bid + ask) / 2, true
+}
+
+// Depth returns up to levels price levels per side, best first. A
+// non-positive levels returns the whole book.
+func (ob *OrderBook) Depth(levels int) DepthSnapshot {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return ob.snapshot(levels)
+}
+
+// CumulativeDepth returns the total size available on side at prices at
+// least as good as price: bids at or above it, asks at or below it.
+func (ob *OrderBook) CumulativeDepth(side string, price float64) float64 {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	var total float64
+	if side == "buy" {
+		for _, level := range ob.bids {
+			if level.price < price {
+				break
+			}
+			total += level.size
+		}
+		return total
+	}
+	for _, level := range ob.asks {
+		if level.price > price {
+			break
+		}
+		total += level.size
+	}
+	return total
+}
+
+// Subscribe returns a full snapshot of the book together with a channel of
+// the updates that follow it. Updates are dropped for subscribers that fall
+// more than buffer updates behind; cancel releases the subscription.
+func (ob *OrderBook) Subscribe(buffer int) (DepthSnapshot, <-chan BookUpdate, func()) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	id := ob.nextSubID
+	ob.nextSubID++
+	ch := make(chan BookUpdate, buffer)
+	ob.subscribers[id] = ch
+
+	var cancelled bool
+	cancel := func() {
+		ob.mu.Lock()
+		defer ob.mu.Unlock()
+		if cancelled {
+			return
+		}
+		cancelled = true
+		delete(ob.subscribers, id)
+		close(ch)
+	}
+	return ob.snapshot(0), ch, cancel
+}
+
+func (ob *OrderBook) top() (float64, float64, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if len(ob.bids) == 0 || len(ob.asks) == 0 {
+		return 0, 0, false
+	}
+	return ob.bids[0].price, ob.asks[0].price, true
+}
+
+// snapshot must be called with ob.mu held.
+func (ob *OrderBook) snapshot(levels int) DepthSnapshot {
+	return DepthSnapshot{
+		Symbol:    ob.symbol,
+		Sequence:  ob.sequence,
+		Bids:      viewLevels(ob.bids, levels),
+		Asks:      viewLevels(ob.asks, levels),
+		Timestamp: time.Now(),
+	}
+}
+
+// flush publishes the levels touched since the last flush as one update.
+// It must be called with ob.mu held.
+func (ob *OrderBook) flush() {
+	if len(ob.changed) == 0 {
+		return
+	}
+
+	update := BookUpdate{
+		Symbol:    ob.symbol,
+		Bids:      make([]PriceLevel, 0),
+		Asks:      make([]PriceLevel, 0),
+		Timestamp: time.Now(),
+	}
+	seen := make(map[*priceLevel]bool, len(ob.changed))
+	for _, level := range ob.changed {
+		if seen[level] {
+			continue
+		}
+		seen[level] = true
+		if level.side == "buy" {
+			update.Bids = append(update.Bids, level.view())
+		} else {
+			update.Asks = append(update.Asks, level.view())
+		}
+	}
+	ob.changed = ob.changed[:0]
+
+	ob.sequence++
+	update.Sequence = ob.sequence
+	for _, ch := range ob.subscribers {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
+func (l *priceLevel) view() PriceLevel {
+	size := l.size
+	if l.queue.Len() == 0 || size < fillTolerance {
+		size = 0
+	}
+	return PriceLevel{Price: l.price, Size: size, OrdersNum: l.queue.Len()}
+}
+
+func viewLevels(levels []*priceLevel, n int) []PriceLevel {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	out := make([]PriceLevel, 0, n)
+	for _, level := range levels[:n] {
+		out = append(out, level.view())
+	}
+	return out
+}
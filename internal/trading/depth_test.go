@@ -0,0 +1,107 @@
+package trading
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDepthTestBook(t *testing.T) *OrderBook {
+	book := NewOrderBook("SOL/USDC")
+	orders := []Order{
+		{ID: "b1", Side: "buy", Price: 99, Amount: 1},
+		{ID: "b2", Side: "buy", Price: 98, Amount: 2},
+		{ID: "b3", Side: "buy", Price: 97, Amount: 3},
+		{ID: "a1", Side: "sell", Price: 101, Amount: 1.5},
+		{ID: "a2", Side: "sell", Price: 102, Amount: 2.5},
+	}
+	for _, order := range orders {
+		assert.NoError(t, book.AddOrder(order))
+	}
+	return book
+}
+
+func TestOrderBook_TopOfBook(t *testing.T) {
+	book := NewOrderBook("SOL/USDC")
+	_, ok := book.BestBid()
+	assert.False(t, ok)
+	_, ok = book.Spread()
+	assert.False(t, ok)
+
+	book = newDepthTestBook(t)
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, PriceLevel{Price: 99, Size: 1, OrdersNum: 1}, bid)
+
+	ask, ok := book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, PriceLevel{Price: 101, Size: 1.5, OrdersNum: 1}, ask)
+
+	spread, ok := book.Spread()
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, spread)
+
+	mid, ok := book.Mid()
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, mid)
+}
+
+func TestOrderBook_Depth(t *testing.T) {
+	book := newDepthTestBook(t)
+
+	snapshot := book.Depth(2)
+	assert.Equal(t, "SOL/USDC", snapshot.Symbol)
+	assert.Equal(t, uint64(5), snapshot.Sequence)
+	assert.Equal(t, []PriceLevel{{Price: 99, Size: 1, OrdersNum: 1}, {Price: 98, Size: 2, OrdersNum: 1}}, snapshot.Bids)
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 1.5, OrdersNum: 1}, {Price: 102, Size: 2.5, OrdersNum: 1}}, snapshot.Asks)
+	assert.Len(t, book.Depth(0).Bids, 3)
+
+	assert.Equal(t, 3.0, book.CumulativeDepth("buy", 98))
+	assert.Equal(t, 6.0, book.CumulativeDepth("buy", 90))
+	assert.Equal(t, 0.0, book.CumulativeDepth("buy", 100))
+	assert.Equal(t, 1.5, book.CumulativeDepth("sell", 101.5))
+	assert.Equal(t, 4.0, book.CumulativeDepth("sell", 200))
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"bids":[["99","1"],["98","2"]]`)
+	assert.Contains(t, string(data), `"asks":[["101","1.5"],["102","2.5"]]`)
+}
+
+func TestOrderBook_SubscribeDiffs(t *testing.T) {
+	book := newDepthTestBook(t)
+
+	snapshot, updates, cancel := book.Subscribe(10)
+	assert.Equal(t, uint64(5), snapshot.Sequence)
+	assert.Len(t, snapshot.Bids, 3)
+
+	assert.NoError(t, book.AddOrder(Order{ID: "b4", Side: "buy", Price: 99, Amount: 4}))
+	assert.NoError(t, book.RemoveOrder("b2"))
+	_, err := book.Match(Order{ID: "t1", Side: "buy", Price: 102, Amount: 2, OrderType: "limit"})
+	assert.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, uint64(6), update.Sequence)
+	assert.Equal(t, []PriceLevel{{Price: 99, Size: 5, OrdersNum: 2}}, update.Bids)
+	assert.Empty(t, update.Asks)
+
+	update = <-updates
+	assert.Equal(t, uint64(7), update.Sequence)
+	assert.Equal(t, []PriceLevel{{Price: 98, Size: 0, OrdersNum: 0}}, update.Bids)
+
+	update = <-updates
+	assert.Equal(t, uint64(8), update.Sequence)
+	assert.Empty(t, update.Bids)
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 0, OrdersNum: 0}, {Price: 102, Size: 2, OrdersNum: 1}}, update.Asks)
+
+	cancel()
+	cancel()
+	_, open := <-updates
+	assert.False(t, open)
+
+	// Rejected operations do not consume a sequence number
+	assert.Error(t, book.RemoveOrder("missing"))
+	assert.Equal(t, uint64(8), book.Depth(1).Sequence)
+}
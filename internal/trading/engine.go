@@ -1,53 +1,104 @@
 package trading
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/devinjacknz/devinsystem/internal/ai"
 	"github.com/devinjacknz/devinsystem/internal/exchange"
 	"github.com/devinjacknz/devinsystem/internal/monitoring"
+	"github.com/devinjacknz/devinsystem/internal/portfolio"
 	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/wallet"
 )
 
-type Order struct {
-	ID        string
-	Symbol    string
-	Side      string
-	Amount    float64
-	Price     float64
-	OrderType string
-	Exchange  string
-}
-
 type Engine interface {
 	PlaceOrder(order Order) error
 	CancelOrder(orderID string, symbol string) error
-	Start() error
+	GetOrder(orderID string) (Order, error)
+	ListOrders(filter OrderFilter) []Order
+	GetPositions() []portfolio.Position
+	GetOrderBook(symbol string, levels int) (DepthSnapshot, error)
+	RegisterStrategy(strategy Strategy, symbols ...string) error
+	EnableStrategy(name, symbol string) error
+	DisableStrategy(name, symbol string) error
+	Start(ctx context.Context) error
+	Stop() error
 }
 
+var (
+	ErrEngineRunning = errors.New("trading engine already running")
+	ErrEngineStopped = errors.New("trading engine is not running")
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultDrainTimeout = 30 * time.Second
+)
+
+// localMatchRoute marks fills from opposing orders crossed on the engine's
+// own book rather than on an exchange.
+const localMatchRoute = "local match"
+
 type tradingEngine struct {
 	mu          sync.RWMutex
 	orderBooks  map[string]*OrderBook
+	orders      map[string]*Order
+	positions   *portfolio.Tracker
+	strategies  *StrategySet
 	riskMgr     risk.Manager
 	exchanges   []exchange.Exchange
 	aiService   ai.Service
 	monitor     *monitoring.Service
+
+	pollInterval time.Duration
+	drainTimeout time.Duration
+	inflight     sync.WaitGroup
+	running      bool
+	stopping     bool
+	cancel       context.CancelFunc
+	done         chan struct{}
 }
 
 func NewTradingEngine(riskMgr risk.Manager, exchanges []exchange.Exchange, aiService ai.Service, monitor *monitoring.Service) *tradingEngine {
+	// Feed position sizes back into risk exposure when the manager supports it
+	exposure, _ := riskMgr.(portfolio.ExposureUpdater)
+
 	return &tradingEngine{
 		orderBooks: make(map[string]*OrderBook),
+		orders:     make(map[string]*Order),
+		positions:  portfolio.NewTracker(exposure),
+		strategies: NewStrategySet(),
 		riskMgr:    riskMgr,
 		exchanges:  exchanges,
 		aiService:  aiService,
 		monitor:    monitor,
+
+		pollInterval: defaultPollInterval,
+		drainTimeout: defaultDrainTimeout,
 	}
 }
 
 func (e *tradingEngine) PlaceOrder(order Order) error {
+	if err := e.beginOrder(); err != nil {
+		return err
+	}
+	defer e.inflight.Done()
+
+	if order.ID == "" {
+		order.ID = newOrderID()
+	}
+	if order.Wallet == "" {
+		order.Wallet = string(wallet.TradingWallet)
+	}
+	if err := e.trackOrder(order); err != nil {
+		return err
+	}
+
 	riskOrder := risk.Order{
 		Symbol:    order.Symbol,
 		Side:      order.Side,
@@ -56,42 +107,272 @@ func (e *tradingEngine) PlaceOrder(order Order) error {
 		OrderType: order.OrderType,
 	}
 	if err := e.riskMgr.ValidateOrder(riskOrder); err != nil {
-		return fmt.Errorf("risk validation failed: %w", err)
+		err = fmt.Errorf("risk validation failed: %w", err)
+		e.updateStatus(order.ID, OrderStatusRejected, err.Error())
+		return err
 	}
 
-	// Find the appropriate exchange
-	var selectedExchange exchange.Exchange
-	for _, ex := range e.exchanges {
-		if ex.Name() == order.Exchange {
-			selectedExchange = ex
-			break
-		}
-	}
+	selectedExchange := e.findExchange(order.Exchange)
 	if selectedExchange == nil {
-		return fmt.Errorf("exchange not found: %s", order.Exchange)
+		err := fmt.Errorf("exchange not found: %s", order.Exchange)
+		e.updateStatus(order.ID, OrderStatusRejected, err.Error())
+		return err
 	}
 
 	// Convert trading.Order to exchange.Order
-	if err := selectedExchange.ExecuteOrder(exchange.Order{
+	report, err := selectedExchange.ExecuteOrder(exchange.Order{
+		ID:        order.ID,
 		Symbol:    order.Symbol,
 		Side:      order.Side,
 		Amount:    order.Amount,
 		Price:     order.Price,
 		OrderType: order.OrderType,
-	}); err != nil {
-		return fmt.Errorf("failed to execute order: %w", err)
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to execute order: %w", err)
+		// A swap that may have landed stays submitted until reconciled
+		status := OrderStatusRejected
+		var submitErr *exchange.SubmitError
+		if errors.As(err, &submitErr) && submitErr.Pending() {
+			status = OrderStatusSubmitted
+		}
+		e.updateStatus(order.ID, status, err.Error())
+		return err
+	}
+
+	if err := e.updateStatus(order.ID, OrderStatusSubmitted, ""); err != nil {
+		return err
+	}
+
+	if report != nil && report.FilledAmount > 0 {
+		if err := e.RecordFill(order.ID, *report); err != nil {
+			return fmt.Errorf("failed to record fill: %w", err)
+		}
+	}
+
+	// Only the unfilled part of a limit order rests on the book, after
+	// crossing any opposing orders already there
+	e.mu.Lock()
+	placed := e.orders[order.ID]
+	if placed.Status.IsTerminal() || placed.OrderType == "market" {
+		e.mu.Unlock()
+		return nil
+	}
+	resting := placed.clone()
+	resting.Amount = placed.Remaining()
+	orderBook := e.orderBook(order.Symbol)
+	e.mu.Unlock()
+
+	trades, err := orderBook.Match(resting)
+	if err != nil {
+		return err
+	}
+	for _, trade := range trades {
+		if err := e.settleTrade(trade); err != nil {
+			return fmt.Errorf("failed to settle local match: %w", err)
+		}
+	}
+	return nil
+}
+
+// settleTrade fills both sides of a local match. The book already took the
+// amount off the maker and never rested it for the taker.
+func (e *tradingEngine) settleTrade(trade Trade) error {
+	for _, orderID := range []string{trade.TakerOrderID, trade.MakerOrderID} {
+		fill := Fill{
+			OrderID: orderID,
+			Amount:  trade.Amount,
+			Price:   trade.Price,
+			Route:   localMatchRoute,
+			Time:    trade.Time,
+		}
+		if err := e.recordFill(fill, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordFill applies an execution report to the order it belongs to.
+// Exchanges that settle asynchronously can call it after PlaceOrder returns.
+func (e *tradingEngine) RecordFill(orderID string, report exchange.ExecutionReport) error {
+	fill := Fill{
+		OrderID: orderID,
+		Amount:  report.FilledAmount,
+		Price:   report.AvgPrice,
+		Fee:     report.Fee,
+		TxID:    report.TxID,
+		Route:   report.Route,
+		Time:    report.Timestamp,
+	}
+	return e.recordFill(fill, true)
+}
+
+// recordFill applies fill to its order and position, taking it off the
+// order's resting size when reduceBook is set.
+func (e *tradingEngine) recordFill(fill Fill, reduceBook bool) error {
+	orderID := fill.OrderID
+	if fill.Time.IsZero() {
+		fill.Time = time.Now()
+	}
+
+	e.mu.Lock()
+	order, exists := e.orders[orderID]
+	if !exists {
+		e.mu.Unlock()
+		return ErrOrderNotFound
+	}
+	if err := order.applyFill(fill); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	filled := order.clone()
+	orderBook := e.orderBooks[order.Symbol]
+	e.mu.Unlock()
+
+	if reduceBook && orderBook != nil {
+		if err := orderBook.ReduceOrder(orderID, fill.Amount); err != nil && !errors.Is(err, ErrOrderNotFound) {
+			return fmt.Errorf("failed to update order book: %w", err)
+		}
+	}
+
+	e.monitor.LogTrade(filled.Symbol, filled.Side, fill.Amount, fill.Price)
+	e.monitor.LogOrder(filled.ID, filled.Symbol, string(filled.Status), "")
+
+	position, err := e.positions.ApplyFill(portfolio.Fill{
+		Wallet:   filled.Wallet,
+		Symbol:   filled.Symbol,
+		Exchange: filled.Exchange,
+		Side:     filled.Side,
+		Amount:   fill.Amount,
+		Price:    fill.Price,
+		Fee:      fill.Fee,
+		Time:     fill.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update position: %w", err)
 	}
+	e.monitor.LogExposure(position.Symbol, position.Size)
+	return nil
+}
+
+// RegisterStrategy adds a strategy for symbols, or every symbol when none
+// are given. Its intents are placed as orders on each market data update.
+func (e *tradingEngine) RegisterStrategy(strategy Strategy, symbols ...string) error {
+	return e.strategies.Register(strategy, symbols...)
+}
+
+// EnableStrategy turns a strategy on for symbol, or entirely when symbol is
+// empty.
+func (e *tradingEngine) EnableStrategy(name, symbol string) error {
+	return e.strategies.SetEnabled(name, symbol, true)
+}
+
+func (e *tradingEngine) DisableStrategy(name, symbol string) error {
+	return e.strategies.SetEnabled(name, symbol, false)
+}
+
+func (e *tradingEngine) ListStrategies() []StrategyInfo {
+	return e.strategies.List()
+}
 
+// runStrategies feeds one market update to the enabled strategies and places
+// the orders they ask for. Each order still goes through risk validation.
+func (e *tradingEngine) runStrategies(input StrategyInput) {
+	for _, intent := range e.strategies.Evaluate(input) {
+		e.monitor.LogIntent(intent.Strategy, intent.Symbol, intent.Side, intent.Amount, intent.Reason)
+
+		err := e.PlaceOrder(Order{
+			Symbol:    intent.Symbol,
+			Side:      intent.Side,
+			Amount:    intent.Amount,
+			Price:     intent.Price,
+			OrderType: intent.OrderType,
+			Exchange:  intent.Exchange,
+		})
+		if err != nil {
+			e.monitor.LogError(fmt.Sprintf("Strategy %s order for %s failed: %v", intent.Strategy, intent.Symbol, err))
+		}
+	}
+}
+
+// OrderBook returns the book of resting orders for symbol, creating an empty
+// one so consumers can subscribe before the first order arrives.
+func (e *tradingEngine) OrderBook(symbol string) *OrderBook {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	return e.orderBook(symbol)
+}
+
+// GetOrderBook returns the top levels of the book for symbol.
+func (e *tradingEngine) GetOrderBook(symbol string, levels int) (DepthSnapshot, error) {
+	e.mu.RLock()
+	orderBook, exists := e.orderBooks[symbol]
+	e.mu.RUnlock()
+
+	if !exists {
+		return DepthSnapshot{}, errors.New("market not found")
+	}
+	return orderBook.Depth(levels), nil
+}
 
-	orderBook, exists := e.orderBooks[order.Symbol]
+// orderBook must be called with e.mu held.
+func (e *tradingEngine) orderBook(symbol string) *OrderBook {
+	orderBook, exists := e.orderBooks[symbol]
 	if !exists {
-		orderBook = NewOrderBook()
-		e.orderBooks[order.Symbol] = orderBook
+		orderBook = NewOrderBook(symbol)
+		e.orderBooks[symbol] = orderBook
 	}
+	return orderBook
+}
 
-	return orderBook.AddOrder(order)
+func (e *tradingEngine) GetPositions() []portfolio.Position {
+	return e.positions.Positions()
+}
+
+// Positions exposes the tracker so callers can subscribe to position updates.
+func (e *tradingEngine) Positions() *portfolio.Tracker {
+	return e.positions
+}
+
+// markPositions revalues the open positions last traded on ex at its
+// current price.
+func (e *tradingEngine) markPositions(ex exchange.Exchange) {
+	for _, pos := range e.positions.Positions() {
+		if pos.IsFlat() || pos.Exchange != ex.Name() {
+			continue
+		}
+		price, err := ex.GetMarketPrice(pos.Symbol)
+		if err != nil {
+			e.monitor.LogError(fmt.Sprintf("Failed to mark position %s: %v", pos.Symbol, err))
+			continue
+		}
+		if err := e.positions.Mark(pos.Symbol, price); err != nil {
+			e.monitor.LogError(fmt.Sprintf("Failed to mark position %s: %v", pos.Symbol, err))
+		}
+	}
+}
+
+// markPosition revalues the open position in symbol, if it was last traded
+// on ex, at a streamed price.
+func (e *tradingEngine) markPosition(ex exchange.Exchange, symbol string, price float64) {
+	for _, pos := range e.positions.Positions() {
+		if pos.IsFlat() || pos.Exchange != ex.Name() || pos.Symbol != symbol {
+			continue
+		}
+		if err := e.positions.Mark(symbol, price); err != nil {
+			e.monitor.LogError(fmt.Sprintf("Failed to mark position %s: %v", symbol, err))
+		}
+	}
+}
+
+func (e *tradingEngine) findExchange(name string) exchange.Exchange {
+	for _, ex := range e.exchanges {
+		if ex.Name() == name {
+			return ex
+		}
+	}
+	return nil
 }
 
 func (e *tradingEngine) CancelOrder(orderID string, symbol string) error {
@@ -103,81 +384,329 @@ func (e *tradingEngine) CancelOrder(orderID string, symbol string) error {
 		return errors.New("market not found")
 	}
 
-	return orderBook.RemoveOrder(orderID)
+	if err := e.updateStatus(orderID, OrderStatusCancelled, "cancelled by request"); err != nil {
+		return err
+	}
+
+	// Market orders never rest, so there may be nothing to take off the book
+	if err := orderBook.RemoveOrder(orderID); err != nil && !errors.Is(err, ErrOrderNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (e *tradingEngine) GetOrder(orderID string) (Order, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	order, exists := e.orders[orderID]
+	if !exists {
+		return Order{}, ErrOrderNotFound
+	}
+	return order.clone(), nil
+}
+
+// ListOrders returns the tracked orders matching filter, oldest first.
+func (e *tradingEngine) ListOrders(filter OrderFilter) []Order {
+	e.mu.RLock()
+	orders := make([]Order, 0, len(e.orders))
+	for _, order := range e.orders {
+		if filter.Matches(*order) {
+			orders = append(orders, order.clone())
+		}
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].ID < orders[j].ID
+		}
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return orders
+}
+
+// trackOrder registers a new order in the pending state.
+func (e *tradingEngine) trackOrder(order Order) error {
+	order.Status = ""
+	order.Reason = ""
+	order.History = nil
+	if err := order.transition(OrderStatusPending, "", time.Now()); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.orders[order.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, order.ID)
+	}
+	e.orders[order.ID] = &order
+	e.monitor.LogOrder(order.ID, order.Symbol, string(order.Status), "")
+	return nil
+}
+
+func (e *tradingEngine) updateStatus(orderID string, status OrderStatus, reason string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, exists := e.orders[orderID]
+	if !exists {
+		return ErrOrderNotFound
+	}
+	if err := order.transition(status, reason, time.Now()); err != nil {
+		return err
+	}
+	e.monitor.LogOrder(order.ID, order.Symbol, string(status), reason)
+	return nil
 }
 
-func (e *tradingEngine) Start() error {
+// Start runs the engine until ctx is cancelled or Stop is called. On the way
+// out it stops market polling, waits for in-flight orders to finish and
+// flushes a summary of open orders and positions.
+func (e *tradingEngine) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return ErrEngineRunning
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e.running = true
+	e.stopping = false
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	e.mu.Unlock()
+	defer cancel()
+
 	// Log startup
 	e.monitor.LogSystem("Trading engine starting with multiple exchanges")
-	
+
 	// Initialize exchanges
 	for _, ex := range e.exchanges {
 		e.monitor.LogSystem(fmt.Sprintf("Initializing exchange: %s", ex.Name()))
 	}
-	
+
 	// Start market data monitoring
-	go e.monitorMarkets()
-	
-	// Keep the main thread running
-	select {}
+	var pollers sync.WaitGroup
+	e.monitorMarkets(ctx, &pollers)
+
+	<-ctx.Done()
+	e.monitor.LogSystem("Trading engine shutting down")
+
+	// Refuse new orders, then let the ones already submitted finish
+	e.mu.Lock()
+	e.stopping = true
+	e.mu.Unlock()
+
+	pollers.Wait()
+	err := e.drainOrders()
+	e.flushState()
+
+	e.mu.Lock()
+	e.running = false
+	close(e.done)
+	e.mu.Unlock()
+
+	e.monitor.LogSystem("Trading engine stopped")
+	return err
+}
+
+// Stop cancels a running engine and waits for Start to return.
+func (e *tradingEngine) Stop() error {
+	e.mu.RLock()
+	running, cancel, done := e.running, e.cancel, e.done
+	e.mu.RUnlock()
+
+	if !running {
+		return ErrEngineStopped
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// beginOrder registers an in-flight order, refusing it once shutdown has
+// started.
+func (e *tradingEngine) beginOrder() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopping {
+		return ErrEngineStopped
+	}
+	e.inflight.Add(1)
+	return nil
 }
 
-func (e *tradingEngine) monitorMarkets() {
+func (e *tradingEngine) drainOrders() error {
+	drained := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(e.drainTimeout):
+		e.monitor.LogError("Timed out waiting for in-flight orders")
+		return errors.New("timed out waiting for in-flight orders")
+	}
+}
+
+// flushState logs the orders still open and the positions held at shutdown
+// so they can be reconciled on the next start.
+func (e *tradingEngine) flushState() {
+	for _, order := range e.ListOrders(OrderFilter{}) {
+		if order.Status.IsTerminal() {
+			continue
+		}
+		e.monitor.LogOrder(order.ID, order.Symbol, string(order.Status), "open at shutdown")
+	}
+	for _, pos := range e.positions.Positions() {
+		if pos.IsFlat() {
+			continue
+		}
+		e.monitor.LogSystem(fmt.Sprintf("Position at shutdown: %s %s size %.8f entry %.8f pnl %.8f",
+			pos.Wallet, pos.Symbol, pos.Size, pos.EntryPrice, pos.PnL()))
+	}
+}
+
+func (e *tradingEngine) monitorMarkets(ctx context.Context, pollers *sync.WaitGroup) {
 	for _, ex := range e.exchanges {
-		go func(exchange exchange.Exchange) {
-			for {
-				time.Sleep(5 * time.Second)
-				
-				if exchange.Name() == "Jupiter" {
-					data, err := exchange.GetMarketData()
-					if err != nil {
-						e.monitor.LogError(fmt.Sprintf("Failed to get market data: %v", err))
-						continue
-					}
-
-					for _, d := range data {
-						e.monitor.LogJupiterSwap(d.Symbol, "USDC", d.Price, d.Volume, 0.1)
-						
-						aiData := ai.MarketData{
-							Symbol: d.Symbol,
-							Price:  d.Price,
-							Volume: d.Volume,
-							Trend:  "",
-						}
-						
-						analysis, err := e.aiService.AnalyzeMarket(aiData)
-						if err != nil {
-							e.monitor.LogError(fmt.Sprintf("Failed to analyze market data for %s: %v", d.Symbol, err))
-							continue
-						}
-						
-						e.monitor.LogAISignal(d.Symbol, analysis.Trend, analysis.Confidence)
-					}
-				} else {
-					data, err := exchange.GetMarketData()
-					if err != nil {
-						e.monitor.LogError(fmt.Sprintf("Failed to get market data from %s: %v", exchange.Name(), err))
-						continue
-					}
-
-					for _, d := range data {
-						aiData := ai.MarketData{
-							Symbol: d.Symbol,
-							Price:  d.Price,
-							Volume: d.Volume,
-							Trend:  "",
-						}
-						
-						analysis, err := e.aiService.AnalyzeMarket(aiData)
-						if err != nil {
-							e.monitor.LogError(fmt.Sprintf("Failed to analyze market data: %v", err))
-							continue
-						}
-						
-						e.monitor.LogAISignal(d.Symbol, analysis.Trend, analysis.Confidence)
-					}
-				}
+		pollers.Add(1)
+		go func(ex exchange.Exchange) {
+			defer pollers.Done()
+			if !e.streamMarkets(ctx, ex) {
+				e.pollMarkets(ctx, ex)
 			}
 		}(ex)
+
+		if source, ok := ex.(exchange.LaunchSource); ok {
+			launches, err := source.SubscribeLaunches(ctx)
+			if errors.Is(err, exchange.ErrStreamingUnsupported) {
+				continue
+			}
+			if err != nil {
+				e.monitor.LogError(fmt.Sprintf("Failed to subscribe to %s launches: %v", ex.Name(), err))
+				continue
+			}
+			pollers.Add(1)
+			go func(name string) {
+				defer pollers.Done()
+				e.watchLaunches(name, launches)
+			}(ex.Name())
+		}
+	}
+}
+
+// pollMarkets fetches ex's market data every poll interval until ctx is
+// cancelled.
+func (e *tradingEngine) pollMarkets(ctx context.Context, ex exchange.Exchange) {
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		e.markPositions(ex)
+		e.pollExchange(ex)
+	}
+}
+
+// streamMarkets handles ex's market events as they arrive until ctx is
+// cancelled. It returns false when ex does not stream or its stream ends
+// early, leaving the caller to poll instead.
+func (e *tradingEngine) streamMarkets(ctx context.Context, ex exchange.Exchange) bool {
+	streamer, ok := ex.(exchange.Streamer)
+	if !ok {
+		return false
+	}
+	events, err := streamer.Subscribe(ctx, nil)
+	if err != nil {
+		if !errors.Is(err, exchange.ErrStreamingUnsupported) {
+			e.monitor.LogError(fmt.Sprintf("Failed to stream market data from %s, polling instead: %v", ex.Name(), err))
+		}
+		return false
+	}
+
+	e.monitor.LogSystem(fmt.Sprintf("Streaming market data from %s", ex.Name()))
+	for event := range events {
+		switch event.Type {
+		case exchange.MarketEventPrice:
+			market := event.Market
+			e.markPosition(ex, market.Symbol, market.Price)
+			e.analyzeMarket(ex, &market)
+		case exchange.MarketEventGap:
+			// The stream resends its prices; positions outside it are
+			// marked here
+			e.monitor.LogSystem(fmt.Sprintf("Gap in %s market data (%s), resyncing", ex.Name(), event.Reason))
+			e.markPositions(ex)
+		}
 	}
+	if ctx.Err() != nil {
+		return true
+	}
+	e.monitor.LogError(fmt.Sprintf("Market data stream from %s ended, polling instead", ex.Name()))
+	return false
+}
+
+// watchLaunches hands every new token to the strategies as soon as it is
+// announced, without waiting for the next poll or an AI analysis.
+func (e *tradingEngine) watchLaunches(exchangeName string, launches <-chan exchange.TokenLaunch) {
+	for launch := range launches {
+		launch := launch
+		e.monitor.LogSystem(fmt.Sprintf("New token on %s: %s (%s) mint %s by %s",
+			exchangeName, launch.Name, launch.Symbol, launch.Mint, launch.Creator))
+		e.runStrategies(StrategyInput{
+			Exchange: exchangeName,
+			Market:   exchange.MarketData{Symbol: launch.Market(), Price: launch.Reserves.Price()},
+			Launch:   &launch,
+			Time:     launch.Timestamp,
+		})
+	}
+}
+
+func (e *tradingEngine) pollExchange(ex exchange.Exchange) {
+	data, err := ex.GetMarketData()
+	if err != nil {
+		e.monitor.LogError(fmt.Sprintf("Failed to get market data from %s: %v", ex.Name(), err))
+		// Markets that were fetched are still worth analysing
+		var partial *exchange.MarketDataError
+		if !errors.As(err, &partial) {
+			return
+		}
+	}
+
+	for _, d := range data {
+		e.analyzeMarket(ex, d)
+	}
+}
+
+// analyzeMarket runs one market update through the AI service and hands it
+// to the strategies.
+func (e *tradingEngine) analyzeMarket(ex exchange.Exchange, d *exchange.MarketData) {
+	if ex.Name() == "Jupiter" {
+		e.monitor.LogJupiterSwap(d.Symbol, "USDC", d.Price, d.Volume, 0.1)
+	}
+
+	aiData := ai.MarketData{
+		Symbol: d.Symbol,
+		Price:  d.Price,
+		Volume: d.Volume,
+		Trend:  "",
+	}
+
+	analysis, err := e.aiService.AnalyzeMarket(aiData)
+	if err != nil {
+		e.monitor.LogError(fmt.Sprintf("Failed to analyze market data for %s: %v", d.Symbol, err))
+		return
+	}
+
+	e.monitor.LogAISignal(d.Symbol, analysis.Trend, analysis.Confidence)
+	e.runStrategies(StrategyInput{Exchange: ex.Name(), Market: *d, Analysis: analysis, Time: time.Now()})
 }
@@ -0,0 +1,204 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTradingEngine_StartStop(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	polled := make(chan struct{}, 100)
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("GetMarketData").Return([]*exchange.MarketData{}, nil).Run(func(mock.Arguments) {
+		polled <- struct{}{}
+	})
+
+	engine := newTestEngine(mockRisk, jupiter)
+	engine.pollInterval = 5 * time.Millisecond
+
+	assert.ErrorIs(t, engine.Stop(), ErrEngineStopped)
+
+	result := make(chan error, 1)
+	go func() { result <- engine.Start(context.Background()) }()
+
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("engine never polled the exchange")
+	}
+	assert.ErrorIs(t, engine.Start(context.Background()), ErrEngineRunning)
+
+	assert.NoError(t, engine.Stop())
+	assert.NoError(t, <-result)
+
+	// Polling has stopped and new orders are refused
+	for len(polled) > 0 {
+		<-polled
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, polled)
+	assert.ErrorIs(t, engine.PlaceOrder(Order{ID: "late", Symbol: "SOL/USDC", Exchange: "Jupiter"}), ErrEngineStopped)
+}
+
+func TestTradingEngine_ContextCancelDrainsOrders(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+
+	executing := make(chan struct{})
+	release := make(chan struct{})
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("GetMarketData").Return([]*exchange.MarketData{}, nil)
+	jupiter.On("ExecuteOrder", mock.Anything).Return(&exchange.ExecutionReport{FilledAmount: 1, AvgPrice: 100}, nil).Run(func(mock.Arguments) {
+		close(executing)
+		<-release
+	})
+
+	engine := newTestEngine(mockRisk, jupiter)
+	engine.pollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- engine.Start(ctx) }()
+
+	placed := make(chan error, 1)
+	go func() {
+		placed <- engine.PlaceOrder(Order{ID: "swap", Symbol: "SOL/USDC", Side: "buy", Amount: 1, OrderType: "market", Exchange: "Jupiter"})
+	}()
+	<-executing
+
+	cancel()
+	select {
+	case <-result:
+		t.Fatal("engine stopped before the in-flight order finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-placed)
+	assert.NoError(t, <-result)
+
+	order, err := engine.GetOrder("swap")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusFilled, order.Status)
+}
+
+func TestTradingEngine_DrainTimeout(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+
+	executing := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.Anything).Return(nil, nil).Run(func(mock.Arguments) {
+		close(executing)
+		<-release
+	})
+
+	engine := newTestEngine(mockRisk, jupiter)
+	engine.pollInterval = time.Hour
+	engine.drainTimeout = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- engine.Start(ctx) }()
+	go engine.PlaceOrder(Order{ID: "stuck", Symbol: "SOL/USDC", Side: "buy", Amount: 1, OrderType: "market", Exchange: "Jupiter"})
+	<-executing
+
+	cancel()
+	assert.Error(t, <-result)
+}
+
+type streamingExchange struct {
+	*mockExchange
+	events chan exchange.MarketEvent
+}
+
+func (e *streamingExchange) Subscribe(ctx context.Context, symbols []string) (<-chan exchange.MarketEvent, error) {
+	return e.events, nil
+}
+
+type recordingStrategy struct {
+	seen chan StrategyInput
+}
+
+func (s *recordingStrategy) Name() string {
+	return "recorder"
+}
+
+func (s *recordingStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	s.seen <- input
+	return nil
+}
+
+func TestTradingEngine_StreamsMarketData(t *testing.T) {
+	pump := &streamingExchange{mockExchange: &mockExchange{name: "Pump.fun"}, events: make(chan exchange.MarketEvent)}
+	engine := newTestEngine(new(mockRiskManager), pump)
+	engine.pollInterval = 5 * time.Millisecond
+	strategy := &recordingStrategy{seen: make(chan StrategyInput, 10)}
+	assert.NoError(t, engine.RegisterStrategy(strategy))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- engine.Start(ctx) }()
+
+	// Events are handled as they arrive, without polling GetMarketData
+	pump.events <- exchange.MarketEvent{Type: exchange.MarketEventPrice, Market: exchange.MarketData{Symbol: "LIVE/SOL", Price: 3e-8}}
+	pump.events <- exchange.MarketEvent{Type: exchange.MarketEventHeartbeat}
+	pump.events <- exchange.MarketEvent{Type: exchange.MarketEventGap, Reason: "reconnected"}
+	pump.events <- exchange.MarketEvent{Type: exchange.MarketEventPrice, Market: exchange.MarketData{Symbol: "LIVE/SOL", Price: 4e-8}}
+	for _, price := range []float64{3e-8, 4e-8} {
+		select {
+		case input := <-strategy.seen:
+			assert.Equal(t, "Pump.fun", input.Exchange)
+			assert.Equal(t, exchange.MarketData{Symbol: "LIVE/SOL", Price: price}, input.Market)
+			assert.NotNil(t, input.Analysis)
+		case <-time.After(time.Second):
+			t.Fatal("strategy never saw the streamed price")
+		}
+	}
+	pump.AssertNotCalled(t, "GetMarketData")
+
+	// A stream that ends early falls back to polling
+	polled := make(chan struct{}, 100)
+	pump.On("GetMarketData").Return([]*exchange.MarketData{}, nil).Run(func(mock.Arguments) {
+		polled <- struct{}{}
+	})
+	close(pump.events)
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("engine never fell back to polling")
+	}
+
+	cancel()
+	assert.NoError(t, <-result)
+}
+
+func TestTradingEngine_PollsPartialMarketData(t *testing.T) {
+	jupiter := &mockExchange{name: "Jupiter"}
+	engine := newTestEngine(new(mockRiskManager), jupiter)
+	strategy := &recordingStrategy{seen: make(chan StrategyInput, 10)}
+	assert.NoError(t, engine.RegisterStrategy(strategy))
+
+	// Markets that were fetched are analysed despite the ones that failed
+	partial := &exchange.MarketDataError{Failed: map[string]error{"BONK": errors.New("rate limit exceeded")}}
+	jupiter.On("GetMarketData").Return([]*exchange.MarketData{{Symbol: "SOL", Price: 187.42}}, partial).Once()
+	engine.pollExchange(jupiter)
+	select {
+	case input := <-strategy.seen:
+		assert.Equal(t, exchange.MarketData{Symbol: "SOL", Price: 187.42}, input.Market)
+	case <-time.After(time.Second):
+		t.Fatal("strategy never saw the fetched market")
+	}
+
+	jupiter.On("GetMarketData").Return([]*exchange.MarketData{{Symbol: "SOL", Price: 187.42}}, errors.New("token list unavailable")).Once()
+	engine.pollExchange(jupiter)
+	assert.Empty(t, strategy.seen)
+}
@@ -0,0 +1,208 @@
+package trading
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type OrderStatus string
+
+// fillTolerance absorbs float rounding when comparing filled and ordered
+// amounts.
+const fillTolerance = 1e-9
+
+const (
+	OrderStatusPending         OrderStatus = "pending"
+	OrderStatusSubmitted       OrderStatus = "submitted"
+	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
+	OrderStatusFilled          OrderStatus = "filled"
+	OrderStatusRejected        OrderStatus = "rejected"
+	OrderStatusCancelled       OrderStatus = "cancelled"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrDuplicateOrder    = errors.New("order already exists")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrOverfill          = errors.New("fill exceeds remaining order amount")
+)
+
+// orderTransitions lists the statuses each status may move to. Filled,
+// rejected and cancelled are terminal.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending: {
+		OrderStatusSubmitted,
+		OrderStatusRejected,
+		OrderStatusCancelled,
+	},
+	OrderStatusSubmitted: {
+		OrderStatusPartiallyFilled,
+		OrderStatusFilled,
+		OrderStatusRejected,
+		OrderStatusCancelled,
+	},
+	OrderStatusPartiallyFilled: {
+		OrderStatusPartiallyFilled,
+		OrderStatusFilled,
+		OrderStatusCancelled,
+	},
+}
+
+func (s OrderStatus) IsTerminal() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type StatusChange struct {
+	Status OrderStatus
+	Reason string
+	Time   time.Time
+}
+
+// Fill is a single execution against an order, as reported by the exchange.
+type Fill struct {
+	OrderID string
+	Amount  float64
+	Price   float64
+	Fee     float64
+	TxID    string
+	Route   string
+	Time    time.Time
+}
+
+type Order struct {
+	ID        string
+	Symbol    string
+	Side      string
+	Amount    float64
+	Price     float64
+	OrderType string
+	Exchange  string
+	Wallet    string
+
+	Status    OrderStatus
+	Reason    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	History   []StatusChange
+
+	FilledAmount float64
+	AvgFillPrice float64
+	Fees         float64
+	Fills        []Fill
+}
+
+// Remaining returns the amount still to be filled.
+func (o Order) Remaining() float64 {
+	return o.Amount - o.FilledAmount
+}
+
+// transition moves the order to status, recording when and why it happened.
+func (o *Order) transition(status OrderStatus, reason string, at time.Time) error {
+	if o.Status == "" {
+		o.Status = OrderStatusPending
+		o.CreatedAt = at
+		o.UpdatedAt = at
+		o.History = append(o.History, StatusChange{Status: OrderStatusPending, Time: at})
+		if status == OrderStatusPending {
+			return nil
+		}
+	}
+
+	if !o.Status.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, status)
+	}
+
+	o.Status = status
+	o.Reason = reason
+	o.UpdatedAt = at
+	o.History = append(o.History, StatusChange{Status: status, Reason: reason, Time: at})
+	return nil
+}
+
+// applyFill records fill against the order and moves it to partially filled
+// or filled.
+func (o *Order) applyFill(fill Fill) error {
+	if fill.Amount <= 0 {
+		return fmt.Errorf("invalid fill amount: %f", fill.Amount)
+	}
+	if fill.Amount > o.Remaining()+fillTolerance {
+		return fmt.Errorf("%w: %f > %f", ErrOverfill, fill.Amount, o.Remaining())
+	}
+
+	status := OrderStatusPartiallyFilled
+	if o.Remaining()-fill.Amount <= fillTolerance {
+		status = OrderStatusFilled
+	}
+	if err := o.transition(status, "", fill.Time); err != nil {
+		return err
+	}
+
+	notional := o.AvgFillPrice*o.FilledAmount + fill.Price*fill.Amount
+	o.FilledAmount += fill.Amount
+	o.AvgFillPrice = notional / o.FilledAmount
+	o.Fees += fill.Fee
+	o.Fills = append(o.Fills, fill)
+	return nil
+}
+
+// StatusTime returns when the order first entered status.
+func (o Order) StatusTime(status OrderStatus) (time.Time, bool) {
+	for _, change := range o.History {
+		if change.Status == status {
+			return change.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (o Order) clone() Order {
+	c := o
+	c.History = append([]StatusChange(nil), o.History...)
+	c.Fills = append([]Fill(nil), o.Fills...)
+	return c
+}
+
+// OrderFilter selects orders in ListOrders. Empty fields match everything.
+type OrderFilter struct {
+	Symbol   string
+	Exchange string
+	Statuses []OrderStatus
+}
+
+func (f OrderFilter) Matches(order Order) bool {
+	if f.Symbol != "" && order.Symbol != f.Symbol {
+		return false
+	}
+	if f.Exchange != "" && order.Exchange != f.Exchange {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func newOrderID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("order-%d", time.Now().UnixNano())
+	}
+	return "order-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,378 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/ai"
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/devinjacknz/devinsystem/internal/monitoring"
+	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRiskManager struct {
+	mock.Mock
+}
+
+func (m *mockRiskManager) ValidateOrder(order risk.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+func (m *mockRiskManager) CheckExposure(symbol string) (float64, error) {
+	args := m.Called(symbol)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *mockRiskManager) UpdateStopLoss(symbol string, currentPrice float64) error {
+	args := m.Called(symbol, currentPrice)
+	return args.Error(0)
+}
+
+type mockExchange struct {
+	mock.Mock
+	name string
+}
+
+func (m *mockExchange) Name() string {
+	return m.name
+}
+
+func (m *mockExchange) GetMarketPrice(symbol string) (float64, error) {
+	args := m.Called(symbol)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *mockExchange) ExecuteOrder(order exchange.Order) (*exchange.ExecutionReport, error) {
+	args := m.Called(order)
+	report, _ := args.Get(0).(*exchange.ExecutionReport)
+	return report, args.Error(1)
+}
+
+func (m *mockExchange) GetMarketData() ([]*exchange.MarketData, error) {
+	args := m.Called()
+	return args.Get(0).([]*exchange.MarketData), args.Error(1)
+}
+
+func newTestEngine(riskMgr risk.Manager, exchanges ...exchange.Exchange) *tradingEngine {
+	return NewTradingEngine(riskMgr, exchanges, &ai.MockService{}, monitoring.NewService())
+}
+
+func TestOrderStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from OrderStatus
+		to   OrderStatus
+		want bool
+	}{
+		{OrderStatusPending, OrderStatusSubmitted, true},
+		{OrderStatusPending, OrderStatusRejected, true},
+		{OrderStatusPending, OrderStatusFilled, false},
+		{OrderStatusSubmitted, OrderStatusPartiallyFilled, true},
+		{OrderStatusSubmitted, OrderStatusFilled, true},
+		{OrderStatusPartiallyFilled, OrderStatusPartiallyFilled, true},
+		{OrderStatusPartiallyFilled, OrderStatusRejected, false},
+		{OrderStatusFilled, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusSubmitted, false},
+		{OrderStatusRejected, OrderStatusSubmitted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+
+	assert.True(t, OrderStatusFilled.IsTerminal())
+	assert.True(t, OrderStatusRejected.IsTerminal())
+	assert.True(t, OrderStatusCancelled.IsTerminal())
+	assert.False(t, OrderStatusSubmitted.IsTerminal())
+}
+
+func TestOrder_TransitionRecordsHistory(t *testing.T) {
+	var order Order
+	start := time.Now()
+
+	assert.NoError(t, order.transition(OrderStatusPending, "", start))
+	assert.NoError(t, order.transition(OrderStatusSubmitted, "", start.Add(time.Second)))
+	assert.NoError(t, order.transition(OrderStatusCancelled, "user", start.Add(2*time.Second)))
+
+	err := order.transition(OrderStatusFilled, "", start.Add(3*time.Second))
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	assert.Equal(t, OrderStatusCancelled, order.Status)
+	assert.Equal(t, "user", order.Reason)
+	assert.Equal(t, start, order.CreatedAt)
+	assert.Equal(t, start.Add(2*time.Second), order.UpdatedAt)
+	assert.Len(t, order.History, 3)
+
+	submittedAt, ok := order.StatusTime(OrderStatusSubmitted)
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Second), submittedAt)
+	_, ok = order.StatusTime(OrderStatusFilled)
+	assert.False(t, ok)
+}
+
+func TestTradingEngine_OrderLifecycle(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.MatchedBy(func(o risk.Order) bool { return o.Amount < 100 })).Return(nil)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(assert.AnError)
+
+	unconfirmed := &exchange.SubmitError{TxID: "sig1", Err: solana.ErrConfirmationTimeout}
+	failed := &exchange.SubmitError{TxID: "sig2", Err: &solana.TransactionError{Err: "InstructionError"}}
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.MatchedBy(func(o exchange.Order) bool { return o.ID == "o4" })).Return(nil, unconfirmed)
+	jupiter.On("ExecuteOrder", mock.MatchedBy(func(o exchange.Order) bool { return o.ID == "o5" })).Return(nil, failed)
+	jupiter.On("ExecuteOrder", mock.Anything).Return(nil, nil)
+	engine := newTestEngine(mockRisk, jupiter)
+
+	tests := []struct {
+		name       string
+		order      Order
+		wantErr    bool
+		wantStatus OrderStatus
+	}{
+		{
+			name:       "accepted order is submitted",
+			order:      Order{ID: "o1", Symbol: "SOL/USDC", Side: "buy", Amount: 1, Price: 100, OrderType: "limit", Exchange: "Jupiter"},
+			wantStatus: OrderStatusSubmitted,
+		},
+		{
+			name:       "risk failure rejects order",
+			order:      Order{ID: "o2", Symbol: "SOL/USDC", Side: "buy", Amount: 1000, Price: 100, OrderType: "limit", Exchange: "Jupiter"},
+			wantErr:    true,
+			wantStatus: OrderStatusRejected,
+		},
+		{
+			name:       "unknown exchange rejects order",
+			order:      Order{ID: "o3", Symbol: "BONK/USDC", Side: "sell", Amount: 1, Price: 0.1, OrderType: "limit", Exchange: "invalid"},
+			wantErr:    true,
+			wantStatus: OrderStatusRejected,
+		},
+		{
+			name:       "unconfirmed swap stays submitted",
+			order:      Order{ID: "o4", Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market", Exchange: "Jupiter"},
+			wantErr:    true,
+			wantStatus: OrderStatusSubmitted,
+		},
+		{
+			name:       "swap failed on chain rejects order",
+			order:      Order{ID: "o5", Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market", Exchange: "Jupiter"},
+			wantErr:    true,
+			wantStatus: OrderStatusRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.PlaceOrder(tt.order)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			order, err := engine.GetOrder(tt.order.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, order.Status)
+			_, ok := order.StatusTime(OrderStatusPending)
+			assert.True(t, ok)
+		})
+	}
+
+	// The swap's signature is kept for reconciling it later
+	order, err := engine.GetOrder("o4")
+	assert.NoError(t, err)
+	assert.Contains(t, order.Reason, "sig1")
+
+	err = engine.PlaceOrder(Order{ID: "o1", Symbol: "SOL/USDC", Exchange: "Jupiter"})
+	assert.ErrorIs(t, err, ErrDuplicateOrder)
+
+	assert.NoError(t, engine.CancelOrder("o1", "SOL/USDC"))
+	order, err = engine.GetOrder("o1")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusCancelled, order.Status)
+	assert.Error(t, engine.CancelOrder("o1", "SOL/USDC"))
+
+	_, err = engine.GetOrder("missing")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestTradingEngine_ListOrders(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.Anything).Return(nil, nil)
+	engine := newTestEngine(mockRisk, jupiter)
+
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "a", Symbol: "SOL/USDC", Side: "buy", Amount: 1, Price: 100, Exchange: "Jupiter"}))
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "b", Symbol: "BONK/USDC", Side: "buy", Amount: 1, Price: 0.1, Exchange: "Jupiter"}))
+	assert.Error(t, engine.PlaceOrder(Order{ID: "c", Symbol: "SOL/USDC", Side: "sell", Amount: 1, Price: 100, Exchange: "Pump.fun"}))
+
+	depth, err := engine.GetOrderBook("SOL/USDC", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceLevel{{Price: 100, Size: 1, OrdersNum: 1}}, depth.Bids)
+	assert.Empty(t, depth.Asks)
+	_, err = engine.GetOrderBook("WIF/USDC", 5)
+	assert.Error(t, err)
+
+	tests := []struct {
+		name   string
+		filter OrderFilter
+		want   []string
+	}{
+		{name: "no filter", filter: OrderFilter{}, want: []string{"a", "b", "c"}},
+		{name: "by symbol", filter: OrderFilter{Symbol: "SOL/USDC"}, want: []string{"a", "c"}},
+		{name: "by exchange", filter: OrderFilter{Exchange: "Pump.fun"}, want: []string{"c"}},
+		{name: "by status", filter: OrderFilter{Statuses: []OrderStatus{OrderStatusSubmitted}}, want: []string{"a", "b"}},
+		{name: "combined", filter: OrderFilter{Symbol: "SOL/USDC", Statuses: []OrderStatus{OrderStatusRejected}}, want: []string{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, order := range engine.ListOrders(tt.filter) {
+				ids = append(ids, order.ID)
+			}
+			assert.ElementsMatch(t, tt.want, ids)
+		})
+	}
+}
+
+func TestOrder_ApplyFill(t *testing.T) {
+	order := Order{ID: "o1", Amount: 10}
+	now := time.Now()
+	assert.NoError(t, order.transition(OrderStatusSubmitted, "", now))
+
+	assert.NoError(t, order.applyFill(Fill{Amount: 4, Price: 100, Fee: 0.4, Time: now}))
+	assert.Equal(t, OrderStatusPartiallyFilled, order.Status)
+	assert.InDelta(t, 6, order.Remaining(), 1e-9)
+
+	err := order.applyFill(Fill{Amount: 7, Price: 110, Time: now})
+	assert.ErrorIs(t, err, ErrOverfill)
+
+	assert.NoError(t, order.applyFill(Fill{Amount: 6, Price: 110, Fee: 0.6, Time: now}))
+	assert.Equal(t, OrderStatusFilled, order.Status)
+	assert.InDelta(t, 106, order.AvgFillPrice, 1e-9)
+	assert.InDelta(t, 1.0, order.Fees, 1e-9)
+	assert.Len(t, order.Fills, 2)
+}
+
+func TestTradingEngine_RecordsExecutionReports(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.MatchedBy(func(o exchange.Order) bool { return o.ID == "full" })).Return(&exchange.ExecutionReport{
+		OrderID:      "full",
+		FilledAmount: 2,
+		AvgPrice:     101,
+		Fee:          0.05,
+		TxID:         "5sig",
+		Route:        "Orca -> Raydium",
+	}, nil)
+	jupiter.On("ExecuteOrder", mock.MatchedBy(func(o exchange.Order) bool { return o.ID == "partial" })).Return(&exchange.ExecutionReport{
+		OrderID:      "partial",
+		FilledAmount: 1,
+		AvgPrice:     99,
+	}, nil)
+	engine := newTestEngine(mockRisk, jupiter)
+
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "full", Symbol: "SOL/USDC", Side: "buy", Amount: 2, Price: 100, OrderType: "market", Exchange: "Jupiter"}))
+	order, err := engine.GetOrder("full")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusFilled, order.Status)
+	assert.Equal(t, 2.0, order.FilledAmount)
+	assert.Equal(t, 101.0, order.AvgFillPrice)
+	assert.Equal(t, 0.05, order.Fees)
+	assert.Len(t, order.Fills, 1)
+	assert.Equal(t, "5sig", order.Fills[0].TxID)
+	assert.Equal(t, "Orca -> Raydium", order.Fills[0].Route)
+
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "partial", Symbol: "SOL/USDC", Side: "buy", Amount: 3, Price: 100, OrderType: "limit", Exchange: "Jupiter"}))
+	order, err = engine.GetOrder("partial")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusPartiallyFilled, order.Status)
+
+	assert.NoError(t, engine.RecordFill("partial", exchange.ExecutionReport{FilledAmount: 2, AvgPrice: 102}))
+	order, err = engine.GetOrder("partial")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusFilled, order.Status)
+	assert.InDelta(t, 101, order.AvgFillPrice, 1e-9)
+
+	assert.ErrorIs(t, engine.RecordFill("missing", exchange.ExecutionReport{FilledAmount: 1}), ErrOrderNotFound)
+
+	positions := engine.GetPositions()
+	assert.Len(t, positions, 1)
+	assert.Equal(t, "A", positions[0].Wallet)
+	assert.Equal(t, "Jupiter", positions[0].Exchange)
+	assert.InDelta(t, 5, positions[0].Size, 1e-9)
+	assert.InDelta(t, 101, positions[0].EntryPrice, 1e-9)
+	assert.InDelta(t, -0.05, positions[0].RealizedPnL, 1e-9)
+}
+
+func TestTradingEngine_CrossesRestingOrders(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.Anything).Return(nil, nil)
+	engine := newTestEngine(mockRisk, jupiter)
+
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "ask", Symbol: "SOL/USDC", Side: "sell", Amount: 2, Price: 100, OrderType: "limit", Exchange: "Jupiter"}))
+	assert.NoError(t, engine.PlaceOrder(Order{ID: "bid", Symbol: "SOL/USDC", Side: "buy", Amount: 3, Price: 101, OrderType: "limit", Exchange: "Jupiter"}))
+
+	// Both sides fill at the resting price and only the remainder rests
+	ask, err := engine.GetOrder("ask")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusFilled, ask.Status)
+	bid, err := engine.GetOrder("bid")
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusPartiallyFilled, bid.Status)
+	assert.Equal(t, 2.0, bid.FilledAmount)
+	assert.Equal(t, 100.0, bid.AvgFillPrice)
+	assert.Equal(t, localMatchRoute, bid.Fills[0].Route)
+
+	book := engine.OrderBook("SOL/USDC")
+	assert.False(t, book.IsCrossed())
+	depth := book.Depth(5)
+	assert.Empty(t, depth.Asks)
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 1, OrdersNum: 1}}, depth.Bids)
+
+	positions := engine.GetPositions()
+	assert.Len(t, positions, 1)
+	assert.InDelta(t, 0, positions[0].Size, 1e-9)
+}
+
+type exposureRiskManager struct {
+	mockRiskManager
+	exposures map[string]float64
+}
+
+func (m *exposureRiskManager) UpdateExposure(symbol string, amount float64) error {
+	m.exposures[symbol] = amount
+	return nil
+}
+
+func TestTradingEngine_FillsUpdateRiskExposure(t *testing.T) {
+	riskMgr := &exposureRiskManager{exposures: make(map[string]float64)}
+	riskMgr.On("ValidateOrder", mock.Anything).Return(nil)
+
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("ExecuteOrder", mock.Anything).Return(&exchange.ExecutionReport{FilledAmount: 3, AvgPrice: 100}, nil).Once()
+	jupiter.On("ExecuteOrder", mock.Anything).Return(&exchange.ExecutionReport{FilledAmount: 1, AvgPrice: 110}, nil).Once()
+	jupiter.On("GetMarketPrice", "SOL/USDC").Return(120.0, nil)
+	engine := newTestEngine(riskMgr, jupiter)
+
+	assert.NoError(t, engine.PlaceOrder(Order{Symbol: "SOL/USDC", Side: "buy", Amount: 3, OrderType: "market", Exchange: "Jupiter"}))
+	assert.Equal(t, 3.0, riskMgr.exposures["SOL/USDC"])
+
+	assert.NoError(t, engine.PlaceOrder(Order{Symbol: "SOL/USDC", Side: "sell", Amount: 1, OrderType: "market", Exchange: "Jupiter", Wallet: "B"}))
+	assert.Equal(t, 2.0, riskMgr.exposures["SOL/USDC"])
+
+	engine.markPositions(jupiter)
+	pos, ok := engine.Positions().Position("A", "SOL/USDC")
+	assert.True(t, ok)
+	assert.InDelta(t, 60, pos.UnrealizedPnL, 1e-9)
+}
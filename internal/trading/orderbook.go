@@ -1,70 +1,295 @@
 package trading
 
 import (
+	"container/list"
+	"errors"
+	"fmt"
 	"sort"
 	"sync"
+	"time"
 )
 
+var ErrInvalidOrder = errors.New("invalid order")
+
 type OrderBook struct {
 	mu     sync.RWMutex
-	orders map[string]Order
-	bids   []PriceLevel
-	asks   []PriceLevel
+	symbol string
+	orders map[string]*restingOrder
+	bids   []*priceLevel
+	asks   []*priceLevel
+
+	sequence    uint64
+	changed     []*priceLevel
+	subscribers map[int]chan BookUpdate
+	nextSubID   int
 }
 
+// PriceLevel is the aggregated view of all orders resting at one price.
 type PriceLevel struct {
 	Price     float64
 	Size      float64
 	OrdersNum int
 }
 
-func NewOrderBook() *OrderBook {
+// Trade is a match between an incoming order and a resting one. Price is
+// always the resting order's price.
+type Trade struct {
+	TakerOrderID string
+	MakerOrderID string
+	Side         string
+	Price        float64
+	Amount       float64
+	Time         time.Time
+}
+
+// priceLevel queues the orders at one price in arrival order.
+type priceLevel struct {
+	side  string
+	price float64
+	size  float64
+	queue *list.List
+}
+
+type restingOrder struct {
+	order Order
+	level *priceLevel
+	elem  *list.Element
+}
+
+func NewOrderBook(symbol string) *OrderBook {
 	return &OrderBook{
-		orders: make(map[string]Order),
-		bids:   make([]PriceLevel, 0),
-		asks:   make([]PriceLevel, 0),
+		symbol:      symbol,
+		orders:      make(map[string]*restingOrder),
+		bids:        make([]*priceLevel, 0),
+		asks:        make([]*priceLevel, 0),
+		subscribers: make(map[int]chan BookUpdate),
 	}
 }
 
+// AddOrder rests order on the book behind any orders already at its price,
+// even if it crosses the other side. order.Amount is taken as the open
+// quantity.
 func (ob *OrderBook) AddOrder(order Order) error {
 	ob.mu.Lock()
 	defer ob.mu.Unlock()
 
-	ob.orders[order.ID] = order
+	if err := ob.validate(order); err != nil {
+		return err
+	}
+	ob.rest(order)
+	ob.flush()
+	return nil
+}
 
-	level := PriceLevel{
-		Price:     order.Price,
-		Size:      order.Amount,
-		OrdersNum: 1,
+// Match is local matching: it crosses order against the book in price-time
+// priority and returns the resulting trades for the caller to settle. Any
+// unfilled remainder of a limit order rests on the book; the remainder of a
+// market order is discarded.
+func (ob *OrderBook) Match(order Order) ([]Trade, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if order.OrderType != "market" {
+		if err := ob.validate(order); err != nil {
+			return nil, err
+		}
+	} else if order.Amount <= 0 || (order.Side != "buy" && order.Side != "sell") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, order.ID)
 	}
+	defer ob.flush()
 
-	if order.Side == "buy" {
-		ob.bids = insertPriceLevel(ob.bids, level, true)
-	} else {
-		ob.asks = insertPriceLevel(ob.asks, level, false)
+	trades := ob.match(order, time.Now())
+	remaining := order.Amount - filledAmount(trades)
+	if order.OrderType != "market" && remaining > fillTolerance {
+		order.Amount = remaining
+		ob.rest(order)
 	}
+	return trades, nil
+}
 
+func (ob *OrderBook) RemoveOrder(orderID string) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	resting, exists := ob.orders[orderID]
+	if !exists {
+		return ErrOrderNotFound
+	}
+	ob.unlink(resting)
+	ob.flush()
 	return nil
 }
 
-func (ob *OrderBook) RemoveOrder(orderID string) error {
+// ReduceOrder takes amount off a resting order after a partial fill, removing
+// the order once nothing is left. The order keeps its place in the queue.
+func (ob *OrderBook) ReduceOrder(orderID string, amount float64) error {
 	ob.mu.Lock()
 	defer ob.mu.Unlock()
 
-	delete(ob.orders, orderID)
+	resting, exists := ob.orders[orderID]
+	if !exists {
+		return ErrOrderNotFound
+	}
+	if amount <= 0 {
+		return fmt.Errorf("%w: reduce amount %f", ErrInvalidOrder, amount)
+	}
+	ob.reduce(resting, amount)
+	ob.flush()
+	return nil
+}
+
+// Crosses reports whether order would trade against the opposite side of
+// the book at its limit price.
+func (ob *OrderBook) Crosses(order Order) bool {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return ob.crosses(order)
+}
+
+// IsCrossed reports whether the best bid is at or above the best ask.
+func (ob *OrderBook) IsCrossed() bool {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	return len(ob.bids) > 0 && len(ob.asks) > 0 && ob.bids[0].price >= ob.asks[0].price
+}
+
+func (ob *OrderBook) validate(order Order) error {
+	if order.ID == "" {
+		return fmt.Errorf("%w: missing order id", ErrInvalidOrder)
+	}
+	if order.Side != "buy" && order.Side != "sell" {
+		return fmt.Errorf("%w: invalid side %q", ErrInvalidOrder, order.Side)
+	}
+	if order.Amount <= 0 || order.Price <= 0 {
+		return fmt.Errorf("%w: amount %f price %f", ErrInvalidOrder, order.Amount, order.Price)
+	}
+	if _, exists := ob.orders[order.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, order.ID)
+	}
 	return nil
 }
 
-func insertPriceLevel(levels []PriceLevel, level PriceLevel, isBid bool) []PriceLevel {
+func (ob *OrderBook) crosses(order Order) bool {
+	if order.Side == "buy" {
+		return len(ob.asks) > 0 && (order.OrderType == "market" || ob.asks[0].price <= order.Price)
+	}
+	return len(ob.bids) > 0 && (order.OrderType == "market" || ob.bids[0].price >= order.Price)
+}
+
+// match must be called with ob.mu held.
+func (ob *OrderBook) match(order Order, at time.Time) []Trade {
+	var trades []Trade
+	remaining := order.Amount
+	for remaining > fillTolerance && ob.crosses(order) {
+		var level *priceLevel
+		if order.Side == "buy" {
+			level = ob.asks[0]
+		} else {
+			level = ob.bids[0]
+		}
+
+		maker := level.queue.Front().Value.(*restingOrder)
+		amount := maker.order.Amount
+		if amount > remaining {
+			amount = remaining
+		}
+
+		trades = append(trades, Trade{
+			TakerOrderID: order.ID,
+			MakerOrderID: maker.order.ID,
+			Side:         order.Side,
+			Price:        level.price,
+			Amount:       amount,
+			Time:         at,
+		})
+		remaining -= amount
+		ob.reduce(maker, amount)
+	}
+	return trades
+}
+
+// rest must be called with ob.mu held.
+func (ob *OrderBook) rest(order Order) {
+	isBid := order.Side == "buy"
+	levels := ob.asks
+	if isBid {
+		levels = ob.bids
+	}
+
 	idx := sort.Search(len(levels), func(i int) bool {
 		if isBid {
-			return levels[i].Price <= level.Price
+			return levels[i].price <= order.Price
 		}
-		return levels[i].Price >= level.Price
+		return levels[i].price >= order.Price
 	})
 
-	levels = append(levels, PriceLevel{})
-	copy(levels[idx+1:], levels[idx:])
-	levels[idx] = level
+	var level *priceLevel
+	if idx < len(levels) && levels[idx].price == order.Price {
+		level = levels[idx]
+	} else {
+		level = &priceLevel{side: order.Side, price: order.Price, queue: list.New()}
+		levels = append(levels, nil)
+		copy(levels[idx+1:], levels[idx:])
+		levels[idx] = level
+	}
+
+	resting := &restingOrder{order: order, level: level}
+	resting.elem = level.queue.PushBack(resting)
+	level.size += order.Amount
+	ob.orders[order.ID] = resting
+	ob.changed = append(ob.changed, level)
+
+	if isBid {
+		ob.bids = levels
+	} else {
+		ob.asks = levels
+	}
+}
+
+// reduce must be called with ob.mu held.
+func (ob *OrderBook) reduce(resting *restingOrder, amount float64) {
+	if amount >= resting.order.Amount-fillTolerance {
+		ob.unlink(resting)
+		return
+	}
+	resting.order.Amount -= amount
+	resting.level.size -= amount
+	ob.changed = append(ob.changed, resting.level)
+}
+
+// unlink must be called with ob.mu held.
+func (ob *OrderBook) unlink(resting *restingOrder) {
+	level := resting.level
+	level.queue.Remove(resting.elem)
+	level.size -= resting.order.Amount
+	delete(ob.orders, resting.order.ID)
+	ob.changed = append(ob.changed, level)
+
+	if level.queue.Len() > 0 {
+		return
+	}
+	if resting.order.Side == "buy" {
+		ob.bids = removeLevel(ob.bids, level)
+	} else {
+		ob.asks = removeLevel(ob.asks, level)
+	}
+}
+
+func removeLevel(levels []*priceLevel, level *priceLevel) []*priceLevel {
+	for i, l := range levels {
+		if l == level {
+			return append(levels[:i], levels[i+1:]...)
+		}
+	}
 	return levels
 }
+
+func filledAmount(trades []Trade) float64 {
+	var total float64
+	for _, trade := range trades {
+		total += trade.Amount
+	}
+	return total
+}
@@ -0,0 +1,98 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBook_AggregatesLevels(t *testing.T) {
+	book := NewOrderBook("SOL/USDC")
+
+	orders := []Order{
+		{ID: "b1", Side: "buy", Price: 99, Amount: 1},
+		{ID: "b2", Side: "buy", Price: 100, Amount: 2},
+		{ID: "b3", Side: "buy", Price: 99, Amount: 3},
+		{ID: "a1", Side: "sell", Price: 101, Amount: 4},
+		{ID: "a2", Side: "sell", Price: 102, Amount: 5},
+		{ID: "a3", Side: "sell", Price: 101, Amount: 6},
+	}
+	for _, order := range orders {
+		assert.NoError(t, book.AddOrder(order))
+	}
+
+	assert.Equal(t, []PriceLevel{{Price: 100, Size: 2, OrdersNum: 1}, {Price: 99, Size: 4, OrdersNum: 2}}, viewLevels(book.bids, 0))
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 10, OrdersNum: 2}, {Price: 102, Size: 5, OrdersNum: 1}}, viewLevels(book.asks, 0))
+
+	assert.ErrorIs(t, book.AddOrder(Order{ID: "b1", Side: "buy", Price: 98, Amount: 1}), ErrDuplicateOrder)
+	assert.ErrorIs(t, book.AddOrder(Order{ID: "x", Side: "hold", Price: 98, Amount: 1}), ErrInvalidOrder)
+	assert.ErrorIs(t, book.AddOrder(Order{ID: "y", Side: "buy", Price: 0, Amount: 1}), ErrInvalidOrder)
+}
+
+func TestOrderBook_RemoveAndReduce(t *testing.T) {
+	book := NewOrderBook("SOL/USDC")
+	assert.NoError(t, book.AddOrder(Order{ID: "b1", Side: "buy", Price: 100, Amount: 2}))
+	assert.NoError(t, book.AddOrder(Order{ID: "b2", Side: "buy", Price: 100, Amount: 3}))
+	assert.NoError(t, book.AddOrder(Order{ID: "b3", Side: "buy", Price: 99, Amount: 1}))
+
+	assert.NoError(t, book.ReduceOrder("b2", 1))
+	assert.Equal(t, []PriceLevel{{Price: 100, Size: 4, OrdersNum: 2}, {Price: 99, Size: 1, OrdersNum: 1}}, viewLevels(book.bids, 0))
+
+	assert.NoError(t, book.RemoveOrder("b1"))
+	assert.Equal(t, []PriceLevel{{Price: 100, Size: 2, OrdersNum: 1}, {Price: 99, Size: 1, OrdersNum: 1}}, viewLevels(book.bids, 0))
+
+	assert.NoError(t, book.ReduceOrder("b2", 2))
+	assert.Equal(t, []PriceLevel{{Price: 99, Size: 1, OrdersNum: 1}}, viewLevels(book.bids, 0))
+
+	assert.ErrorIs(t, book.RemoveOrder("b2"), ErrOrderNotFound)
+	assert.ErrorIs(t, book.ReduceOrder("missing", 1), ErrOrderNotFound)
+	assert.ErrorIs(t, book.ReduceOrder("b3", 0), ErrInvalidOrder)
+}
+
+func TestOrderBook_CrossingDetection(t *testing.T) {
+	book := NewOrderBook("SOL/USDC")
+	assert.NoError(t, book.AddOrder(Order{ID: "b1", Side: "buy", Price: 100, Amount: 1}))
+	assert.NoError(t, book.AddOrder(Order{ID: "a1", Side: "sell", Price: 101, Amount: 1}))
+
+	assert.False(t, book.IsCrossed())
+	assert.True(t, book.Crosses(Order{Side: "buy", Price: 101}))
+	assert.False(t, book.Crosses(Order{Side: "buy", Price: 100.5}))
+	assert.True(t, book.Crosses(Order{Side: "sell", Price: 100}))
+	assert.True(t, book.Crosses(Order{Side: "sell", OrderType: "market"}))
+
+	// AddOrder never matches, so a crossing order simply rests
+	assert.NoError(t, book.AddOrder(Order{ID: "b2", Side: "buy", Price: 102, Amount: 1}))
+	assert.True(t, book.IsCrossed())
+}
+
+func TestOrderBook_MatchPriceTimePriority(t *testing.T) {
+	book := NewOrderBook("SOL/USDC")
+	assert.NoError(t, book.AddOrder(Order{ID: "a1", Side: "sell", Price: 101, Amount: 2}))
+	assert.NoError(t, book.AddOrder(Order{ID: "a2", Side: "sell", Price: 100, Amount: 1}))
+	assert.NoError(t, book.AddOrder(Order{ID: "a3", Side: "sell", Price: 101, Amount: 2}))
+
+	trades, err := book.Match(Order{ID: "t1", Side: "buy", Price: 101, Amount: 4, OrderType: "limit"})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 3)
+	assert.Equal(t, "a2", trades[0].MakerOrderID)
+	assert.Equal(t, 100.0, trades[0].Price)
+	assert.Equal(t, "a1", trades[1].MakerOrderID)
+	assert.Equal(t, 2.0, trades[1].Amount)
+	assert.Equal(t, "a3", trades[2].MakerOrderID)
+	assert.Equal(t, 1.0, trades[2].Amount)
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 1, OrdersNum: 1}}, viewLevels(book.asks, 0))
+
+	// Limit remainder rests, market remainder is dropped
+	trades, err = book.Match(Order{ID: "t2", Side: "buy", Price: 101, Amount: 3, OrderType: "limit"})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+	assert.Empty(t, book.asks)
+	assert.Equal(t, []PriceLevel{{Price: 101, Size: 2, OrdersNum: 1}}, viewLevels(book.bids, 0))
+
+	trades, err = book.Match(Order{ID: "t3", Side: "sell", Amount: 5, OrderType: "market"})
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+	assert.Equal(t, 2.0, trades[0].Amount)
+	assert.Empty(t, book.bids)
+	assert.Empty(t, book.asks)
+}
@@ -0,0 +1,35 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTradingEngine_ReplayedJupiterSession(t *testing.T) {
+	client, err := exchange.NewReplayClient("../exchange/testdata/jupiter.json")
+	assert.NoError(t, err)
+	jupiter := exchange.NewJupiterDEXWithClient(client)
+
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+	engine := newTestEngine(mockRisk, jupiter)
+	assert.NoError(t, engine.RegisterStrategy(&fixedStrategy{
+		name:    "take_profit",
+		intents: []OrderIntent{{Side: "sell", Amount: 1, OrderType: "market"}},
+	}, "SOL"))
+
+	engine.pollExchange(jupiter)
+
+	// Without a submitter the swap is never sent, so nothing fills
+	orders := engine.ListOrders(OrderFilter{})
+	assert.Len(t, orders, 1)
+	assert.Equal(t, OrderStatusRejected, orders[0].Status)
+	assert.Equal(t, "SOL", orders[0].Symbol)
+	assert.Equal(t, "Jupiter", orders[0].Exchange)
+	assert.Contains(t, orders[0].Reason, exchange.ErrNoSubmitter.Error())
+	assert.Empty(t, orders[0].Fills)
+	assert.Empty(t, engine.GetPositions())
+}
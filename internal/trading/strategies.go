@@ -0,0 +1,266 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// signalLatch remembers the last side each symbol signalled so strategies
+// only emit an intent when their view changes, not on every tick.
+type signalLatch struct {
+	mu   sync.Mutex
+	last map[string]string
+}
+
+func (l *signalLatch) change(symbol, side string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.last == nil {
+		l.last = make(map[string]string)
+	}
+	if l.last[symbol] == side {
+		return false
+	}
+	l.last[symbol] = side
+	return side != ""
+}
+
+// priceHistory keeps the most recent prices per symbol.
+type priceHistory struct {
+	mu     sync.Mutex
+	size   int
+	prices map[string][]float64
+}
+
+func (h *priceHistory) add(symbol string, price float64) []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.prices == nil {
+		h.prices = make(map[string][]float64)
+	}
+	prices := append(h.prices[symbol], price)
+	if len(prices) > h.size {
+		prices = prices[len(prices)-h.size:]
+	}
+	h.prices[symbol] = prices
+	return append([]float64(nil), prices...)
+}
+
+func marketIntent(input StrategyInput, side string, amount float64, reason string) OrderIntent {
+	return OrderIntent{
+		Symbol:    input.Market.Symbol,
+		Side:      side,
+		Amount:    amount,
+		Price:     input.Market.Price,
+		OrderType: "market",
+		Reason:    reason,
+	}
+}
+
+// MomentumStrategy buys when price has risen by more than threshold over the
+// last lookback observations and sells when it has fallen by as much.
+type MomentumStrategy struct {
+	name      string
+	lookback  int
+	threshold float64
+	amount    float64
+	history   priceHistory
+	latch     signalLatch
+}
+
+func NewMomentumStrategy(name string, lookback int, threshold, amount float64) *MomentumStrategy {
+	if name == "" {
+		name = "momentum"
+	}
+	if lookback < 1 {
+		lookback = 1
+	}
+	return &MomentumStrategy{
+		name:      name,
+		lookback:  lookback,
+		threshold: threshold,
+		amount:    amount,
+		history:   priceHistory{size: lookback + 1},
+	}
+}
+
+func (s *MomentumStrategy) Name() string {
+	return s.name
+}
+
+func (s *MomentumStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	prices := s.history.add(input.Market.Symbol, input.Market.Price)
+	if len(prices) <= s.lookback || prices[0] <= 0 {
+		return nil
+	}
+
+	change := (prices[len(prices)-1] - prices[0]) / prices[0]
+	side := ""
+	switch {
+	case change >= s.threshold:
+		side = "buy"
+	case change <= -s.threshold:
+		side = "sell"
+	}
+	if !s.latch.change(input.Market.Symbol, side) {
+		return nil
+	}
+	return []OrderIntent{marketIntent(input, side, s.amount, fmt.Sprintf("momentum %.2f%%", change*100))}
+}
+
+// MeanReversionStrategy trades against prices that stray more than zScore
+// standard deviations from their moving average over window observations.
+type MeanReversionStrategy struct {
+	name    string
+	window  int
+	zScore  float64
+	amount  float64
+	history priceHistory
+	latch   signalLatch
+}
+
+func NewMeanReversionStrategy(name string, window int, zScore, amount float64) *MeanReversionStrategy {
+	if name == "" {
+		name = "mean_reversion"
+	}
+	if window < 2 {
+		window = 2
+	}
+	return &MeanReversionStrategy{
+		name:    name,
+		window:  window,
+		zScore:  zScore,
+		amount:  amount,
+		history: priceHistory{size: window},
+	}
+}
+
+func (s *MeanReversionStrategy) Name() string {
+	return s.name
+}
+
+func (s *MeanReversionStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	prices := s.history.add(input.Market.Symbol, input.Market.Price)
+	if len(prices) < s.window {
+		return nil
+	}
+
+	var mean float64
+	for _, p := range prices {
+		mean += p
+	}
+	mean /= float64(len(prices))
+
+	var variance float64
+	for _, p := range prices {
+		variance += (p - mean) * (p - mean)
+	}
+	std := math.Sqrt(variance / float64(len(prices)))
+	if std == 0 {
+		s.latch.change(input.Market.Symbol, "")
+		return nil
+	}
+
+	z := (input.Market.Price - mean) / std
+	side := ""
+	switch {
+	case z <= -s.zScore:
+		side = "buy"
+	case z >= s.zScore:
+		side = "sell"
+	}
+	if !s.latch.change(input.Market.Symbol, side) {
+		return nil
+	}
+	return []OrderIntent{marketIntent(input, side, s.amount, fmt.Sprintf("z-score %.2f", z))}
+}
+
+// AIFollowStrategy trades in the direction of the AI analysis when its
+// confidence is at least minConfidence. Explicit BUY/SELL signals take
+// precedence over the overall trend.
+type AIFollowStrategy struct {
+	name          string
+	minConfidence float64
+	amount        float64
+	latch         signalLatch
+}
+
+func NewAIFollowStrategy(name string, minConfidence, amount float64) *AIFollowStrategy {
+	if name == "" {
+		name = "ai_follow"
+	}
+	return &AIFollowStrategy{
+		name:          name,
+		minConfidence: minConfidence,
+		amount:        amount,
+	}
+}
+
+func (s *AIFollowStrategy) Name() string {
+	return s.name
+}
+
+func (s *AIFollowStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	if input.Analysis == nil {
+		return nil
+	}
+
+	side, confidence := "", 0.0
+	for _, signal := range input.Analysis.Signals {
+		action := strings.ToLower(signal.Action)
+		if (action == "buy" || action == "sell") && signal.Confidence > confidence {
+			side, confidence = action, signal.Confidence
+		}
+	}
+	if side == "" {
+		switch strings.ToLower(input.Analysis.Trend) {
+		case "bullish", "up":
+			side = "buy"
+		case "bearish", "down":
+			side = "sell"
+		}
+		confidence = input.Analysis.Confidence
+	}
+	if confidence < s.minConfidence {
+		side = ""
+	}
+
+	if !s.latch.change(input.Market.Symbol, side) {
+		return nil
+	}
+	return []OrderIntent{marketIntent(input, side, s.amount, fmt.Sprintf("ai %s %.2f", side, confidence))}
+}
+
+// NewStrategy builds one of the built-in strategies by kind. Missing params
+// fall back to the defaults listed below.
+//
+//	momentum:       lookback (10), threshold (0.02)
+//	mean_reversion: window (20), z_score (2)
+//	ai_follow:      min_confidence (0.7)
+func NewStrategy(kind, name string, amount float64, params map[string]float64) (Strategy, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid strategy amount: %f", amount)
+	}
+
+	param := func(key string, def float64) float64 {
+		if v, ok := params[key]; ok {
+			return v
+		}
+		return def
+	}
+
+	switch kind {
+	case "momentum":
+		return NewMomentumStrategy(name, int(param("lookback", 10)), param("threshold", 0.02), amount), nil
+	case "mean_reversion":
+		return NewMeanReversionStrategy(name, int(param("window", 20)), param("z_score", 2), amount), nil
+	case "ai_follow":
+		return NewAIFollowStrategy(name, param("min_confidence", 0.7), amount), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy type: %s", kind)
+	}
+}
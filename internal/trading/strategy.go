@@ -0,0 +1,206 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/ai"
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+)
+
+var (
+	ErrStrategyNotFound = errors.New("strategy not found")
+	ErrStrategyExists   = errors.New("strategy already registered")
+)
+
+// StrategyInput is one market observation handed to every strategy enabled
+// for its symbol. Analysis may be nil when the AI service is unavailable.
+type StrategyInput struct {
+	Exchange string
+	Market   exchange.MarketData
+	Analysis *ai.Analysis
+	// Launch is set when the observation is a newly created token, priced
+	// at its launch reserves, rather than a poll.
+	Launch *exchange.TokenLaunch
+	Time   time.Time
+}
+
+// OrderIntent is a strategy's request to trade. The engine turns it into an
+// order, which still has to pass risk validation. An empty Exchange routes
+// the order to the exchange the market data came from.
+type OrderIntent struct {
+	Strategy  string
+	Symbol    string
+	Side      string
+	Amount    float64
+	Price     float64
+	OrderType string
+	Exchange  string
+	Reason    string
+}
+
+type Strategy interface {
+	Name() string
+	Evaluate(input StrategyInput) []OrderIntent
+}
+
+// StrategyInfo describes a registration for listing.
+type StrategyInfo struct {
+	Name     string
+	Symbols  []string
+	Enabled  bool
+	Disabled []string
+}
+
+type strategyRegistration struct {
+	strategy Strategy
+	// symbols is nil when the strategy trades every symbol.
+	symbols  map[string]bool
+	enabled  bool
+	disabled map[string]bool
+}
+
+func (r *strategyRegistration) active(symbol string) bool {
+	if !r.enabled || r.disabled[symbol] {
+		return false
+	}
+	return r.symbols == nil || r.symbols[symbol]
+}
+
+// StrategySet holds the registered strategies and which symbols each one is
+// currently enabled for. It is safe for concurrent use.
+type StrategySet struct {
+	mu            sync.RWMutex
+	registrations map[string]*strategyRegistration
+}
+
+func NewStrategySet() *StrategySet {
+	return &StrategySet{
+		registrations: make(map[string]*strategyRegistration),
+	}
+}
+
+// Register adds strategy for the given symbols, or for every symbol when
+// none are given. New registrations start enabled.
+func (s *StrategySet) Register(strategy Strategy, symbols ...string) error {
+	if strategy == nil {
+		return errors.New("strategy is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.registrations[strategy.Name()]; exists {
+		return fmt.Errorf("%w: %s", ErrStrategyExists, strategy.Name())
+	}
+
+	reg := &strategyRegistration{
+		strategy: strategy,
+		enabled:  true,
+		disabled: make(map[string]bool),
+	}
+	if len(symbols) > 0 {
+		reg.symbols = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			reg.symbols[symbol] = true
+		}
+	}
+	s.registrations[strategy.Name()] = reg
+	return nil
+}
+
+func (s *StrategySet) Unregister(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.registrations[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrStrategyNotFound, name)
+	}
+	delete(s.registrations, name)
+	return nil
+}
+
+// SetEnabled turns strategy name on or off for symbol, or as a whole when
+// symbol is empty.
+func (s *StrategySet) SetEnabled(name, symbol string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reg, exists := s.registrations[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrStrategyNotFound, name)
+	}
+
+	if symbol == "" {
+		reg.enabled = enabled
+		return nil
+	}
+	if reg.symbols != nil && !reg.symbols[symbol] {
+		return fmt.Errorf("strategy %s is not registered for %s", name, symbol)
+	}
+	if enabled {
+		delete(reg.disabled, symbol)
+	} else {
+		reg.disabled[symbol] = true
+	}
+	return nil
+}
+
+// Evaluate runs every strategy active for the input's symbol and collects
+// their intents, stamping each with the strategy that produced it.
+func (s *StrategySet) Evaluate(input StrategyInput) []OrderIntent {
+	s.mu.RLock()
+	active := make([]Strategy, 0, len(s.registrations))
+	for _, reg := range s.registrations {
+		if reg.active(input.Market.Symbol) {
+			active = append(active, reg.strategy)
+		}
+	}
+	s.mu.RUnlock()
+
+	// Evaluate in a stable order so results are reproducible
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Name() < active[j].Name()
+	})
+
+	var intents []OrderIntent
+	for _, strategy := range active {
+		for _, intent := range strategy.Evaluate(input) {
+			intent.Strategy = strategy.Name()
+			if intent.Symbol == "" {
+				intent.Symbol = input.Market.Symbol
+			}
+			if intent.Exchange == "" {
+				intent.Exchange = input.Exchange
+			}
+			intents = append(intents, intent)
+		}
+	}
+	return intents
+}
+
+func (s *StrategySet) List() []StrategyInfo {
+	s.mu.RLock()
+	infos := make([]StrategyInfo, 0, len(s.registrations))
+	for name, reg := range s.registrations {
+		info := StrategyInfo{Name: name, Enabled: reg.enabled}
+		for symbol := range reg.symbols {
+			info.Symbols = append(info.Symbols, symbol)
+		}
+		for symbol := range reg.disabled {
+			info.Disabled = append(info.Disabled, symbol)
+		}
+		sort.Strings(info.Symbols)
+		sort.Strings(info.Disabled)
+		infos = append(infos, info)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
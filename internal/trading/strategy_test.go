@@ -0,0 +1,254 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/ai"
+	"github.com/devinjacknz/devinsystem/internal/exchange"
+	"github.com/devinjacknz/devinsystem/internal/risk"
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedStrategy struct {
+	name    string
+	intents []OrderIntent
+}
+
+func (s *fixedStrategy) Name() string {
+	return s.name
+}
+
+func (s *fixedStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	return s.intents
+}
+
+func priceInput(symbol string, price float64) StrategyInput {
+	return StrategyInput{Exchange: "Jupiter", Market: exchange.MarketData{Symbol: symbol, Price: price}}
+}
+
+func TestStrategySet_EnableDisable(t *testing.T) {
+	set := NewStrategySet()
+	buy := &fixedStrategy{name: "buyer", intents: []OrderIntent{{Side: "buy", Amount: 1}}}
+	sell := &fixedStrategy{name: "seller", intents: []OrderIntent{{Side: "sell", Amount: 2}}}
+
+	assert.NoError(t, set.Register(buy))
+	assert.NoError(t, set.Register(sell, "SOL/USDC"))
+	assert.ErrorIs(t, set.Register(buy), ErrStrategyExists)
+
+	intents := set.Evaluate(priceInput("SOL/USDC", 100))
+	assert.Len(t, intents, 2)
+	assert.Equal(t, "buyer", intents[0].Strategy)
+	assert.Equal(t, "SOL/USDC", intents[0].Symbol)
+	assert.Equal(t, "Jupiter", intents[0].Exchange)
+	assert.Equal(t, "seller", intents[1].Strategy)
+
+	intents = set.Evaluate(priceInput("BONK/USDC", 0.1))
+	assert.Len(t, intents, 1)
+	assert.Equal(t, "buyer", intents[0].Strategy)
+
+	assert.NoError(t, set.SetEnabled("buyer", "SOL/USDC", false))
+	assert.Len(t, set.Evaluate(priceInput("SOL/USDC", 100)), 1)
+	assert.Len(t, set.Evaluate(priceInput("BONK/USDC", 0.1)), 1)
+
+	assert.NoError(t, set.SetEnabled("seller", "", false))
+	assert.Empty(t, set.Evaluate(priceInput("SOL/USDC", 100)))
+
+	assert.NoError(t, set.SetEnabled("buyer", "SOL/USDC", true))
+	assert.Len(t, set.Evaluate(priceInput("SOL/USDC", 100)), 1)
+
+	assert.Error(t, set.SetEnabled("seller", "BONK/USDC", true))
+	assert.ErrorIs(t, set.SetEnabled("missing", "", true), ErrStrategyNotFound)
+
+	infos := set.List()
+	assert.Equal(t, []StrategyInfo{
+		{Name: "buyer", Enabled: true},
+		{Name: "seller", Symbols: []string{"SOL/USDC"}, Enabled: false},
+	}, infos)
+
+	assert.NoError(t, set.Unregister("seller"))
+	assert.ErrorIs(t, set.Unregister("seller"), ErrStrategyNotFound)
+}
+
+func TestMomentumStrategy(t *testing.T) {
+	strategy := NewMomentumStrategy("", 2, 0.05, 1)
+	assert.Equal(t, "momentum", strategy.Name())
+
+	var sides []string
+	for _, price := range []float64{100, 102, 106, 108, 109, 100, 95} {
+		for _, intent := range strategy.Evaluate(priceInput("SOL/USDC", price)) {
+			sides = append(sides, intent.Side)
+			assert.Equal(t, "market", intent.OrderType)
+			assert.Equal(t, price, intent.Price)
+		}
+	}
+	assert.Equal(t, []string{"buy", "sell"}, sides)
+}
+
+func TestMeanReversionStrategy(t *testing.T) {
+	strategy := NewMeanReversionStrategy("", 5, 1.5, 2)
+
+	var sides []string
+	for _, price := range []float64{100, 101, 100, 99, 100, 80, 81, 100, 100, 130} {
+		for _, intent := range strategy.Evaluate(priceInput("SOL/USDC", price)) {
+			sides = append(sides, intent.Side)
+			assert.Equal(t, 2.0, intent.Amount)
+		}
+	}
+	assert.Equal(t, []string{"buy", "sell"}, sides)
+}
+
+func TestAIFollowStrategy(t *testing.T) {
+	strategy := NewAIFollowStrategy("", 0.7, 1)
+
+	tests := []struct {
+		name     string
+		analysis *ai.Analysis
+		want     string
+	}{
+		{name: "no analysis", analysis: nil, want: ""},
+		{name: "confident buy signal", analysis: &ai.Analysis{Signals: []ai.Signal{{Action: "BUY", Confidence: 0.8}}}, want: "buy"},
+		{name: "repeated buy is ignored", analysis: &ai.Analysis{Signals: []ai.Signal{{Action: "BUY", Confidence: 0.9}}}, want: ""},
+		{name: "weak sell ignored", analysis: &ai.Analysis{Signals: []ai.Signal{{Action: "SELL", Confidence: 0.5}}}, want: ""},
+		{name: "bearish trend", analysis: &ai.Analysis{Trend: "BEARISH", Confidence: 0.75}, want: "sell"},
+		{name: "hold", analysis: &ai.Analysis{Trend: "neutral", Signals: []ai.Signal{{Action: "hold", Confidence: 0.9}}}, want: ""},
+		{name: "buy after hold", analysis: &ai.Analysis{Signals: []ai.Signal{{Action: "buy", Confidence: 0.95}}}, want: "buy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := priceInput("SOL/USDC", 100)
+			input.Analysis = tt.analysis
+			intents := strategy.Evaluate(input)
+			if tt.want == "" {
+				assert.Empty(t, intents)
+				return
+			}
+			assert.Len(t, intents, 1)
+			assert.Equal(t, tt.want, intents[0].Side)
+		})
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	strategy, err := NewStrategy("momentum", "fast", 1, map[string]float64{"lookback": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", strategy.Name())
+	assert.Equal(t, 3, strategy.(*MomentumStrategy).lookback)
+	assert.Equal(t, 0.02, strategy.(*MomentumStrategy).threshold)
+
+	_, err = NewStrategy("mean_reversion", "", 1, nil)
+	assert.NoError(t, err)
+	_, err = NewStrategy("ai_follow", "", 1, nil)
+	assert.NoError(t, err)
+	_, err = NewStrategy("grid", "", 1, nil)
+	assert.Error(t, err)
+	_, err = NewStrategy("momentum", "", 0, nil)
+	assert.Error(t, err)
+}
+
+func TestTradingEngine_RoutesIntentsThroughRisk(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.MatchedBy(func(o risk.Order) bool { return o.Symbol == "SOL/USDC" })).Return(nil)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(assert.AnError)
+
+	jupiter := &mockExchange{name: "Jupiter"}
+	jupiter.On("GetMarketData").Return([]*exchange.MarketData{
+		{Symbol: "SOL/USDC", Price: 100},
+		{Symbol: "BONK/USDC", Price: 0.1},
+	}, nil)
+	jupiter.On("ExecuteOrder", mock.Anything).Return(&exchange.ExecutionReport{FilledAmount: 1, AvgPrice: 100}, nil)
+
+	engine := newTestEngine(mockRisk, jupiter)
+	assert.NoError(t, engine.RegisterStrategy(&fixedStrategy{name: "always_buy", intents: []OrderIntent{{Side: "buy", Amount: 1, OrderType: "market"}}}))
+
+	engine.pollExchange(jupiter)
+
+	orders := engine.ListOrders(OrderFilter{})
+	assert.Len(t, orders, 2)
+	filled := engine.ListOrders(OrderFilter{Statuses: []OrderStatus{OrderStatusFilled}})
+	assert.Len(t, filled, 1)
+	assert.Equal(t, "SOL/USDC", filled[0].Symbol)
+	assert.Equal(t, "Jupiter", filled[0].Exchange)
+	rejected := engine.ListOrders(OrderFilter{Statuses: []OrderStatus{OrderStatusRejected}})
+	assert.Len(t, rejected, 1)
+	assert.Equal(t, "BONK/USDC", rejected[0].Symbol)
+
+	assert.NoError(t, engine.DisableStrategy("always_buy", ""))
+	engine.pollExchange(jupiter)
+	assert.Len(t, engine.ListOrders(OrderFilter{}), 2)
+}
+
+type launchExchange struct {
+	*mockExchange
+	launches chan exchange.TokenLaunch
+}
+
+func (e *launchExchange) SubscribeLaunches(ctx context.Context) (<-chan exchange.TokenLaunch, error) {
+	return e.launches, nil
+}
+
+// launchStrategy buys every token the moment it launches.
+type launchStrategy struct {
+	seen chan StrategyInput
+}
+
+func (s *launchStrategy) Name() string {
+	return "launch"
+}
+
+func (s *launchStrategy) Evaluate(input StrategyInput) []OrderIntent {
+	if input.Launch == nil {
+		return nil
+	}
+	s.seen <- input
+	return []OrderIntent{{Side: "buy", Amount: 1e6, OrderType: "market", Reason: "launched"}}
+}
+
+func TestTradingEngine_LaunchesReachStrategies(t *testing.T) {
+	mockRisk := new(mockRiskManager)
+	mockRisk.On("ValidateOrder", mock.Anything).Return(nil)
+	pump := &launchExchange{mockExchange: &mockExchange{name: "Pump.fun"}, launches: make(chan exchange.TokenLaunch)}
+	pump.On("ExecuteOrder", mock.Anything).Return(&exchange.ExecutionReport{FilledAmount: 1e6, AvgPrice: 3e-8}, nil)
+
+	engine := newTestEngine(mockRisk, pump)
+	engine.pollInterval = time.Hour
+	strategy := &launchStrategy{seen: make(chan StrategyInput, 1)}
+	assert.NoError(t, engine.RegisterStrategy(strategy))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- engine.Start(ctx) }()
+
+	launch := exchange.TokenLaunch{
+		Mint:      solana.PublicKey{1},
+		Name:      "Launch",
+		Reserves:  exchange.BondingCurve{VirtualTokenReserves: 1073000000000000, VirtualSolReserves: 30000000000},
+		Timestamp: time.Now(),
+	}
+	pump.launches <- launch
+
+	select {
+	case input := <-strategy.seen:
+		assert.Equal(t, "Pump.fun", input.Exchange)
+		assert.Equal(t, launch.Market(), input.Market.Symbol)
+		assert.InDelta(t, 2.7958993e-8, input.Market.Price, 1e-15)
+		assert.Equal(t, launch, *input.Launch)
+	case <-time.After(time.Second):
+		t.Fatal("strategy never saw the launch")
+	}
+
+	close(pump.launches)
+	cancel()
+	assert.NoError(t, <-result)
+
+	orders := engine.ListOrders(OrderFilter{})
+	require.Len(t, orders, 1)
+	assert.Equal(t, launch.Market(), orders[0].Symbol)
+	assert.Equal(t, "Pump.fun", orders[0].Exchange)
+	assert.Equal(t, OrderStatusFilled, orders[0].Status)
+}
@@ -0,0 +1,138 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// seedKeyID is the key store entry holding the BIP39 seed every wallet key
+// is derived from. It is not a wallet key itself.
+const seedKeyID = "bip39-seed"
+
+// mnemonicEntropyBits gives 24-word mnemonics.
+const mnemonicEntropyBits = 256
+
+const hardenedOffset = 0x80000000
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid BIP39 mnemonic")
+	ErrNoSeed          = errors.New("key store has no wallet seed")
+	ErrSeedExists      = errors.New("key store already holds a different wallet seed")
+)
+
+// GenerateMnemonic returns a fresh 24-word BIP39 mnemonic to back up and
+// pass to NewWalletManagerFromMnemonic.
+func GenerateMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	defer zero(entropy)
+	return bip39.NewMnemonic(entropy)
+}
+
+// SolanaDerivationPath is the path Solana wallets (Phantom, Solflare, the
+// Solana CLI with `prompt://?key=n/0`) derive account n from.
+func SolanaDerivationPath(account uint32) string {
+	return fmt.Sprintf("m/44'/501'/%d'/0'", account)
+}
+
+// DeriveKey derives the ed25519 key at path from a BIP39 seed following
+// SLIP-0010. ed25519 only supports hardened derivation, so every path
+// segment must end in '.
+func DeriveKey(seed []byte, path string) (ed25519.PrivateKey, error) {
+	indexes, err := parseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, chainCode := slip10Step([]byte("ed25519 seed"), seed)
+	for _, index := range indexes {
+		data := make([]byte, 1+32+4)
+		copy(data[1:], key)
+		binary.BigEndian.PutUint32(data[33:], index)
+		zero(key)
+		key, chainCode = slip10Step(chainCode, data)
+		zero(data)
+	}
+	zero(chainCode)
+	defer zero(key)
+
+	return ed25519.NewKeyFromSeed(key), nil
+}
+
+func slip10Step(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+func parseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q", path)
+	}
+	indexes := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		if !strings.HasSuffix(segment, "'") {
+			return nil, fmt.Errorf("invalid derivation path %q: ed25519 needs hardened segments", path)
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(segment, "'"), 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %w", path, err)
+		}
+		indexes = append(indexes, uint32(n)+hardenedOffset)
+	}
+	return indexes, nil
+}
+
+// StoreMnemonic stores the seed for mnemonic and passphrase so wallet keys
+// can be derived from it. Storing the seed a key store already holds is a
+// no-op; a different one is rejected so existing wallets stay recoverable.
+func (ks *HSMKeyStore) StoreMnemonic(mnemonic, passphrase string) error {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+	defer zero(seed)
+
+	if existing, err := ks.Retrieve(seedKeyID); err == nil {
+		defer zero(existing)
+		if !bytes.Equal(existing, seed) {
+			return ErrSeedExists
+		}
+		return nil
+	}
+	return ks.Store(seedKeyID, seed)
+}
+
+// HasSeed reports whether wallet keys can be derived from the key store.
+func (ks *HSMKeyStore) HasSeed() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	_, ok := ks.keys[seedKeyID]
+	return ok
+}
+
+// deriveSolanaKey derives Solana account n from the stored seed.
+func (ks *HSMKeyStore) deriveSolanaKey(account uint32) (ed25519.PrivateKey, error) {
+	if !ks.HasSeed() {
+		return nil, ErrNoSeed
+	}
+	seed, err := ks.Retrieve(seedKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(seed)
+	return DeriveKey(seed, SolanaDerivationPath(account))
+}
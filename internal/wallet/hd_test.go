@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMnemonic and its first accounts are from the Solana Cookbook's
+// "Restoring a keypair from a mnemonic" example.
+const testMnemonic = "neither lonely flavor argue grass remind eye tag avocado spot unusual intact"
+
+var testMnemonicAccounts = []string{
+	"5vftMkHL72JaJG6ExQfGAsT2uGVHpRR7oTNUPMs68Y2N",
+	"GcXbfQ5yY3uxCyBNDPBbR5FjumHf89E7YHXuULfGDBBv",
+	"7QPgyQwNLqnoSwHEuK8wKy2Y3Ani6EHoZRihTuWkwxbc",
+	"5aE8UprEEWtpVskhxo3f8ETco2kVKiZT9SS3D5Lcg8s2",
+}
+
+func TestDeriveKey(t *testing.T) {
+	// SLIP-0010 ed25519 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{"m/0'", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+		{"m/0'/1'", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2"},
+		{"m/0'/1'/2'", "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9"},
+		{"m/0'/1'/2'/2'", "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662"},
+		{"m/0'/1'/2'/2'/1000000000'", "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, err := DeriveKey(seed, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, hex.EncodeToString(key.Seed()))
+		})
+	}
+
+	for _, path := range []string{"", "44'/501'", "m/44'/501'/0", "m/x'", "m/2147483648'"} {
+		_, err := DeriveKey(seed, path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestGenerateMnemonic(t *testing.T) {
+	mnemonic, err := GenerateMnemonic()
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+
+	keyStore, err := NewHSMKeyStore()
+	require.NoError(t, err)
+	assert.NoError(t, keyStore.StoreMnemonic(mnemonic, ""))
+}
+
+func TestWalletManager_FromMnemonic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	rpc := solana.NewClient("http://127.0.0.1:0")
+	ks, err := OpenHSMKeyStore(path, []byte("passphrase"), testScrypt)
+	require.NoError(t, err)
+
+	_, err = NewSolanaWallet("A", ks, rpc, 0)
+	assert.ErrorIs(t, err, ErrNoSeed)
+	manager, err := NewWalletManagerWithKeyStore(ks, rpc)
+	require.NoError(t, err)
+	assert.ErrorIs(t, manager.CreateWallet(TradingWallet), ErrNoSeed)
+
+	_, err = NewWalletManagerFromMnemonic("neither lonely flavor argue grass remind eye tag avocado spot unusual unusual", "", ks, rpc)
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	manager, err = NewWalletManagerFromMnemonic(testMnemonic, "", ks, rpc)
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateWallet(TradingWallet))
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	require.NoError(t, manager.CreateDerivedWallet("C", 3))
+	assert.Error(t, manager.CreateDerivedWallet("D", 1))
+	assert.Error(t, manager.CreateWallet("D"))
+
+	addresses := map[WalletType]string{
+		TradingWallet: testMnemonicAccounts[0],
+		ProfitWallet:  testMnemonicAccounts[1],
+		"C":           testMnemonicAccounts[3],
+	}
+	for walletType, address := range addresses {
+		w, err := manager.GetWallet(walletType)
+		require.NoError(t, err)
+		assert.Equal(t, address, w.GetAddress(), walletType)
+	}
+
+	// The seed survives restarts and cannot be swapped out
+	ks, err = OpenHSMKeyStore(path, []byte("passphrase"), KDFParams{})
+	require.NoError(t, err)
+	_, err = NewWalletManagerFromMnemonic(testMnemonic, "other passphrase", ks, rpc)
+	assert.ErrorIs(t, err, ErrSeedExists)
+	restarted, err := NewWalletManagerFromMnemonic(testMnemonic, "", ks, rpc)
+	require.NoError(t, err)
+	require.NoError(t, restarted.CreateDerivedWallet("D", 2))
+	w, _ := restarted.GetWallet("D")
+	assert.Equal(t, testMnemonicAccounts[2], w.GetAddress())
+	w, err = restarted.GetWallet(ProfitWallet)
+	require.NoError(t, err)
+	assert.Equal(t, testMnemonicAccounts[1], w.GetAddress())
+}
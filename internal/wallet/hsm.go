@@ -3,16 +3,24 @@ package wallet
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ed25519"
 	"crypto/rand"
 	"errors"
 	"io"
 	"sync"
+	"time"
 )
 
 type HSMKeyStore struct {
 	mu       sync.RWMutex
 	keys     map[string][]byte
 	masterKey []byte
+
+	// Set for keystores opened from a file; every Store rewrites it.
+	path    string
+	kdf     KDFParams
+	check   []byte
+	created map[string]time.Time
 }
 
 func NewHSMKeyStore() (*HSMKeyStore, error) {
@@ -46,7 +54,16 @@ func (ks *HSMKeyStore) Store(id string, key []byte) error {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 	
-	ks.keys[id] = gcm.Seal(nonce, nonce, key, nil)
+	previous, existed := ks.keys[id]
+	ks.keys[id] = gcm.Seal(nonce, nonce, key, []byte(id))
+	if err := ks.persist(); err != nil {
+		if existed {
+			ks.keys[id] = previous
+		} else {
+			delete(ks.keys, id)
+		}
+		return err
+	}
 	return nil
 }
 
@@ -75,5 +92,40 @@ func (ks *HSMKeyStore) Retrieve(id string) ([]byte, error) {
 	}
 
 	nonce, ciphertext := encryptedKey[:nonceSize], encryptedKey[nonceSize:]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return gcm.Open(nil, nonce, ciphertext, []byte(id))
+}
+
+// Sign signs message with the ed25519 key stored under id. The key is only
+// decrypted for the duration of the call.
+func (ks *HSMKeyStore) Sign(id string, message []byte) ([]byte, error) {
+	key, err := ks.Retrieve(id)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("stored key is not an ed25519 private key")
+	}
+	return ed25519.Sign(ed25519.PrivateKey(key), message), nil
+}
+
+// PublicKey returns the public half of the ed25519 key stored under id.
+func (ks *HSMKeyStore) PublicKey(id string) (ed25519.PublicKey, error) {
+	key, err := ks.Retrieve(id)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("stored key is not an ed25519 private key")
+	}
+	return append(ed25519.PublicKey(nil), ed25519.PrivateKey(key).Public().(ed25519.PublicKey)...), nil
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
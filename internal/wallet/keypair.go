@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+var (
+	ErrInvalidKeypair = errors.New("invalid keypair")
+	ErrExportDisabled = errors.New("key export is disabled")
+	ErrExportConfirm  = errors.New("export confirmation does not match wallet address")
+)
+
+// KeyFormat is a serialization of a 64-byte Solana keypair: the ed25519
+// seed followed by its public key.
+type KeyFormat string
+
+const (
+	// KeyFormatJSON is the byte array the Solana CLI writes to id.json.
+	KeyFormatJSON KeyFormat = "json"
+	// KeyFormatBase58 is the secret key string browser wallets export.
+	KeyFormatBase58 KeyFormat = "base58"
+)
+
+// ParseKeypair decodes a keypair in either format, telling them apart by
+// the leading '['.
+func ParseKeypair(data []byte) (ed25519.PrivateKey, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		return ParseKeypairJSON(data)
+	}
+	return ParseKeypairBase58(string(data))
+}
+
+// LoadKeypairFile reads a keypair file such as ~/.config/solana/id.json.
+func LoadKeypairFile(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(data)
+
+	key, err := ParseKeypair(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func ParseKeypairJSON(data []byte) (ed25519.PrivateKey, error) {
+	var raw []int
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeypair, err)
+	}
+	defer func() {
+		for i := range raw {
+			raw[i] = 0
+		}
+	}()
+
+	b := make([]byte, len(raw))
+	for i, v := range raw {
+		if v < 0 || v > 255 {
+			zero(b)
+			return nil, fmt.Errorf("%w: byte %d out of range", ErrInvalidKeypair, i)
+		}
+		b[i] = byte(v)
+	}
+	return keypairFromBytes(b)
+}
+
+func ParseKeypairBase58(s string) (ed25519.PrivateKey, error) {
+	b, err := solana.DecodeBase58(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeypair, err)
+	}
+	return keypairFromBytes(b)
+}
+
+// keypairFromBytes checks that the public half matches the seed so a
+// corrupted or mismatched key is never stored.
+func keypairFromBytes(b []byte) (ed25519.PrivateKey, error) {
+	if len(b) != ed25519.PrivateKeySize {
+		zero(b)
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrInvalidKeypair, len(b), ed25519.PrivateKeySize)
+	}
+	key := ed25519.NewKeyFromSeed(b[:ed25519.SeedSize])
+	if !bytes.Equal(key[ed25519.SeedSize:], b[ed25519.SeedSize:]) {
+		zero(b)
+		zero(key)
+		return nil, fmt.Errorf("%w: public key does not match secret key", ErrInvalidKeypair)
+	}
+	zero(b)
+	return key, nil
+}
+
+// EncodeKeypair serializes key in format.
+func EncodeKeypair(key ed25519.PrivateKey, format KeyFormat) (string, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return "", ErrInvalidKeypair
+	}
+	switch format {
+	case KeyFormatJSON:
+		raw := make([]int, len(key))
+		for i, b := range key {
+			raw[i] = int(b)
+		}
+		out, err := json.Marshal(raw)
+		return string(out), err
+	case KeyFormatBase58:
+		return solana.EncodeBase58(key), nil
+	default:
+		return "", fmt.Errorf("unknown key format %q", format)
+	}
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 8032 test vector 1 as a Solana keypair
+const (
+	testKeypairJSON    = "[157,97,177,157,239,253,90,96,186,132,74,244,146,236,44,196,68,73,197,105,123,50,105,25,112,59,172,3,28,174,127,96,215,90,152,1,130,177,10,183,213,75,254,211,201,100,7,58,14,225,114,243,218,166,35,37,175,2,26,104,247,7,81,26]"
+	testKeypairBase58  = "49W385L4rePHy6PAaQUovbD2aacgN4HsKXSMeUzRg4fmwXszN91JuMFrQRj3vMDpZuRF3ZknQBuRBoWQJEfXstMw"
+	testKeypairAddress = "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z"
+)
+
+func TestParseKeypair(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "cli json", data: testKeypairJSON},
+		{name: "cli json with newline", data: testKeypairJSON + "\n"},
+		{name: "base58", data: testKeypairBase58},
+		{name: "base58 with whitespace", data: "  " + testKeypairBase58 + "\n"},
+		{name: "short json", data: "[1,2,3]", wantErr: true},
+		{name: "byte out of range", data: "[256" + testKeypairJSON[4:], wantErr: true},
+		{name: "mismatched public key", data: testKeypairJSON[:len(testKeypairJSON)-3] + "27]", wantErr: true},
+		{name: "seed only", data: solana.EncodeBase58(make([]byte, 32)), wantErr: true},
+		{name: "not base58", data: "0OIl", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKeypair([]byte(tt.data))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidKeypair)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testKeypairAddress, solana.EncodeBase58(key[32:]))
+
+			for format, want := range map[KeyFormat]string{KeyFormatJSON: testKeypairJSON, KeyFormatBase58: testKeypairBase58} {
+				encoded, err := EncodeKeypair(key, format)
+				assert.NoError(t, err)
+				assert.Equal(t, want, encoded)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "id.json")
+	require.NoError(t, os.WriteFile(path, []byte(testKeypairJSON), 0o600))
+	key, err := LoadKeypairFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, key, 64)
+	_, err = LoadKeypairFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	_, err = EncodeKeypair(key, "hex")
+	assert.Error(t, err)
+}
+
+func TestWalletManager_ImportExport(t *testing.T) {
+	manager, err := NewWalletManagerWithRPC(solana.NewClient("http://127.0.0.1:0"))
+	require.NoError(t, err)
+
+	key, err := ParseKeypair([]byte(testKeypairJSON))
+	require.NoError(t, err)
+	require.NoError(t, manager.ImportWallet(TradingWallet, key))
+	assert.Error(t, manager.ImportWallet(TradingWallet, key))
+	assert.ErrorIs(t, manager.ImportWallet(ProfitWallet, key[:32]), ErrInvalidKeypair)
+
+	w, err := manager.GetWallet(TradingWallet)
+	require.NoError(t, err)
+	assert.Equal(t, testKeypairAddress, w.GetAddress())
+
+	// Generated wallets get valid addresses too
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	profit, _ := manager.GetWallet(ProfitWallet)
+	_, err = solana.PublicKeyFromBase58(profit.GetAddress())
+	assert.NoError(t, err)
+
+	_, err = manager.ExportWallet(TradingWallet, testKeypairAddress, KeyFormatBase58)
+	assert.ErrorIs(t, err, ErrExportDisabled)
+
+	manager.AllowKeyExport(true)
+	_, err = manager.ExportWallet(TradingWallet, profit.GetAddress(), KeyFormatBase58)
+	assert.ErrorIs(t, err, ErrExportConfirm)
+	_, err = manager.ExportWallet("C", testKeypairAddress, KeyFormatBase58)
+	assert.Error(t, err)
+
+	exported, err := manager.ExportWallet(TradingWallet, testKeypairAddress, KeyFormatBase58)
+	assert.NoError(t, err)
+	assert.Equal(t, testKeypairBase58, exported)
+	exported, err = manager.ExportWallet(TradingWallet, testKeypairAddress, KeyFormatJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, testKeypairJSON, exported)
+
+	manager.AllowKeyExport(false)
+	_, err = manager.ExportWallet(TradingWallet, testKeypairAddress, KeyFormatJSON)
+	assert.ErrorIs(t, err, ErrExportDisabled)
+}
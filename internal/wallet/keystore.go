@@ -0,0 +1,274 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeystorePassphraseEnv names the variable OpenHSMKeyStoreFromEnv reads the
+// passphrase from.
+const KeystorePassphraseEnv = "KEYSTORE_PASSPHRASE"
+
+const (
+	keystoreVersion = 1
+	keystoreCheck   = "devinsystem keystore"
+	saltSize        = 16
+)
+
+var (
+	ErrWrongPassphrase     = errors.New("wrong keystore passphrase")
+	ErrUnsupportedKeystore = errors.New("unsupported keystore")
+)
+
+// KDFParams says how the master key is derived from the passphrase. Name is
+// "scrypt" (N, R, P) or "argon2id" (Time, Memory in KiB, Threads).
+type KDFParams struct {
+	Name    string `json:"name"`
+	Salt    []byte `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// DefaultKDF is used for new keystore files when none is given.
+var DefaultKDF = KDFParams{Name: "scrypt", N: 1 << 15, R: 8, P: 1}
+
+func (p KDFParams) deriveKey(passphrase []byte) ([]byte, error) {
+	switch p.Name {
+	case "scrypt":
+		return scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, 32)
+	case "argon2id":
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, fmt.Errorf("%w: incomplete argon2id parameters", ErrUnsupportedKeystore)
+		}
+		return argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, 32), nil
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrUnsupportedKeystore, p.Name)
+	}
+}
+
+// keystoreFile is the on-disk format. Each key is sealed with AES-GCM under
+// the derived master key, with its ID as additional data so entries cannot
+// be swapped. Check seals a known value to detect a wrong passphrase.
+type keystoreFile struct {
+	Version int                      `json:"version"`
+	KDF     KDFParams                `json:"kdf"`
+	Check   []byte                   `json:"check"`
+	Keys    map[string]keystoreEntry `json:"keys"`
+}
+
+type keystoreEntry struct {
+	Ciphertext []byte    `json:"ciphertext"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OpenHSMKeyStore opens the keystore file at path, creating it with kdf
+// (DefaultKDF when zero) if it does not exist. Keys stored afterwards are
+// written back to the file before Store returns.
+func OpenHSMKeyStore(path string, passphrase []byte, kdf KDFParams) (*HSMKeyStore, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("keystore passphrase is empty")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKeyStore(path, passphrase, kdf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode keystore %s: %w", path, err)
+	}
+	if file.Version != keystoreVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedKeystore, file.Version)
+	}
+
+	masterKey, err := file.KDF.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	check, err := openSealed(masterKey, file.Check, []byte("check"))
+	if err != nil || !bytes.Equal(check, []byte(keystoreCheck)) {
+		return nil, ErrWrongPassphrase
+	}
+
+	ks := &HSMKeyStore{
+		keys:      make(map[string][]byte, len(file.Keys)),
+		masterKey: masterKey,
+		path:      path,
+		kdf:       file.KDF,
+		check:     file.Check,
+		created:   make(map[string]time.Time, len(file.Keys)),
+	}
+	for id, entry := range file.Keys {
+		ks.keys[id] = entry.Ciphertext
+		ks.created[id] = entry.CreatedAt
+	}
+	return ks, nil
+}
+
+// OpenHSMKeyStoreFromEnv is OpenHSMKeyStore with the passphrase taken from
+// KEYSTORE_PASSPHRASE.
+func OpenHSMKeyStoreFromEnv(path string, kdf KDFParams) (*HSMKeyStore, error) {
+	passphrase := os.Getenv(KeystorePassphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s is not set", KeystorePassphraseEnv)
+	}
+	return OpenHSMKeyStore(path, []byte(passphrase), kdf)
+}
+
+func createKeyStore(path string, passphrase []byte, kdf KDFParams) (*HSMKeyStore, error) {
+	if kdf.Name == "" {
+		kdf = DefaultKDF
+	}
+	kdf.Salt = make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, kdf.Salt); err != nil {
+		return nil, err
+	}
+
+	masterKey, err := kdf.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	check, err := seal(masterKey, []byte(keystoreCheck), []byte("check"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &HSMKeyStore{
+		keys:      make(map[string][]byte),
+		masterKey: masterKey,
+		path:      path,
+		kdf:       kdf,
+		check:     check,
+		created:   make(map[string]time.Time),
+	}
+	if err := ks.persist(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// KeyIDs lists the stored key IDs in order.
+func (ks *HSMKeyStore) KeyIDs() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// persist writes every key to the keystore file, replacing it atomically.
+// It is a no-op for in-memory keystores. Callers hold ks.mu.
+func (ks *HSMKeyStore) persist() error {
+	if ks.path == "" {
+		return nil
+	}
+
+	file := keystoreFile{
+		Version: keystoreVersion,
+		KDF:     ks.kdf,
+		Check:   ks.check,
+		Keys:    make(map[string]keystoreEntry, len(ks.keys)),
+	}
+	now := time.Now().UTC()
+	for id, ciphertext := range ks.keys {
+		if _, ok := ks.created[id]; !ok {
+			ks.created[id] = now
+		}
+		file.Keys[id] = keystoreEntry{Ciphertext: ciphertext, CreatedAt: ks.created[id]}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ks.path, data, 0o600)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	// Make sure the contents are on disk before the rename makes them live
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func seal(key, plaintext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func openSealed(key, sealed, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid sealed data")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additional)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Cheap parameters so tests stay fast
+var (
+	testScrypt = KDFParams{Name: "scrypt", N: 1 << 10, R: 8, P: 1}
+	testArgon2 = KDFParams{Name: "argon2id", Time: 1, Memory: 1024, Threads: 1}
+)
+
+func TestOpenHSMKeyStore(t *testing.T) {
+	for _, kdf := range []KDFParams{testScrypt, testArgon2} {
+		t.Run(kdf.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "keys", "keystore.json")
+			passphrase := []byte("correct horse battery staple")
+			keyA := ed25519.NewKeyFromSeed(make([]byte, 32))
+			keyB := ed25519.NewKeyFromSeed(append(make([]byte, 31), 1))
+
+			ks, err := OpenHSMKeyStore(path, passphrase, kdf)
+			require.NoError(t, err)
+			require.NoError(t, ks.Store("A", keyA))
+			require.NoError(t, ks.Store("B", keyB))
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			entries, err := os.ReadDir(filepath.Dir(path))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "temporary files left behind")
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), solana.EncodeBase58(keyA))
+			var file keystoreFile
+			require.NoError(t, json.Unmarshal(data, &file))
+			assert.Equal(t, keystoreVersion, file.Version)
+			assert.Equal(t, kdf.Name, file.KDF.Name)
+			assert.Len(t, file.KDF.Salt, saltSize)
+
+			// A restart sees the same keys
+			reopened, err := OpenHSMKeyStore(path, passphrase, KDFParams{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"A", "B"}, reopened.KeyIDs())
+			key, err := reopened.Retrieve("A")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte(keyA), key)
+			key, err = reopened.Retrieve("B")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte(keyB), key)
+
+			_, err = OpenHSMKeyStore(path, []byte("wrong"), KDFParams{})
+			assert.ErrorIs(t, err, ErrWrongPassphrase)
+		})
+	}
+}
+
+func TestOpenHSMKeyStore_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keystore.json")
+	passphrase := []byte("passphrase")
+
+	ks, err := OpenHSMKeyStore(path, passphrase, testScrypt)
+	require.NoError(t, err)
+	require.NoError(t, ks.Store("A", []byte("key a")))
+	require.NoError(t, ks.Store("B", []byte("key b")))
+
+	_, err = OpenHSMKeyStore(path, nil, KDFParams{})
+	assert.Error(t, err)
+	_, err = OpenHSMKeyStore(filepath.Join(dir, "new.json"), passphrase, KDFParams{Name: "md5"})
+	assert.ErrorIs(t, err, ErrUnsupportedKeystore)
+
+	rewrite := func(t *testing.T, f func(*keystoreFile)) string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var file keystoreFile
+		require.NoError(t, json.Unmarshal(data, &file))
+		f(&file)
+		data, err = json.Marshal(file)
+		require.NoError(t, err)
+		modified := filepath.Join(t.TempDir(), "keystore.json")
+		require.NoError(t, os.WriteFile(modified, data, 0o600))
+		return modified
+	}
+
+	_, err = OpenHSMKeyStore(rewrite(t, func(f *keystoreFile) { f.Version = 2 }), passphrase, KDFParams{})
+	assert.ErrorIs(t, err, ErrUnsupportedKeystore)
+
+	// Ciphertexts are bound to their key ID
+	swapped, err := OpenHSMKeyStore(rewrite(t, func(f *keystoreFile) {
+		f.Keys["A"], f.Keys["B"] = f.Keys["B"], f.Keys["A"]
+	}), passphrase, KDFParams{})
+	require.NoError(t, err)
+	_, err = swapped.Retrieve("A")
+	assert.Error(t, err)
+
+	// A failed write leaves the key out rather than half stored
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "blocker"), 0o700))
+	assert.Error(t, ks.Store("C", []byte("key c")))
+	_, err = ks.Retrieve("C")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"A", "B"}, ks.KeyIDs())
+}
+
+func TestOpenHSMKeyStoreFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+
+	t.Setenv(KeystorePassphraseEnv, "")
+	_, err := OpenHSMKeyStoreFromEnv(path, testScrypt)
+	assert.Error(t, err)
+
+	t.Setenv(KeystorePassphraseEnv, "from env")
+	ks, err := OpenHSMKeyStoreFromEnv(path, testScrypt)
+	require.NoError(t, err)
+	require.NoError(t, ks.Store("A", []byte("key")))
+
+	_, err = OpenHSMKeyStore(path, []byte("from env"), KDFParams{})
+	assert.NoError(t, err)
+}
+
+func TestWalletManager_RestoresFromKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	rpc := solana.NewClient("http://127.0.0.1:0")
+
+	ks, err := OpenHSMKeyStore(path, []byte("passphrase"), testScrypt)
+	require.NoError(t, err)
+	manager, err := NewWalletManagerFromMnemonic(testMnemonic, "", ks, rpc)
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateWallet(TradingWallet))
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	trading, _ := manager.GetWallet(TradingWallet)
+	profit, _ := manager.GetWallet(ProfitWallet)
+
+	ks, err = OpenHSMKeyStore(path, []byte("passphrase"), KDFParams{})
+	require.NoError(t, err)
+	restarted, err := NewWalletManagerWithKeyStore(ks, rpc)
+	require.NoError(t, err)
+
+	restored, err := restarted.GetWallet(TradingWallet)
+	require.NoError(t, err)
+	assert.Equal(t, trading.GetAddress(), restored.GetAddress())
+	restored, err = restarted.GetWallet(ProfitWallet)
+	require.NoError(t, err)
+	assert.Equal(t, profit.GetAddress(), restored.GetAddress())
+	assert.Error(t, restarted.CreateWallet(TradingWallet))
+}
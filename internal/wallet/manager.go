@@ -1,29 +1,128 @@
 package wallet
 
 import (
+	"crypto/ed25519"
 	"errors"
+	"fmt"
 	"sync"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
 )
 
+// DefaultRPCURL is the node wallets use unless given their own client.
+const DefaultRPCURL = "https://api.mainnet-beta.solana.com"
+
+// walletAccounts are the derivation accounts of the standard wallets. Other
+// wallets pick their own with CreateDerivedWallet.
+var walletAccounts = map[WalletType]uint32{
+	TradingWallet: 0,
+	ProfitWallet:  1,
+}
+
 type walletManager struct {
-	mu       sync.RWMutex
-	keyStore *HSMKeyStore
-	wallets  map[WalletType]*SolanaWallet
+	mu          sync.RWMutex
+	keyStore    *HSMKeyStore
+	rpc         *solana.Client
+	wallets     map[WalletType]*SolanaWallet
+	allowExport bool
 }
 
 func NewWalletManager() (*walletManager, error) {
+	return NewWalletManagerWithRPC(solana.NewClient(DefaultRPCURL))
+}
+
+// NewWalletManagerWithRPC creates wallets that read and move funds through
+// rpc. Keys derive from a throwaway mnemonic and live in memory only, so
+// they are lost on restart.
+func NewWalletManagerWithRPC(rpc *solana.Client) (*walletManager, error) {
 	keyStore, err := NewHSMKeyStore()
 	if err != nil {
 		return nil, err
 	}
+	mnemonic, err := GenerateMnemonic()
+	if err != nil {
+		return nil, err
+	}
+	return NewWalletManagerFromMnemonic(mnemonic, "", keyStore, rpc)
+}
+
+// NewWalletManagerFromMnemonic derives every wallet from mnemonic and the
+// optional BIP39 passphrase. The seed is kept in keyStore, so later starts
+// can use NewWalletManagerWithKeyStore without the mnemonic.
+func NewWalletManagerFromMnemonic(mnemonic, passphrase string, keyStore *HSMKeyStore, rpc *solana.Client) (*walletManager, error) {
+	if err := keyStore.StoreMnemonic(mnemonic, passphrase); err != nil {
+		return nil, err
+	}
+	return NewWalletManagerWithKeyStore(keyStore, rpc)
+}
 
-	return &walletManager{
+// NewWalletManagerWithKeyStore restores a wallet for every key already in
+// keyStore, so wallets kept in a keystore file survive restarts.
+func NewWalletManagerWithKeyStore(keyStore *HSMKeyStore, rpc *solana.Client) (*walletManager, error) {
+	m := &walletManager{
 		keyStore: keyStore,
+		rpc:      rpc,
 		wallets:  make(map[WalletType]*SolanaWallet),
-	}, nil
+	}
+	for _, id := range keyStore.KeyIDs() {
+		if id == seedKeyID {
+			continue
+		}
+		wallet, err := openSolanaWallet(id, keyStore, rpc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore wallet %s: %w", id, err)
+		}
+		m.wallets[WalletType(id)] = wallet
+	}
+	return m, nil
 }
 
+// CreateWallet derives the trading or profit wallet from the seed along
+// m/44'/501'/n'/0', with n 0 and 1 respectively.
 func (m *walletManager) CreateWallet(walletType WalletType) error {
+	account, ok := walletAccounts[walletType]
+	if !ok {
+		return fmt.Errorf("no derivation account for wallet %s; use CreateDerivedWallet", walletType)
+	}
+	return m.CreateDerivedWallet(walletType, account)
+}
+
+// CreateDerivedWallet derives a sub-wallet from the seed at Solana account
+// n. Accounts 0 and 1 belong to the trading and profit wallets.
+func (m *walletManager) CreateDerivedWallet(walletType WalletType, account uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.wallets[walletType]; exists {
+		return errors.New("wallet already exists")
+	}
+
+	privateKey, err := m.keyStore.deriveSolanaKey(account)
+	if err != nil {
+		return err
+	}
+	defer zero(privateKey)
+
+	var publicKey solana.PublicKey
+	copy(publicKey[:], privateKey.Public().(ed25519.PublicKey))
+	for existingType, existing := range m.wallets {
+		if existing.PublicKey() == publicKey {
+			return fmt.Errorf("account %d is already used by wallet %s", account, existingType)
+		}
+	}
+
+	wallet, err := NewSolanaWalletFromKey(string(walletType), m.keyStore, m.rpc, privateKey)
+	if err != nil {
+		return err
+	}
+
+	m.wallets[walletType] = wallet
+	return nil
+}
+
+// ImportWallet adds a wallet for an existing funded keypair instead of
+// generating a new one.
+func (m *walletManager) ImportWallet(walletType WalletType, privateKey ed25519.PrivateKey) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -31,7 +130,7 @@ func (m *walletManager) CreateWallet(walletType WalletType) error {
 		return errors.New("wallet already exists")
 	}
 
-	wallet, err := NewSolanaWallet(string(walletType), m.keyStore)
+	wallet, err := NewSolanaWalletFromKey(string(walletType), m.keyStore, m.rpc, privateKey)
 	if err != nil {
 		return err
 	}
@@ -40,17 +139,61 @@ func (m *walletManager) CreateWallet(walletType WalletType) error {
 	return nil
 }
 
-func (m *walletManager) TransferFunds(from, to WalletType, amount float64) error {
+// AllowKeyExport turns ExportWallet on or off. It is off by default.
+func (m *walletManager) AllowKeyExport(allow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowExport = allow
+}
+
+// ExportWallet returns the wallet's secret key in format. Export must have
+// been allowed with AllowKeyExport, and confirmAddress must repeat the
+// wallet's address so a key is never exported by mistake.
+func (m *walletManager) ExportWallet(walletType WalletType, confirmAddress string, format KeyFormat) (string, error) {
+	m.mu.RLock()
+	wallet, exists := m.wallets[walletType]
+	allowed := m.allowExport
+	m.mu.RUnlock()
+
+	if !allowed {
+		return "", ErrExportDisabled
+	}
+	if !exists {
+		return "", errors.New("wallet not found")
+	}
+	if confirmAddress != wallet.GetAddress() {
+		return "", ErrExportConfirm
+	}
+	return wallet.exportKey(format)
+}
+
+// AddWallet adds a wallet that signs with signer, e.g. a PKCS#11 token or a
+// remote signer, so its key never enters this process.
+func (m *walletManager) AddWallet(walletType WalletType, signer Signer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.wallets[walletType]; exists {
+		return errors.New("wallet already exists")
+	}
+
+	m.wallets[walletType] = NewSolanaWalletWithSigner(string(walletType), signer, m.rpc)
+	return nil
+}
+
+// TransferFunds moves amount of mint between two of the manager's wallets
+// on chain.
+func (m *walletManager) TransferFunds(from, to WalletType, mint solana.PublicKey, amount float64) (solana.Signature, error) {
 	m.mu.RLock()
 	fromWallet, fromExists := m.wallets[from]
 	toWallet, toExists := m.wallets[to]
 	m.mu.RUnlock()
 
 	if !fromExists || !toExists {
-		return errors.New("wallet not found")
+		return solana.Signature{}, errors.New("wallet not found")
 	}
 
-	return fromWallet.Transfer(toWallet, amount)
+	return fromWallet.Transfer(toWallet, mint, amount)
 }
 
 func (m *walletManager) GetWallet(walletType WalletType) (*SolanaWallet, error) {
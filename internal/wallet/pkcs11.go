@@ -0,0 +1,183 @@
+//go:build pkcs11
+
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/miekg/pkcs11"
+)
+
+// EdDSA identifiers from PKCS#11 v3.0, which miekg/pkcs11 does not define.
+const (
+	ckmEdDSA                  = 0x1057
+	ckmECEdwardsKeyPairGen    = 0x1055
+	ckkECEdwards              = 0x40
+	ed25519CurveOIDParameters = "\x06\x03\x2b\x65\x70"
+)
+
+// PKCS11Signer signs with an ed25519 key that never leaves a PKCS#11 token,
+// such as a network HSM or SoftHSM in development.
+type PKCS11Signer struct {
+	mu        sync.Mutex
+	ctx       *pkcs11.Ctx
+	session   pkcs11.SessionHandle
+	key       pkcs11.ObjectHandle
+	publicKey solana.PublicKey
+}
+
+// OpenPKCS11Signer loads the module, logs in to the token and looks up the
+// key pair labelled config.KeyLabel.
+func OpenPKCS11Signer(config PKCS11Config) (*PKCS11Signer, error) {
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s", config.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module: %w", err)
+	}
+
+	s := &PKCS11Signer{ctx: ctx}
+	if err := s.open(config); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *PKCS11Signer) open(config PKCS11Config) error {
+	slot, err := findSlot(s.ctx, config.TokenLabel)
+	if err != nil {
+		return err
+	}
+	s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("failed to open PKCS#11 session: %w", err)
+	}
+	if err := s.ctx.Login(s.session, pkcs11.CKU_USER, config.PIN); err != nil {
+		return fmt.Errorf("failed to log in to token %s: %w", config.TokenLabel, err)
+	}
+
+	s.key, err = findObject(s.ctx, s.session, pkcs11.CKO_PRIVATE_KEY, config.KeyLabel)
+	if err != nil {
+		return err
+	}
+	pub, err := findObject(s.ctx, s.session, pkcs11.CKO_PUBLIC_KEY, config.KeyLabel)
+	if err != nil {
+		return err
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, pub, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil)})
+	if err != nil {
+		return fmt.Errorf("failed to read public key %s: %w", config.KeyLabel, err)
+	}
+	point, err := decodeECPoint(attrs[0].Value)
+	if err != nil {
+		return err
+	}
+	copy(s.publicKey[:], point)
+	return nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list PKCS#11 slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("PKCS#11 token %q not found", label)
+}
+
+func findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, err
+	}
+	defer ctx.FindObjectsFinal(session)
+
+	objects, _, err := ctx.FindObjects(session, 2)
+	if err != nil {
+		return 0, err
+	}
+	switch len(objects) {
+	case 0:
+		return 0, fmt.Errorf("ed25519 key %q not found on token", label)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, fmt.Errorf("more than one ed25519 key labelled %q", label)
+	}
+}
+
+// decodeECPoint unwraps CKA_EC_POINT, which tokens return either raw or as
+// a DER OCTET STRING.
+func decodeECPoint(b []byte) ([]byte, error) {
+	if len(b) == 34 && b[0] == 0x04 && b[1] == 32 {
+		b = b[2:]
+	}
+	if len(b) != solana.PublicKeySize {
+		return nil, errors.New("token returned an invalid ed25519 public key")
+	}
+	return b, nil
+}
+
+func (s *PKCS11Signer) PublicKey() solana.PublicKey {
+	return s.publicKey
+}
+
+func (s *PKCS11Signer) Sign(ctx context.Context, message []byte) (solana.Signature, error) {
+	if err := ctx.Err(); err != nil {
+		return solana.Signature{}, err
+	}
+
+	// A session runs one signing operation at a time.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}, s.key); err != nil {
+		return solana.Signature{}, fmt.Errorf("PKCS#11 sign init: %w", err)
+	}
+	raw, err := s.ctx.Sign(s.session, message)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("PKCS#11 sign: %w", err)
+	}
+	sig, err := toSignature(raw)
+	if err != nil {
+		return sig, err
+	}
+	if err := verify(s.publicKey, message, sig); err != nil {
+		return solana.Signature{}, err
+	}
+	return sig, nil
+}
+
+// Close logs out and unloads the module.
+func (s *PKCS11Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session != 0 {
+		s.ctx.Logout(s.session)
+		s.ctx.CloseSession(s.session)
+		s.session = 0
+	}
+	err := s.ctx.Finalize()
+	s.ctx.Destroy()
+	return err
+}
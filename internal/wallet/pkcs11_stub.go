@@ -0,0 +1,29 @@
+//go:build !pkcs11
+
+package wallet
+
+import (
+	"context"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+// PKCS11Signer is only available in binaries built with -tags pkcs11, which
+// needs cgo.
+type PKCS11Signer struct{}
+
+func OpenPKCS11Signer(config PKCS11Config) (*PKCS11Signer, error) {
+	return nil, ErrPKCS11Unsupported
+}
+
+func (s *PKCS11Signer) PublicKey() solana.PublicKey {
+	return solana.PublicKey{}
+}
+
+func (s *PKCS11Signer) Sign(ctx context.Context, message []byte) (solana.Signature, error) {
+	return solana.Signature{}, ErrPKCS11Unsupported
+}
+
+func (s *PKCS11Signer) Close() error {
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build pkcs11
+
+package wallet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"os"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPKCS11Signer runs against an initialized token, e.g. SoftHSM:
+//
+//	softhsm2-util --init-token --free --label test --pin 1234 --so-pin 1234
+//	PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so PKCS11_TOKEN=test PKCS11_PIN=1234 \
+//		go test -tags pkcs11 -run PKCS11 ./internal/wallet/
+func TestPKCS11Signer(t *testing.T) {
+	config := PKCS11Config{
+		Module:     os.Getenv("PKCS11_MODULE"),
+		TokenLabel: os.Getenv("PKCS11_TOKEN"),
+		PIN:        os.Getenv("PKCS11_PIN"),
+		KeyLabel:   "devinsystem-test",
+	}
+	if config.Module == "" || config.TokenLabel == "" {
+		t.Skip("PKCS11_MODULE and PKCS11_TOKEN not set")
+	}
+	generateTokenKey(t, config)
+
+	signer, err := OpenPKCS11Signer(config)
+	require.NoError(t, err)
+	defer signer.Close()
+
+	message := []byte("transfer")
+	sig, err := signer.Sign(context.Background(), message)
+	require.NoError(t, err)
+	pub := signer.PublicKey()
+	assert.True(t, ed25519.Verify(pub[:], message, sig[:]))
+
+	config.KeyLabel = "missing"
+	_, err = OpenPKCS11Signer(config)
+	assert.Error(t, err)
+}
+
+// generateTokenKey creates a key pair labelled config.KeyLabel on the token
+// and removes it when the test ends.
+func generateTokenKey(t *testing.T, config PKCS11Config) {
+	ctx := pkcs11.New(config.Module)
+	require.NotNil(t, ctx)
+	require.NoError(t, ctx.Initialize())
+	defer func() {
+		ctx.Finalize()
+		ctx.Destroy()
+	}()
+
+	slot, err := findSlot(ctx, config.TokenLabel)
+	require.NoError(t, err)
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	require.NoError(t, err)
+	defer ctx.CloseSession(session)
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_USER, config.PIN))
+	defer ctx.Logout(session)
+
+	_, _, err = ctx.GenerateKeyPair(session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(ckmECEdwardsKeyPairGen, nil)},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, []byte(ed25519CurveOIDParameters)),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, config.KeyLabel),
+		},
+		[]*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, config.KeyLabel),
+		})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		ctx := pkcs11.New(config.Module)
+		ctx.Initialize()
+		defer func() {
+			ctx.Finalize()
+			ctx.Destroy()
+		}()
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return
+		}
+		defer ctx.CloseSession(session)
+		ctx.Login(session, pkcs11.CKU_USER, config.PIN)
+		for _, class := range []uint{pkcs11.CKO_PUBLIC_KEY, pkcs11.CKO_PRIVATE_KEY} {
+			if object, err := findObject(ctx, session, class, config.KeyLabel); err == nil {
+				ctx.DestroyObject(session, object)
+			}
+		}
+	})
+}
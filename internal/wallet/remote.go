@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+// signRequest and signResponse are the remote signer wire format: POST
+// /sign with the message to sign, answered with its base58 signature.
+type signRequest struct {
+	PublicKey solana.PublicKey `json:"public_key"`
+	Message   []byte           `json:"message"`
+}
+
+type signResponse struct {
+	Signature solana.Signature `json:"signature"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type RemoteSignerConfig struct {
+	// Token is sent as a bearer token with every request.
+	Token      string
+	HTTPClient *http.Client
+}
+
+// RemoteSigner asks a signing service over HTTP to sign with the key for
+// publicKey. The public key is configured rather than fetched, and every
+// signature is verified against it before use.
+type RemoteSigner struct {
+	endpoint  string
+	publicKey solana.PublicKey
+	token     string
+	client    *http.Client
+}
+
+func NewRemoteSigner(endpoint string, publicKey solana.PublicKey, config RemoteSignerConfig) *RemoteSigner {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &RemoteSigner{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		publicKey: publicKey,
+		token:     config.Token,
+		client:    config.HTTPClient,
+	}
+}
+
+func (s *RemoteSigner) PublicKey() solana.PublicKey {
+	return s.publicKey
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, message []byte) (solana.Signature, error) {
+	body, err := json.Marshal(signRequest{PublicKey: s.publicKey, Message: message})
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/sign", bytes.NewReader(body))
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded signResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer: failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		json.Unmarshal(data, &decoded)
+		return solana.Signature{}, fmt.Errorf("remote signer: status %d: %s", resp.StatusCode, decoded.Error)
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer: failed to decode response: %w", err)
+	}
+	if err := verify(s.publicKey, message, decoded.Signature); err != nil {
+		return solana.Signature{}, err
+	}
+	return decoded.Signature, nil
+}
+
+// SignerHandler serves the remote signer protocol for signers, so keys can
+// live in a separate process from the trader. Requests must carry token as
+// a bearer token.
+type SignerHandler struct {
+	signers map[solana.PublicKey]Signer
+	token   string
+}
+
+// NewSignerHandler refuses an empty token, which would let any request
+// with a bare "Bearer " header sign.
+func NewSignerHandler(token string, signers ...Signer) (*SignerHandler, error) {
+	if token == "" {
+		return nil, errors.New("signer token is empty")
+	}
+	h := &SignerHandler{signers: make(map[solana.PublicKey]Signer, len(signers)), token: token}
+	for _, signer := range signers {
+		h.signers[signer.PublicKey()] = signer
+	}
+	return h, nil
+}
+
+func (h *SignerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/sign" {
+		writeSignResponse(w, http.StatusNotFound, signResponse{Error: "not found"})
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+h.token)) != 1 {
+		writeSignResponse(w, http.StatusUnauthorized, signResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req signRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		writeSignResponse(w, http.StatusBadRequest, signResponse{Error: "invalid request"})
+		return
+	}
+	signer, ok := h.signers[req.PublicKey]
+	if !ok {
+		writeSignResponse(w, http.StatusNotFound, signResponse{Error: "unknown key " + req.PublicKey.String()})
+		return
+	}
+
+	sig, err := signer.Sign(r.Context(), req.Message)
+	if err != nil {
+		writeSignResponse(w, http.StatusInternalServerError, signResponse{Error: err.Error()})
+		return
+	}
+	writeSignResponse(w, http.StatusOK, signResponse{Signature: sig})
+}
+
+func writeSignResponse(w http.ResponseWriter, status int, resp signResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
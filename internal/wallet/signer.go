@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+var (
+	ErrSignatureMismatch = errors.New("signer returned a signature that does not verify")
+	ErrExportUnsupported = errors.New("wallet key is not held in the local key store")
+	ErrPKCS11Unsupported = errors.New("PKCS#11 support not built in; rebuild with -tags pkcs11")
+)
+
+// Signer signs messages with one ed25519 key without handing the key out.
+// Implementations may keep it in the local keystore, a PKCS#11 token or a
+// remote signing service.
+type Signer interface {
+	PublicKey() solana.PublicKey
+	Sign(ctx context.Context, message []byte) (solana.Signature, error)
+}
+
+// LocalSigner signs with a key held encrypted in an HSMKeyStore. The key is
+// only decrypted for the duration of each signature.
+type LocalSigner struct {
+	keyStore  *HSMKeyStore
+	id        string
+	publicKey solana.PublicKey
+}
+
+func NewLocalSigner(keyStore *HSMKeyStore, id string) (*LocalSigner, error) {
+	pub, err := keyStore.PublicKey(id)
+	if err != nil {
+		return nil, err
+	}
+	s := &LocalSigner{keyStore: keyStore, id: id}
+	copy(s.publicKey[:], pub)
+	return s, nil
+}
+
+func (s *LocalSigner) PublicKey() solana.PublicKey {
+	return s.publicKey
+}
+
+func (s *LocalSigner) Sign(ctx context.Context, message []byte) (solana.Signature, error) {
+	raw, err := s.keyStore.Sign(s.id, message)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	return toSignature(raw)
+}
+
+// export serializes the key. Only local keys can leave the signer, and only
+// through the manager's guarded ExportWallet.
+func (s *LocalSigner) export(format KeyFormat) (string, error) {
+	key, err := s.keyStore.Retrieve(s.id)
+	if err != nil {
+		return "", err
+	}
+	defer zero(key)
+	return EncodeKeypair(ed25519.PrivateKey(key), format)
+}
+
+// PKCS11Config selects an ed25519 key pair on a PKCS#11 token. The private
+// and public key objects must share KeyLabel.
+type PKCS11Config struct {
+	// Module is the path to the vendor library, e.g.
+	// /usr/lib/softhsm/libsofthsm2.so.
+	Module     string
+	TokenLabel string
+	PIN        string
+	KeyLabel   string
+}
+
+func toSignature(raw []byte) (solana.Signature, error) {
+	var sig solana.Signature
+	if len(raw) != solana.SignatureSize {
+		return sig, fmt.Errorf("invalid signature length %d", len(raw))
+	}
+	copy(sig[:], raw)
+	return sig, nil
+}
+
+// verify guards against signers that return garbage, e.g. a token holding a
+// different key than configured.
+func verify(signer solana.PublicKey, message []byte, sig solana.Signature) error {
+	if !ed25519.Verify(signer[:], message, sig[:]) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// SignTransaction signs tx with signer and places the signature in the
+// signer's slot.
+func SignTransaction(ctx context.Context, signer Signer, tx *solana.Transaction) error {
+	sig, err := signer.Sign(ctx, tx.Message)
+	if err != nil {
+		return err
+	}
+	return tx.AddSignature(signer.PublicKey(), sig)
+}
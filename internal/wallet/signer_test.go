@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalSigner(t *testing.T, id string, seed byte) *LocalSigner {
+	keyStore, err := NewHSMKeyStore()
+	require.NoError(t, err)
+	key := ed25519.NewKeyFromSeed(append(make([]byte, 31), seed))
+	require.NoError(t, keyStore.Store(id, key))
+	signer, err := NewLocalSigner(keyStore, id)
+	require.NoError(t, err)
+	return signer
+}
+
+func TestRemoteSigner(t *testing.T) {
+	local := newTestLocalSigner(t, "trading", 1)
+	_, err := NewSignerHandler("", local)
+	assert.Error(t, err)
+	handler, err := NewSignerHandler("secret", local)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	message := []byte("transfer")
+	signer := NewRemoteSigner(server.URL, local.PublicKey(), RemoteSignerConfig{Token: "secret"})
+	sig, err := signer.Sign(ctx, message)
+	require.NoError(t, err)
+	pub := local.PublicKey()
+	assert.True(t, ed25519.Verify(pub[:], message, sig[:]))
+
+	tests := []struct {
+		name   string
+		signer *RemoteSigner
+	}{
+		{"wrong token", NewRemoteSigner(server.URL, local.PublicKey(), RemoteSignerConfig{Token: "guess"})},
+		{"no token", NewRemoteSigner(server.URL, local.PublicKey(), RemoteSignerConfig{})},
+		{"unknown key", NewRemoteSigner(server.URL, solana.PublicKey{1}, RemoteSignerConfig{Token: "secret"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.Sign(ctx, message)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRemoteSigner_RejectsBadSignature(t *testing.T) {
+	// A service that signs with a different key than the one configured
+	other := newTestLocalSigner(t, "other", 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req signRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		sig, err := other.Sign(r.Context(), req.Message)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(signResponse{Signature: sig})
+	}))
+	defer server.Close()
+
+	local := newTestLocalSigner(t, "trading", 1)
+	signer := NewRemoteSigner(server.URL, local.PublicKey(), RemoteSignerConfig{})
+	_, err := signer.Sign(context.Background(), []byte("transfer"))
+	assert.ErrorIs(t, err, ErrSignatureMismatch)
+}
+
+func TestWalletManager_AddWallet(t *testing.T) {
+	chain, rpc := newFakeChain(t)
+	manager, err := NewWalletManagerWithRPC(rpc)
+	require.NoError(t, err)
+
+	// The trading key lives in a separate signing service
+	local := newTestLocalSigner(t, "trading", 1)
+	handler, err := NewSignerHandler("secret", local)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	remote := NewRemoteSigner(server.URL, local.PublicKey(), RemoteSignerConfig{Token: "secret"})
+
+	require.NoError(t, manager.AddWallet(TradingWallet, remote))
+	assert.Error(t, manager.AddWallet(TradingWallet, remote))
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	trading, _ := manager.GetWallet(TradingWallet)
+	profit, _ := manager.GetWallet(ProfitWallet)
+	assert.Equal(t, local.PublicKey(), trading.PublicKey())
+
+	chain.lamports[trading.PublicKey()] = solana.LamportsPerSOL
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, solana.NativeMint, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(500000000), chain.lamports[profit.PublicKey()])
+
+	manager.AllowKeyExport(true)
+	_, err = manager.ExportWallet(TradingWallet, trading.GetAddress(), KeyFormatBase58)
+	assert.ErrorIs(t, err, ErrExportUnsupported)
+}
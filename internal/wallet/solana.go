@@ -1,60 +1,294 @@
 package wallet
 
 import (
+	"context"
 	"crypto/ed25519"
-	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+const (
+	transferCommitment   = solana.CommitmentConfirmed
+	transferTimeout      = time.Minute
+	transferPollInterval = 500 * time.Millisecond
+)
+
+// A SOL transfer pays the fee for its one signature and must leave the
+// wallet rent exempt, so neither can be sent.
+const (
+	transferFee       = 5000   // lamports per signature
+	rentExemptMinimum = 890880 // lamports for an account without data
 )
 
+// SolanaWallet reads and moves funds through a Solana RPC node. It signs
+// through a Signer and never holds the raw key itself.
 type SolanaWallet struct {
-	id        string
-	keyStore  *HSMKeyStore
-	publicKey ed25519.PublicKey
-	balance   float64
+	id     string
+	signer Signer
+	rpc    *solana.Client
 }
 
-func NewSolanaWallet(id string, keyStore *HSMKeyStore) (*SolanaWallet, error) {
-	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+// NewSolanaWallet creates a wallet for Solana account n derived from the key
+// store's seed (see StoreMnemonic), so the key can be recovered from the
+// backed-up mnemonic.
+func NewSolanaWallet(id string, keyStore *HSMKeyStore, rpc *solana.Client, account uint32) (*SolanaWallet, error) {
+	privateKey, err := keyStore.deriveSolanaKey(account)
 	if err != nil {
 		return nil, err
 	}
+	defer zero(privateKey)
 
+	return NewSolanaWalletFromKey(id, keyStore, rpc, privateKey)
+}
+
+// NewSolanaWalletFromKey creates a wallet for an existing keypair, e.g. one
+// read with LoadKeypairFile. The key is copied into the key store; callers
+// should zero their copy.
+func NewSolanaWalletFromKey(id string, keyStore *HSMKeyStore, rpc *solana.Client, privateKey ed25519.PrivateKey) (*SolanaWallet, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKeypair
+	}
 	if err := keyStore.Store(id, privateKey); err != nil {
 		return nil, err
 	}
+	return openSolanaWallet(id, keyStore, rpc)
+}
+
+// NewSolanaWalletWithSigner creates a wallet whose key lives outside the
+// process, e.g. in a PKCS11Signer or RemoteSigner.
+func NewSolanaWalletWithSigner(id string, signer Signer, rpc *solana.Client) *SolanaWallet {
+	return &SolanaWallet{id: id, signer: signer, rpc: rpc}
+}
+
+// openSolanaWallet wraps a key that is already in the key store.
+func openSolanaWallet(id string, keyStore *HSMKeyStore, rpc *solana.Client) (*SolanaWallet, error) {
+	signer, err := NewLocalSigner(keyStore, id)
+	if err != nil {
+		return nil, err
+	}
+	return NewSolanaWalletWithSigner(id, signer, rpc), nil
+}
+
+func (w *SolanaWallet) ID() string {
+	return w.id
+}
+
+func (w *SolanaWallet) PublicKey() solana.PublicKey {
+	return w.signer.PublicKey()
+}
+
+// Signer signs for the wallet, e.g. swaps built by an exchange.
+func (w *SolanaWallet) Signer() Signer {
+	return w.signer
+}
 
-	return &SolanaWallet{
-		id:        id,
-		keyStore:  keyStore,
-		publicKey: publicKey,
-		balance:   0,
-	}, nil
+// GetBalance reads the wallet's balance of mint from chain, summing every
+// token account it owns for that mint.
+func (w *SolanaWallet) GetBalance(mint solana.PublicKey) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	units, decimals, err := w.balance(ctx, mint)
+	if err != nil {
+		return 0, err
+	}
+	return fromBaseUnits(units, decimals), nil
 }
 
-func (w *SolanaWallet) GetBalance() float64 {
-	return w.balance
+func (w *SolanaWallet) balance(ctx context.Context, mint solana.PublicKey) (uint64, uint8, error) {
+	if mint == solana.NativeMint {
+		lamports, err := w.rpc.GetBalance(ctx, w.PublicKey(), transferCommitment)
+		return lamports, 9, err
+	}
+
+	accounts, err := w.rpc.GetTokenAccountsByOwner(ctx, w.PublicKey(), solana.TokenAccountsFilter{Mint: &mint}, transferCommitment)
+	if err != nil {
+		return 0, 0, err
+	}
+	var units uint64
+	var decimals uint8
+	for _, account := range accounts {
+		units += account.Amount
+		decimals = account.Decimals
+	}
+	if len(accounts) == 0 {
+		token, err := w.rpc.GetMint(ctx, mint, transferCommitment)
+		if err != nil {
+			return 0, 0, err
+		}
+		decimals = token.Decimals
+	}
+	return units, decimals, nil
 }
 
-func (w *SolanaWallet) Transfer(to Wallet, amount float64) error {
-	if amount > w.balance {
-		return errors.New("insufficient funds")
+// Transfer sends amount of mint to the other wallet and waits for the
+// transfer to confirm. SOL moves with a System Program transfer; tokens
+// move between associated token accounts, creating the recipient's when it
+// does not exist yet.
+func (w *SolanaWallet) Transfer(to Wallet, mint solana.PublicKey, amount float64) (solana.Signature, error) {
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return solana.Signature{}, fmt.Errorf("invalid transfer amount: %f", amount)
 	}
-	
-	// In production, this would interact with Solana blockchain
-	w.balance -= amount
-	if err := to.ReceiveFunds(amount); err != nil {
-		w.balance += amount // rollback on failure
-		return err
+	dest := to.PublicKey()
+	if dest == w.PublicKey() {
+		return solana.Signature{}, errors.New("cannot transfer to the same wallet")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	var instructions []solana.Instruction
+	var err error
+	if mint == solana.NativeMint {
+		instructions, err = w.solTransfer(ctx, dest, amount)
+	} else {
+		instructions, err = w.tokenTransfer(ctx, dest, mint, amount)
 	}
-	
-	return nil
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	return w.submit(ctx, instructions)
 }
 
-func (w *SolanaWallet) ReceiveFunds(amount float64) error {
-	w.balance += amount
-	return nil
+// solTransfer moves lamports, keeping back the fee and the rent-exempt
+// minimum.
+func (w *SolanaWallet) solTransfer(ctx context.Context, dest solana.PublicKey, amount float64) ([]solana.Instruction, error) {
+	held, err := w.rpc.GetBalance(ctx, w.PublicKey(), transferCommitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance: %w", err)
+	}
+	lamports, err := toBaseUnits(amount, 9)
+	if err != nil {
+		return nil, err
+	}
+	var spendable uint64
+	if held > transferFee+rentExemptMinimum {
+		spendable = held - transferFee - rentExemptMinimum
+	}
+	if lamports > spendable {
+		return nil, fmt.Errorf("%w: need %d lamports, have %d after fees and rent", ErrInsufficientFunds, lamports, spendable)
+	}
+	return []solana.Instruction{solana.SystemTransfer(w.PublicKey(), dest, lamports)}, nil
 }
 
+// tokenTransfer moves tokens out of the wallet's associated token account,
+// the only one it debits, so other accounts of the mint do not count.
+func (w *SolanaWallet) tokenTransfer(ctx context.Context, dest, mint solana.PublicKey, amount float64) ([]solana.Instruction, error) {
+	token, err := w.rpc.GetMint(ctx, mint, transferCommitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mint %s: %w", mint, err)
+	}
+	units, err := toBaseUnits(amount, token.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	source, err := solana.AssociatedTokenAddress(w.PublicKey(), mint, token.ProgramID)
+	if err != nil {
+		return nil, err
+	}
+	destAccount, err := solana.AssociatedTokenAddress(dest, mint, token.ProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := w.rpc.GetTokenAccountsByOwner(ctx, w.PublicKey(), solana.TokenAccountsFilter{Mint: &mint}, transferCommitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance: %w", err)
+	}
+	var held uint64
+	for _, account := range accounts {
+		if account.Address == source {
+			held = account.Amount
+		}
+	}
+	if units > held {
+		return nil, fmt.Errorf("%w: need %d base units of %s in %s, have %d", ErrInsufficientFunds, units, mint, source, held)
+	}
+
+	var instructions []solana.Instruction
+	if _, err := w.rpc.GetAccountInfo(ctx, destAccount, transferCommitment); errors.Is(err, solana.ErrAccountNotFound) {
+		create, err := solana.CreateAssociatedTokenAccountIdempotent(w.PublicKey(), dest, mint, token.ProgramID)
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, create)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up token account %s: %w", destAccount, err)
+	}
+
+	transfer := solana.TransferChecked(source, mint, destAccount, w.PublicKey(), units, token.Decimals, token.ProgramID)
+	return append(instructions, transfer), nil
+}
+
+// submit builds a transaction paid for by the wallet, signs it with the
+// wallet's signer and waits for confirmation.
+func (w *SolanaWallet) submit(ctx context.Context, instructions []solana.Instruction) (solana.Signature, error) {
+	blockhash, err := w.rpc.GetLatestBlockhash(ctx, transferCommitment)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("failed to get blockhash: %w", err)
+	}
+	msg, err := solana.NewLegacyMessage(w.PublicKey(), instructions, blockhash.Blockhash)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	tx := solana.NewTransaction(msg)
+
+	if err := SignTransaction(ctx, w.signer, tx); err != nil {
+		return solana.Signature{}, fmt.Errorf("failed to sign transfer: %w", err)
+	}
+
+	sig, err := w.rpc.SendAndConfirm(ctx, tx, solana.SendOptions{PreflightCommitment: transferCommitment}, transferCommitment, transferPollInterval)
+	if err != nil {
+		return sig, fmt.Errorf("transfer %s: %w", sig, err)
+	}
+	return sig, nil
+}
+
+// SignatureStatus looks up a transaction the wallet sent, e.g. one whose
+// confirmation timed out. A nil status means the node has no record of it.
+func (w *SolanaWallet) SignatureStatus(sig solana.Signature) (*solana.SignatureStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+	defer cancel()
+
+	statuses, err := w.rpc.GetSignatureStatuses(ctx, []solana.Signature{sig}, true)
+	if err != nil {
+		return nil, err
+	}
+	return statuses[0], nil
+}
+
+// GetAddress is the wallet's base58 Solana address.
 func (w *SolanaWallet) GetAddress() string {
-	return hex.EncodeToString(w.publicKey)
+	return w.PublicKey().String()
+}
+
+// exportKey serializes the wallet's secret key. It is only reachable through
+// the manager's guarded ExportWallet, and only for keys in the local store.
+func (w *SolanaWallet) exportKey(format KeyFormat) (string, error) {
+	local, ok := w.signer.(*LocalSigner)
+	if !ok {
+		return "", ErrExportUnsupported
+	}
+	return local.export(format)
+}
+
+func toBaseUnits(amount float64, decimals uint8) (uint64, error) {
+	units := math.Round(amount * math.Pow10(int(decimals)))
+	if units < 1 {
+		return 0, fmt.Errorf("amount %f is below the token's smallest unit", amount)
+	}
+	if units >= math.MaxUint64 {
+		return 0, fmt.Errorf("amount %f is too large", amount)
+	}
+	return uint64(units), nil
+}
+
+func fromBaseUnits(units uint64, decimals uint8) float64 {
+	return float64(units) / math.Pow10(int(decimals))
 }
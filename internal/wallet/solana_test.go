@@ -0,0 +1,282 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tokenAccount struct {
+	owner  solana.PublicKey
+	mint   solana.PublicKey
+	amount uint64
+}
+
+// fakeChain is a JSON-RPC node that executes the System, Associated Token
+// and SPL Token instructions wallets send against in-memory state.
+type fakeChain struct {
+	t        *testing.T
+	mu       sync.Mutex
+	lamports map[solana.PublicKey]uint64
+	mints    map[solana.PublicKey]uint8
+	tokens   map[solana.PublicKey]*tokenAccount
+	sent     []*solana.Message
+	// statuses overrides the confirmed status reported for a signature; a
+	// nil entry reports it as unknown.
+	statuses map[solana.Signature]interface{}
+}
+
+func newFakeChain(t *testing.T) (*fakeChain, *solana.Client) {
+	chain := &fakeChain{
+		t:        t,
+		lamports: make(map[solana.PublicKey]uint64),
+		mints:    make(map[solana.PublicKey]uint8),
+		tokens:   make(map[solana.PublicKey]*tokenAccount),
+		statuses: make(map[solana.Signature]interface{}),
+	}
+	server := httptest.NewServer(chain)
+	t.Cleanup(server.Close)
+	return chain, solana.NewClientWithConfig(server.URL, solana.ClientConfig{MaxRetries: -1})
+}
+
+func (c *fakeChain) fundTokens(owner, mint solana.PublicKey, amount uint64) {
+	account, err := solana.AssociatedTokenAddress(owner, mint, solana.TokenProgramID)
+	require.NoError(c.t, err)
+	c.tokens[account] = &tokenAccount{owner: owner, mint: mint, amount: amount}
+}
+
+func (c *fakeChain) tokenBalance(owner, mint solana.PublicKey) uint64 {
+	account, err := solana.AssociatedTokenAddress(owner, mint, solana.TokenProgramID)
+	require.NoError(c.t, err)
+	if token, ok := c.tokens[account]; ok {
+		return token.amount
+	}
+	return 0
+}
+
+func (c *fakeChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int               `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	require.NoError(c.t, json.NewDecoder(r.Body).Decode(&req))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var key solana.PublicKey
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &key)
+	}
+	wrap := func(value interface{}) interface{} {
+		return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": value}
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "getBalance":
+		result = wrap(c.lamports[key])
+	case "getTokenAccountsByOwner":
+		var filter struct {
+			Mint solana.PublicKey `json:"mint"`
+		}
+		require.NoError(c.t, json.Unmarshal(req.Params[1], &filter))
+		accounts := []interface{}{}
+		for address, token := range c.tokens {
+			if token.owner != key || token.mint != filter.Mint {
+				continue
+			}
+			info := map[string]interface{}{
+				"mint":        token.mint,
+				"owner":       token.owner,
+				"tokenAmount": map[string]interface{}{"amount": jsonUint(token.amount), "decimals": c.mints[token.mint]},
+			}
+			accounts = append(accounts, map[string]interface{}{
+				"pubkey":  address,
+				"account": map[string]interface{}{"data": map[string]interface{}{"parsed": map[string]interface{}{"info": info}}},
+			})
+		}
+		result = wrap(accounts)
+	case "getAccountInfo":
+		var data []byte
+		if decimals, ok := c.mints[key]; ok {
+			data = make([]byte, 82)
+			data[44] = decimals
+			data[45] = 1
+		} else if _, ok := c.tokens[key]; ok {
+			data = make([]byte, 165)
+		} else {
+			result = wrap(nil)
+			break
+		}
+		result = wrap(map[string]interface{}{
+			"lamports": 2039280,
+			"owner":    solana.TokenProgramID,
+			"data":     []string{base64.StdEncoding.EncodeToString(data), "base64"},
+		})
+	case "getLatestBlockhash":
+		result = wrap(map[string]interface{}{"blockhash": solana.Hash{7}, "lastValidBlockHeight": 100})
+	case "sendTransaction":
+		var encoded string
+		require.NoError(c.t, json.Unmarshal(req.Params[0], &encoded))
+		result = c.execute(encoded)
+	case "getSignatureStatuses":
+		var sigs []solana.Signature
+		require.NoError(c.t, json.Unmarshal(req.Params[0], &sigs))
+		status, ok := c.statuses[sigs[0]]
+		if !ok {
+			status = map[string]interface{}{"slot": 1, "confirmations": 1, "err": nil, "confirmationStatus": "confirmed"}
+		}
+		result = wrap([]interface{}{status})
+	default:
+		c.t.Errorf("unexpected method %s", req.Method)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func jsonUint(n uint64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func (c *fakeChain) execute(encoded string) solana.Signature {
+	tx, err := solana.ParseTransactionBase64(encoded)
+	require.NoError(c.t, err)
+	msg, err := tx.DecodeMessage()
+	require.NoError(c.t, err)
+	for i, signer := range msg.Signers() {
+		require.True(c.t, ed25519.Verify(signer[:], tx.Message, tx.Signatures[i][:]), "signature %d", i)
+	}
+	c.sent = append(c.sent, msg)
+
+	for _, ix := range msg.Instructions {
+		accounts := make([]solana.PublicKey, len(ix.Accounts))
+		for i, idx := range ix.Accounts {
+			accounts[i] = msg.AccountKeys[idx]
+		}
+		switch msg.AccountKeys[ix.ProgramIDIndex] {
+		case solana.SystemProgramID:
+			require.Equal(c.t, uint32(2), binary.LittleEndian.Uint32(ix.Data))
+			lamports := binary.LittleEndian.Uint64(ix.Data[4:])
+			require.GreaterOrEqual(c.t, c.lamports[accounts[0]], lamports)
+			c.lamports[accounts[0]] -= lamports
+			c.lamports[accounts[1]] += lamports
+		case solana.AssociatedTokenProgramID:
+			if _, ok := c.tokens[accounts[1]]; !ok {
+				c.tokens[accounts[1]] = &tokenAccount{owner: accounts[2], mint: accounts[3]}
+			}
+		case solana.TokenProgramID:
+			require.Equal(c.t, byte(12), ix.Data[0])
+			amount := binary.LittleEndian.Uint64(ix.Data[1:])
+			require.Equal(c.t, c.mints[accounts[1]], ix.Data[9])
+			source, dest := c.tokens[accounts[0]], c.tokens[accounts[2]]
+			require.NotNil(c.t, source)
+			require.NotNil(c.t, dest, "destination token account missing")
+			require.Equal(c.t, accounts[3], source.owner)
+			require.GreaterOrEqual(c.t, source.amount, amount)
+			source.amount -= amount
+			dest.amount += amount
+		default:
+			c.t.Errorf("unexpected program %s", msg.AccountKeys[ix.ProgramIDIndex])
+		}
+	}
+	return tx.Signatures[0]
+}
+
+func TestSolanaWallet_Transfer(t *testing.T) {
+	chain, rpc := newFakeChain(t)
+	manager, err := NewWalletManagerWithRPC(rpc)
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateWallet(TradingWallet))
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	trading, _ := manager.GetWallet(TradingWallet)
+	profit, _ := manager.GetWallet(ProfitWallet)
+
+	usdc := solana.PublicKey{0xc6, 0xfa}
+	chain.mints[usdc] = 6
+	chain.lamports[trading.PublicKey()] = 3 * solana.LamportsPerSOL
+	chain.fundTokens(trading.PublicKey(), usdc, 250000000)
+
+	// SOL
+	sig, err := manager.TransferFunds(TradingWallet, ProfitWallet, solana.NativeMint, 1.25)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1750000000), chain.lamports[trading.PublicKey()])
+	assert.Equal(t, uint64(1250000000), chain.lamports[profit.PublicKey()])
+	assert.False(t, sig.IsZero())
+
+	balance, err := profit.GetBalance(solana.NativeMint)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, balance)
+
+	// Tokens: the first transfer creates the recipient's account
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, usdc, 100)
+	require.NoError(t, err)
+	assert.Len(t, chain.sent[1].Instructions, 2)
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, usdc, 50.5)
+	require.NoError(t, err)
+	assert.Len(t, chain.sent[2].Instructions, 1)
+
+	assert.Equal(t, uint64(99500000), chain.tokenBalance(trading.PublicKey(), usdc))
+	assert.Equal(t, uint64(150500000), chain.tokenBalance(profit.PublicKey(), usdc))
+	balance, err = profit.GetBalance(usdc)
+	assert.NoError(t, err)
+	assert.Equal(t, 150.5, balance)
+
+	// Nothing is sent when the wallet cannot cover the transfer. Only the
+	// associated token account is debited, and SOL for the fee and rent
+	// stays behind.
+	chain.tokens[solana.PublicKey{0xaa}] = &tokenAccount{owner: trading.PublicKey(), mint: usdc, amount: 1000e6}
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, usdc, 500)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, solana.NativeMint, 2)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, solana.NativeMint, 1.75)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.Len(t, chain.sent, 3)
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, solana.NativeMint, 1.749104120)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(rentExemptMinimum+transferFee), chain.lamports[trading.PublicKey()])
+
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, usdc, 0)
+	assert.Error(t, err)
+	_, err = manager.TransferFunds(TradingWallet, ProfitWallet, usdc, 0.0000001)
+	assert.Error(t, err)
+	_, err = trading.Transfer(trading, usdc, 1)
+	assert.Error(t, err)
+	_, err = manager.TransferFunds(TradingWallet, "C", usdc, 1)
+	assert.Error(t, err)
+}
+
+func TestSolanaWallet_GetBalance(t *testing.T) {
+	chain, rpc := newFakeChain(t)
+	keyStore, err := NewHSMKeyStore()
+	require.NoError(t, err)
+	require.NoError(t, keyStore.StoreMnemonic(testMnemonic, ""))
+	w, err := NewSolanaWallet("A", keyStore, rpc, 0)
+	require.NoError(t, err)
+
+	bonk := solana.PublicKey{0xbb}
+	chain.mints[bonk] = 5
+
+	balance, err := w.GetBalance(bonk)
+	assert.NoError(t, err)
+	assert.Zero(t, balance)
+
+	chain.fundTokens(w.PublicKey(), bonk, 123456789)
+	balance, err = w.GetBalance(bonk)
+	assert.NoError(t, err)
+	assert.Equal(t, 1234.56789, balance)
+
+	_, err = w.GetBalance(solana.PublicKey{0xee})
+	assert.ErrorIs(t, err, solana.ErrAccountNotFound)
+}
@@ -0,0 +1,430 @@
+package wallet
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+)
+
+// SweepMode decides how much of the trading wallet counts as profit.
+type SweepMode string
+
+const (
+	// SweepRealizedPnL sweeps realized PnL not yet swept from markets
+	// quoted in Quote, the asset Mint holds.
+	SweepRealizedPnL SweepMode = "realized_pnl"
+	// SweepThreshold sweeps whatever the balance holds above Threshold.
+	SweepThreshold SweepMode = "threshold"
+	// SweepHighWaterMark sweeps gains above the highest balance left after
+	// the previous sweep, so losses must be recovered before sweeping again.
+	SweepHighWaterMark SweepMode = "high_water_mark"
+)
+
+const defaultSweepInterval = time.Hour
+
+// pendingSweepExpiry is how long an unconfirmed transfer the node has never
+// seen is waited for. Its blockhash lasts about 150 slots, a minute or so;
+// after that it can no longer land.
+const pendingSweepExpiry = 5 * time.Minute
+
+// PnLSource reports a wallet's realized PnL from markets quoted in quote;
+// portfolio.Tracker satisfies it.
+type PnLSource interface {
+	RealizedPnL(wallet, quote string) float64
+}
+
+// SweepManager is the part of the wallet manager a Sweeper drives.
+type SweepManager interface {
+	Manager
+	GetWallet(walletType WalletType) (*SolanaWallet, error)
+}
+
+type SweepConfig struct {
+	Mode SweepMode
+	// From and To default to the trading and profit wallets.
+	From WalletType
+	To   WalletType
+	// Mint is the token swept; solana.NativeMint means SOL.
+	Mint solana.PublicKey
+	// Quote is Mint's asset as market symbols name it, e.g. "USDC", so
+	// SweepRealizedPnL only counts PnL made in it. Defaults to "SOL" for
+	// solana.NativeMint.
+	Quote string
+	// Threshold is the balance kept by SweepThreshold and the starting
+	// mark for SweepHighWaterMark (defaulting to the first balance seen).
+	Threshold float64
+	// Reserve is always left in From, e.g. SOL for fees.
+	Reserve float64
+	// Fraction of the profit to sweep; defaults to all of it.
+	Fraction float64
+	// MinAmount skips sweeps too small to be worth a transaction.
+	MinAmount float64
+	// DailyCap limits the total swept per UTC day; zero means no cap.
+	DailyCap float64
+	Interval time.Duration
+	// DryRun audits the sweeps that would be made without moving funds.
+	// Dry-run sweeps are deducted from the balance the sweeper sees and
+	// count towards caps, so the log shows the schedule a live run would
+	// follow.
+	DryRun bool
+	// OnError, when set, is told about every sweep Run fails, including
+	// failures before anything reaches the audit log.
+	OnError func(error)
+}
+
+// SweepRecord is the audit entry written for every sweep attempted.
+type SweepRecord struct {
+	Time      time.Time        `json:"time"`
+	Mode      SweepMode        `json:"mode"`
+	From      WalletType       `json:"from"`
+	To        WalletType       `json:"to"`
+	Mint      solana.PublicKey `json:"mint"`
+	Amount    float64          `json:"amount"`
+	Balance   float64          `json:"balance"`
+	Profit    float64          `json:"profit"`
+	Reason    string           `json:"reason"`
+	DryRun    bool             `json:"dryRun"`
+	Signature string           `json:"signature,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	// Pending marks a transfer that was sent but not confirmed, e.g. after
+	// a confirmation timeout. It may still land, so it counts as swept.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Succeeded reports whether the sweep moved funds, or would have in a dry
+// run.
+func (r SweepRecord) Succeeded() bool {
+	return r.Error == ""
+}
+
+type SweepAuditor interface {
+	Record(record SweepRecord) error
+}
+
+// Sweeper periodically moves profit from the trading wallet to the profit
+// wallet.
+type Sweeper struct {
+	mu      sync.Mutex
+	manager SweepManager
+	pnl     PnLSource
+	audit   SweepAuditor
+	config  SweepConfig
+	now     func() time.Time
+
+	// swept totals sweeps per UTC day for the daily cap.
+	swept     map[time.Time]float64
+	pnlSwept  float64
+	simulated float64
+	mark      float64
+	markSet   bool
+	pending   *pendingSweep
+}
+
+// pendingSweep is a transfer sent without confirmation. It is counted as
+// swept until the chain shows it failed or it expires unseen.
+type pendingSweep struct {
+	sig    solana.Signature
+	amount float64
+	day    time.Time
+	sent   time.Time
+	// mark is the high-water mark before the sweep, restored if it failed.
+	mark float64
+}
+
+// NewSweeper checks config and, when audit is a FileSweepAudit, counts the
+// sweeps it already holds for today against the daily cap. pnl is only
+// needed for SweepRealizedPnL.
+func NewSweeper(manager SweepManager, pnl PnLSource, audit SweepAuditor, config SweepConfig) (*Sweeper, error) {
+	if config.From == "" {
+		config.From = TradingWallet
+	}
+	if config.To == "" {
+		config.To = ProfitWallet
+	}
+	if config.Fraction == 0 {
+		config.Fraction = 1
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultSweepInterval
+	}
+	if config.Quote == "" && config.Mint == solana.NativeMint {
+		config.Quote = "SOL"
+	}
+
+	switch {
+	case config.Mode != SweepRealizedPnL && config.Mode != SweepThreshold && config.Mode != SweepHighWaterMark:
+		return nil, fmt.Errorf("unknown sweep mode %q", config.Mode)
+	case config.Mode == SweepRealizedPnL && pnl == nil:
+		return nil, errors.New("realized PnL sweeps need a PnL source")
+	case config.Mode == SweepRealizedPnL && config.Quote == "":
+		return nil, errors.New("realized PnL sweeps need the quote asset of the swept mint")
+	case config.From == config.To:
+		return nil, errors.New("cannot sweep a wallet into itself")
+	case config.Fraction < 0 || config.Fraction > 1:
+		return nil, fmt.Errorf("sweep fraction %f outside (0, 1]", config.Fraction)
+	case config.Threshold < 0 || config.Reserve < 0 || config.MinAmount < 0 || config.DailyCap < 0:
+		return nil, errors.New("sweep amounts must not be negative")
+	}
+
+	s := &Sweeper{
+		manager: manager,
+		pnl:     pnl,
+		audit:   audit,
+		config:  config,
+		now:     time.Now,
+		swept:   make(map[time.Time]float64),
+	}
+	if file, ok := audit.(*FileSweepAudit); ok {
+		records, err := file.Records()
+		if err != nil {
+			return nil, err
+		}
+		s.restore(records)
+	}
+	return s, nil
+}
+
+// restore counts today's sweeps, including pending ones, against the daily
+// cap so a restart does not reset it. Dry-run records only count towards
+// dry runs.
+func (s *Sweeper) restore(records []SweepRecord) {
+	for _, record := range records {
+		if (record.Succeeded() || record.Pending) && record.DryRun == s.config.DryRun && record.From == s.config.From && record.Mint == s.config.Mint {
+			s.swept[utcDay(record.Time)] += record.Amount
+		}
+	}
+}
+
+// Run sweeps every Interval until ctx is cancelled. Failed sweeps are
+// passed to OnError and retried on the next tick.
+func (s *Sweeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if _, err := s.Sweep(); err != nil && s.config.OnError != nil {
+			s.config.OnError(err)
+		}
+	}
+}
+
+// Sweep makes at most one sweep and returns its audit record, or nil when
+// there is nothing to sweep. A failed transfer is returned as both an error
+// and a record carrying it. While a previous transfer is pending nothing
+// more is swept.
+func (s *Sweeper) Sweep() (*SweepRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, err := s.manager.GetWallet(s.config.From)
+	if err != nil {
+		return nil, err
+	}
+	if s.pending != nil {
+		if settled, err := s.settle(from); err != nil || !settled {
+			return nil, err
+		}
+	}
+	balance, err := from.GetBalance(s.config.Mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s balance: %w", s.config.From, err)
+	}
+	if s.config.DryRun {
+		balance -= s.simulated
+	}
+
+	now := s.now()
+	today := utcDay(now)
+	for day := range s.swept {
+		if day.Before(today) {
+			delete(s.swept, day)
+		}
+	}
+
+	profit, reason := s.profit(balance)
+	amount := profit * s.config.Fraction
+	if available := balance - s.config.Reserve; amount > available {
+		amount = available
+		reason += fmt.Sprintf("; limited to balance above reserve %g", s.config.Reserve)
+	}
+	if s.config.DailyCap > 0 {
+		if remaining := s.config.DailyCap - s.swept[today]; amount > remaining {
+			amount = remaining
+			reason += fmt.Sprintf("; limited by daily cap %g", s.config.DailyCap)
+		}
+	}
+	if amount <= 0 || amount < s.config.MinAmount || math.IsNaN(amount) {
+		return nil, nil
+	}
+
+	record := SweepRecord{
+		Time:    now,
+		Mode:    s.config.Mode,
+		From:    s.config.From,
+		To:      s.config.To,
+		Mint:    s.config.Mint,
+		Amount:  amount,
+		Balance: balance,
+		Profit:  profit,
+		Reason:  reason,
+		DryRun:  s.config.DryRun,
+	}
+
+	var sig solana.Signature
+	var transferErr error
+	if !s.config.DryRun {
+		sig, transferErr = s.manager.TransferFunds(s.config.From, s.config.To, s.config.Mint, amount)
+		if !sig.IsZero() {
+			record.Signature = sig.String()
+		}
+		if transferErr != nil {
+			record.Error = transferErr.Error()
+			// Sent but unconfirmed transfers may still land, unless the
+			// chain already rejected them
+			var failed *solana.TransactionError
+			record.Pending = !sig.IsZero() && !errors.As(transferErr, &failed)
+		}
+	}
+	if transferErr == nil || record.Pending {
+		if record.Pending {
+			s.pending = &pendingSweep{sig: sig, amount: amount, day: today, sent: now, mark: s.mark}
+		}
+		s.swept[today] += amount
+		s.pnlSwept += amount
+		if s.config.DryRun {
+			s.simulated += amount
+		}
+		if after := balance - amount; after > s.mark {
+			s.mark = after
+		}
+	}
+
+	if s.audit != nil {
+		if err := s.audit.Record(record); err != nil {
+			return &record, fmt.Errorf("failed to audit sweep: %w", err)
+		}
+	}
+	if transferErr != nil {
+		return &record, fmt.Errorf("sweep %s -> %s: %w", s.config.From, s.config.To, transferErr)
+	}
+	return &record, nil
+}
+
+// settle resolves the pending transfer, returning false while it may still
+// land. Transfers that failed or expired unseen no longer count as swept.
+func (s *Sweeper) settle(from *SolanaWallet) (bool, error) {
+	pending := s.pending
+	status, err := from.SignatureStatus(pending.sig)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending sweep %s: %w", pending.sig, err)
+	}
+	switch {
+	case status != nil && status.Failed(),
+		status == nil && s.now().Sub(pending.sent) > pendingSweepExpiry:
+		s.swept[pending.day] -= pending.amount
+		s.pnlSwept -= pending.amount
+		s.mark = pending.mark
+	case status == nil || !status.Reached(transferCommitment):
+		return false, nil
+	}
+	s.pending = nil
+	return true, nil
+}
+
+// profit is the amount the mode considers sweepable, with an explanation
+// for the audit log.
+func (s *Sweeper) profit(balance float64) (float64, string) {
+	switch s.config.Mode {
+	case SweepRealizedPnL:
+		pnl := s.pnl.RealizedPnL(string(s.config.From), s.config.Quote)
+		return pnl - s.pnlSwept, fmt.Sprintf("realized %s PnL %g, %g already swept", s.config.Quote, pnl, s.pnlSwept)
+	case SweepThreshold:
+		return balance - s.config.Threshold, fmt.Sprintf("balance %g above threshold %g", balance, s.config.Threshold)
+	default:
+		if !s.markSet {
+			s.mark = s.config.Threshold
+			if s.mark == 0 {
+				s.mark = balance
+			}
+			s.markSet = true
+		}
+		return balance - s.mark, fmt.Sprintf("balance %g above high-water mark %g", balance, s.mark)
+	}
+}
+
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// FileSweepAudit appends sweep records to a JSON Lines file.
+type FileSweepAudit struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSweepAudit(path string) *FileSweepAudit {
+	return &FileSweepAudit{path: path}
+}
+
+func (a *FileSweepAudit) Record(record SweepRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records reads back every record in the file; a missing file has none.
+func (a *FileSweepAudit) Records() ([]SweepRecord, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.Open(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []SweepRecord
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var record SweepRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", a.path, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
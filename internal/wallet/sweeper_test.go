@@ -0,0 +1,316 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devinjacknz/devinsystem/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sweepMint = solana.PublicKey{0xc6, 0xfa}
+
+// stubPnL is realized PnL per wallet, all of it made in USDC markets.
+type stubPnL map[string]float64
+
+func (p stubPnL) RealizedPnL(wallet, quote string) float64 {
+	if quote != "USDC" {
+		return 0
+	}
+	return p[wallet]
+}
+
+// newSweepManager sets up the trading and profit wallets on a fake chain,
+// with trading holding usdc whole tokens of sweepMint.
+func newSweepManager(t *testing.T, usdc uint64) (*fakeChain, *walletManager, solana.PublicKey, solana.PublicKey) {
+	chain, rpc := newFakeChain(t)
+	manager, err := NewWalletManagerWithRPC(rpc)
+	require.NoError(t, err)
+	require.NoError(t, manager.CreateWallet(TradingWallet))
+	require.NoError(t, manager.CreateWallet(ProfitWallet))
+	trading, _ := manager.GetWallet(TradingWallet)
+	profit, _ := manager.GetWallet(ProfitWallet)
+
+	chain.mints[sweepMint] = 6
+	chain.lamports[trading.PublicKey()] = solana.LamportsPerSOL
+	chain.fundTokens(trading.PublicKey(), sweepMint, usdc*1e6)
+	return chain, manager, trading.PublicKey(), profit.PublicKey()
+}
+
+func newTestSweeper(t *testing.T, manager SweepManager, pnl PnLSource, audit SweepAuditor, config SweepConfig, now *time.Time) *Sweeper {
+	sweeper, err := NewSweeper(manager, pnl, audit, config)
+	require.NoError(t, err)
+	sweeper.now = func() time.Time { return *now }
+	return sweeper
+}
+
+func TestSweeper_Threshold(t *testing.T) {
+	chain, manager, trading, profit := newSweepManager(t, 1500)
+	audit := NewFileSweepAudit(filepath.Join(t.TempDir(), "sweeps.jsonl"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	config := SweepConfig{Mode: SweepThreshold, Mint: sweepMint, Threshold: 1000, DailyCap: 300, MinAmount: 1}
+	sweeper := newTestSweeper(t, manager, nil, audit, config, &now)
+
+	record, err := sweeper.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 300.0, record.Amount)
+	assert.Equal(t, 500.0, record.Profit)
+	assert.Contains(t, record.Reason, "daily cap")
+	assert.NotEmpty(t, record.Signature)
+	assert.Equal(t, uint64(300e6), chain.tokenBalance(profit, sweepMint))
+
+	// Capped for the rest of the day, even across a restart
+	record, err = sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	restarted := newTestSweeper(t, manager, nil, audit, config, &now)
+	record, err = restarted.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	now = now.Add(24 * time.Hour)
+	record, err = restarted.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 200.0, record.Amount)
+	assert.Equal(t, uint64(1000e6), chain.tokenBalance(trading, sweepMint))
+
+	// Below MinAmount
+	chain.fundTokens(trading, sweepMint, 1000500000)
+	record, err = restarted.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	records, err := audit.Records()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []float64{300, 200}, []float64{records[0].Amount, records[1].Amount})
+	assert.Equal(t, sweepMint, records[1].Mint)
+	assert.Equal(t, TradingWallet, records[1].From)
+	assert.Equal(t, ProfitWallet, records[1].To)
+}
+
+func TestSweeper_HighWaterMark(t *testing.T) {
+	chain, manager, trading, profit := newSweepManager(t, 1000)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sweeper := newTestSweeper(t, manager, nil, nil, SweepConfig{Mode: SweepHighWaterMark, Mint: sweepMint, Fraction: 0.5}, &now)
+
+	// The first balance seen sets the mark
+	record, err := sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	chain.fundTokens(trading, sweepMint, 1200e6)
+	record, err = sweeper.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 100.0, record.Amount)
+
+	// Losses below the new mark of 1100 must be recovered first
+	chain.fundTokens(trading, sweepMint, 1050e6)
+	record, err = sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	chain.fundTokens(trading, sweepMint, 1140e6)
+	record, err = sweeper.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 20.0, record.Amount)
+	assert.Equal(t, uint64(120e6), chain.tokenBalance(profit, sweepMint))
+}
+
+func TestSweeper_RealizedPnLDryRun(t *testing.T) {
+	chain, manager, _, profit := newSweepManager(t, 1000)
+	audit := NewFileSweepAudit(filepath.Join(t.TempDir(), "sweeps.jsonl"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pnl := stubPnL{"A": 50}
+	config := SweepConfig{Mode: SweepRealizedPnL, Mint: sweepMint, Quote: "USDC", DailyCap: 70, DryRun: true}
+	sweeper := newTestSweeper(t, manager, pnl, audit, config, &now)
+
+	record, err := sweeper.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 50.0, record.Amount)
+	assert.True(t, record.DryRun)
+	assert.Empty(t, record.Signature)
+
+	record, err = sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	pnl["A"] = 80
+	record, err = sweeper.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 20.0, record.Amount)
+	assert.Contains(t, record.Reason, "daily cap")
+
+	// Nothing moved, and dry runs do not use up a live run's cap
+	assert.Empty(t, chain.sent)
+	assert.Zero(t, chain.tokenBalance(profit, sweepMint))
+	config.DryRun = false
+	live := newTestSweeper(t, manager, pnl, audit, config, &now)
+	record, err = live.Sweep()
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 70.0, record.Amount)
+	assert.Equal(t, uint64(70e6), chain.tokenBalance(profit, sweepMint))
+}
+
+func TestSweeper_TransferFailure(t *testing.T) {
+	_, manager, _, _ := newSweepManager(t, 1000)
+	audit := NewFileSweepAudit(filepath.Join(t.TempDir(), "sweeps.jsonl"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sweeper := newTestSweeper(t, manager, nil, audit, SweepConfig{Mode: SweepThreshold, Mint: sweepMint, To: "C", Threshold: 900}, &now)
+
+	// Failures are audited and retried in full
+	for i := 0; i < 2; i++ {
+		record, err := sweeper.Sweep()
+		assert.Error(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, 100.0, record.Amount)
+		assert.False(t, record.Succeeded())
+	}
+	records, err := audit.Records()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.NotEmpty(t, records[0].Error)
+}
+
+// unconfirmedTransfers sends transfers but reports each as timing out
+// before confirmation.
+type unconfirmedTransfers struct {
+	SweepManager
+}
+
+func (m unconfirmedTransfers) TransferFunds(from, to WalletType, mint solana.PublicKey, amount float64) (solana.Signature, error) {
+	sig, err := m.SweepManager.TransferFunds(from, to, mint, amount)
+	if err != nil {
+		return sig, err
+	}
+	return sig, fmt.Errorf("transfer %s: %w", sig, solana.ErrConfirmationTimeout)
+}
+
+func TestSweeper_UnconfirmedTransfer(t *testing.T) {
+	chain, manager, _, profit := newSweepManager(t, 1000)
+	audit := NewFileSweepAudit(filepath.Join(t.TempDir(), "sweeps.jsonl"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pnl := stubPnL{"A": 50}
+	config := SweepConfig{Mode: SweepRealizedPnL, Mint: sweepMint, Quote: "USDC", DailyCap: 200}
+	sweeper := newTestSweeper(t, unconfirmedTransfers{manager}, pnl, audit, config, &now)
+
+	record, err := sweeper.Sweep()
+	assert.ErrorIs(t, err, solana.ErrConfirmationTimeout)
+	require.NotNil(t, record)
+	assert.True(t, record.Pending)
+	sig, err := solana.SignatureFromBase58(record.Signature)
+	require.NoError(t, err)
+
+	// Nothing more is swept while the node has not seen the transfer
+	chain.statuses[sig] = nil
+	record, err = sweeper.Sweep()
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Len(t, chain.sent, 1)
+
+	// Once confirmed it stays swept and only new PnL is swept
+	delete(chain.statuses, sig)
+	pnl["A"] = 80
+	record, err = sweeper.Sweep()
+	assert.ErrorIs(t, err, solana.ErrConfirmationTimeout)
+	require.NotNil(t, record)
+	assert.Equal(t, 30.0, record.Amount)
+	assert.Equal(t, uint64(80e6), chain.tokenBalance(profit, sweepMint))
+
+	// A transfer that failed on chain is swept again
+	sig, err = solana.SignatureFromBase58(record.Signature)
+	require.NoError(t, err)
+	chain.statuses[sig] = map[string]interface{}{"slot": 1, "err": map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}, "confirmationStatus": "confirmed"}
+	pnl["A"] = 90
+	record, err = sweeper.Sweep()
+	assert.Error(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 40.0, record.Amount)
+
+	// So is one that expired without the node ever seeing it
+	sig, err = solana.SignatureFromBase58(record.Signature)
+	require.NoError(t, err)
+	chain.statuses[sig] = nil
+	pnl["A"] = 100
+	now = now.Add(pendingSweepExpiry + time.Second)
+	record, err = sweeper.Sweep()
+	assert.Error(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 50.0, record.Amount)
+
+	// After a restart every pending sweep counts towards the cap, as its
+	// outcome is not in the log
+	restarted := newTestSweeper(t, manager, pnl, audit, config, &now)
+	assert.Equal(t, 170.0, restarted.swept[utcDay(now)])
+}
+
+func TestNewSweeper(t *testing.T) {
+	_, manager, _, _ := newSweepManager(t, 0)
+	tests := []struct {
+		name   string
+		pnl    PnLSource
+		config SweepConfig
+	}{
+		{"unknown mode", nil, SweepConfig{Mode: "moon"}},
+		{"pnl without source", nil, SweepConfig{Mode: SweepRealizedPnL, Quote: "USDC"}},
+		{"pnl without quote", stubPnL{}, SweepConfig{Mode: SweepRealizedPnL, Mint: sweepMint}},
+		{"same wallet", nil, SweepConfig{Mode: SweepThreshold, To: TradingWallet}},
+		{"fraction", nil, SweepConfig{Mode: SweepThreshold, Fraction: 1.5}},
+		{"negative cap", nil, SweepConfig{Mode: SweepThreshold, DailyCap: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSweeper(manager, tt.pnl, nil, tt.config)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewSweeper(manager, stubPnL{}, nil, SweepConfig{Mode: SweepRealizedPnL, Quote: "USDC"})
+	assert.NoError(t, err)
+	_, err = NewSweeper(manager, stubPnL{}, nil, SweepConfig{Mode: SweepRealizedPnL, Mint: solana.NativeMint})
+	assert.NoError(t, err)
+}
+
+func TestSweeper_RunReportsErrors(t *testing.T) {
+	_, rpc := newFakeChain(t)
+	manager, err := NewWalletManagerWithRPC(rpc)
+	require.NoError(t, err)
+
+	// Without a trading wallet every sweep fails before it is audited
+	errs := make(chan error, 1)
+	sweeper, err := NewSweeper(manager, nil, nil, SweepConfig{
+		Mode:     SweepThreshold,
+		Mint:     sweepMint,
+		Interval: time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sweeper.Run(ctx) }()
+
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sweep error reported")
+	}
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -1,5 +1,7 @@
 package wallet
 
+import "github.com/devinjacknz/devinsystem/internal/solana"
+
 type WalletType string
 
 const (
@@ -14,7 +16,7 @@ type KeyStore interface {
 
 type Manager interface {
 	CreateWallet(walletType WalletType) error
-	TransferFunds(from, to WalletType, amount float64) error
+	TransferFunds(from, to WalletType, mint solana.PublicKey, amount float64) (solana.Signature, error)
 }
 
 type WalletManager struct {
@@ -22,8 +24,10 @@ type WalletManager struct {
 	wallets  map[WalletType]Wallet
 }
 
+// Wallet is an account on chain. Balances and amounts are in whole tokens
+// of mint; solana.NativeMint means SOL.
 type Wallet interface {
-	GetBalance() float64
-	Transfer(to Wallet, amount float64) error
-	ReceiveFunds(amount float64) error
+	PublicKey() solana.PublicKey
+	GetBalance(mint solana.PublicKey) (float64, error)
+	Transfer(to Wallet, mint solana.PublicKey, amount float64) (solana.Signature, error)
 }
@@ -11,11 +11,57 @@ type Config struct {
 	
 	// Exchange configurations
 	SolanaRPCURL string `json:"solana_rpc_url"`
-	PumpFunURL   string `json:"pump_fun_url"`
 	
 	// AI model configurations
 	OllamaURL     string `json:"ollama_url"`
 	DeepSeekModel string `json:"deepseek_model"`
+
+	// Trading strategies
+	Strategies []StrategyConfig `json:"strategies"`
+
+	// Simulated exchange settings, used when Environment is "paper"
+	Paper PaperConfig `json:"paper"`
+
+	// Profit sweeping from trading wallet A to profit wallet B
+	Sweep SweepConfig `json:"sweep"`
+}
+
+type StrategyConfig struct {
+	Name    string             `json:"name"`
+	Type    string             `json:"type"`
+	Symbols []string           `json:"symbols"`
+	Amount  float64            `json:"amount"`
+	Enabled bool               `json:"enabled"`
+	Params  map[string]float64 `json:"params"`
+}
+
+// PaperConfig describes the fill models and starting virtual balances for
+// paper trading. Latency is LatencyMs plus up to JitterMs, drawn from Seed.
+type PaperConfig struct {
+	FeeBps      float64            `json:"fee_bps"`
+	SlippageBps float64            `json:"slippage_bps"`
+	LatencyMs   int                `json:"latency_ms"`
+	JitterMs    int                `json:"jitter_ms"`
+	Seed        int64              `json:"seed"`
+	Balances    map[string]float64 `json:"balances"`
+}
+
+// SweepConfig schedules profit sweeps; see wallet.SweepConfig. Mint is a
+// base58 token mint, Quote its symbol in markets (e.g. "USDC") and AuditLog
+// the JSON Lines file sweeps are recorded in.
+type SweepConfig struct {
+	Enabled         bool    `json:"enabled"`
+	Mode            string  `json:"mode"`
+	Mint            string  `json:"mint"`
+	Quote           string  `json:"quote"`
+	Threshold       float64 `json:"threshold"`
+	Reserve         float64 `json:"reserve"`
+	Fraction        float64 `json:"fraction"`
+	MinAmount       float64 `json:"min_amount"`
+	DailyCap        float64 `json:"daily_cap"`
+	IntervalSeconds int     `json:"interval_seconds"`
+	DryRun          bool    `json:"dry_run"`
+	AuditLog        string  `json:"audit_log"`
 }
 
 func LoadConfig(path string) (*Config, error) {